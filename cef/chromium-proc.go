@@ -1526,7 +1526,7 @@ func (m *TCEFChromium) EmitRender(messageId int32, eventName string, target targ
 				case error:
 					argumentJSONArray.Add(result.(error).Error())
 				default:
-					argumentJSONArray.Add(result)
+					argumentJSONArray.Add(argument.EncodeBigNumber(result))
 				}
 			}
 			message.Data = argumentJSONArray.Data()
@@ -21,6 +21,7 @@ import (
 	"github.com/energye/energy/v2/cef/ipc/target"
 	"github.com/energye/energy/v2/cef/ipc/types"
 	"github.com/energye/energy/v2/cef/process"
+	"github.com/energye/energy/v2/pkgs/json"
 	"reflect"
 	"sync"
 	"time"
@@ -65,17 +66,18 @@ func init() {
 
 // createCallback
 //	Create and return a callback function
-func createCallback(fn any) *callback.Callback {
+//	schema: argument list schema, nil: no validation
+func createCallback(fn any, schema *json.Schema) *callback.Callback {
 	switch fn.(type) {
 	case func(context context.IContext):
-		return &callback.Callback{Context: &callback.ContextCallback{Callback: fn.(func(context context.IContext))}}
+		return &callback.Callback{Context: &callback.ContextCallback{Callback: fn.(func(context context.IContext)), Schema: schema}}
 	default:
 		v := reflect.ValueOf(fn)
 		// fn must be a function
 		if v.Kind() != reflect.Func {
 			return nil
 		}
-		return &callback.Callback{Argument: &callback.ArgumentCallback{Callback: &v}}
+		return &callback.Callback{Argument: &callback.ArgumentCallback{Callback: &v, Schema: schema}}
 	}
 }
 
@@ -95,7 +97,13 @@ func On(name string, fn any, options ...types.OnOptions) {
 	if name == "" || fn == nil {
 		return
 	}
-	var isOn = false
+	var (
+		isOn   = false
+		schema *json.Schema
+	)
+	if options != nil && len(options) > 0 {
+		schema = options[0].Schema
+	}
 	if options != nil && len(options) > 0 && !cef.Application().SingleProcess() {
 		option := options[0]
 		if option.OnType == types.OtAll {
@@ -109,7 +117,7 @@ func On(name string, fn any, options ...types.OnOptions) {
 		isOn = true
 	}
 	if isOn {
-		if callbackFN := createCallback(fn); callbackFN != nil {
+		if callbackFN := createCallback(fn, schema); callbackFN != nil {
 			browser.addOnEvent(name, callbackFN)
 		}
 	}
@@ -262,7 +270,7 @@ func (m *browserIPC) addEmitCallback(fn any) int32 {
 	}
 	m.emitLock.Lock()
 	defer m.emitLock.Unlock()
-	if callbackFN := createCallback(fn); callbackFN != nil {
+	if callbackFN := createCallback(fn, nil); callbackFN != nil {
 		if m.emitCallbackMessageId == -1 {
 			m.emitCallbackMessageId = 1
 		} else {
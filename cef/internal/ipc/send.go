@@ -22,7 +22,7 @@ func emitSendToGoChannel(messageId int32, tag target.ITarget, eventName string,
 		Id:        messageId,
 		Name:      InternalIPCGoExecuteGoEvent,
 		EventName: eventName,
-		Data:      arguments,
+		Data:      argument.EncodeBigNumbers(arguments),
 	}
 	if isMainProcess {
 		BrowserChan().IPC().Send(tag.ChannelId(), message.Bytes())
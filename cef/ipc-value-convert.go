@@ -22,6 +22,7 @@ import (
 	"github.com/energye/energy/v2/pkgs/json"
 	"github.com/energye/golcl/lcl/api"
 	jsoniter "github.com/json-iterator/go"
+	"math"
 	"reflect"
 	"unsafe"
 )
@@ -145,6 +146,24 @@ func (m *v8ValueProcessMessageConvert) DictionaryValueToV8Value(dictionary *ICef
 	return result, nil
 }
 
+// intToV8Value int 转 ICefV8Value
+//  超出 int32 范围使用 double, 超出 2^53 的整数在 Go 端已转换为 argument.BigNumberKey 字符串包装对象
+func (m *v8ValueProcessMessageConvert) intToV8Value(v int64) *ICefV8Value {
+	if v >= math.MinInt32 && v <= math.MaxInt32 {
+		return V8ValueRef.NewInt(int32(v))
+	}
+	return V8ValueRef.NewDouble(float64(v))
+}
+
+// uintToV8Value uint 转 ICefV8Value
+//  超出 uint32 范围使用 double
+func (m *v8ValueProcessMessageConvert) uintToV8Value(v uint64) *ICefV8Value {
+	if v <= math.MaxUint32 {
+		return V8ValueRef.NewUInt(uint32(v))
+	}
+	return V8ValueRef.NewDouble(float64(v))
+}
+
 // BytesToV8ArrayValue JSONArray 字节数组转换 TCefV8ValueArray
 func (m *v8ValueProcessMessageConvert) BytesToV8ArrayValue(resultArgsBytes []byte) (*TCefV8ValueArray, error) {
 	//只能是 JSONArray 对象类型
@@ -178,9 +197,9 @@ func (m *v8ValueProcessMessageConvert) JSONArrayToV8ArrayValue(jsonArray json.JS
 		case reflect.String:
 			resultArgs.Add(V8ValueRef.NewString(value.String()))
 		case reflect.Int:
-			resultArgs.Add(V8ValueRef.NewInt(int32(value.Int())))
+			resultArgs.Add(m.intToV8Value(value.Int64()))
 		case reflect.Uint:
-			resultArgs.Add(V8ValueRef.NewUInt(uint32(value.UInt())))
+			resultArgs.Add(m.uintToV8Value(value.UInt64()))
 		case reflect.Float64:
 			resultArgs.Add(V8ValueRef.NewDouble(value.Float()))
 		case reflect.Bool:
@@ -222,9 +241,9 @@ func (m *v8ValueProcessMessageConvert) JSONArrayToV8Value(array json.JSONArray)
 		case reflect.String:
 			result.SetValueByIndex(int32(i), V8ValueRef.NewString(value.String()))
 		case reflect.Int:
-			result.SetValueByIndex(int32(i), V8ValueRef.NewInt(int32(value.Int())))
+			result.SetValueByIndex(int32(i), m.intToV8Value(value.Int64()))
 		case reflect.Uint:
-			result.SetValueByIndex(int32(i), V8ValueRef.NewUInt(uint32(value.UInt())))
+			result.SetValueByIndex(int32(i), m.uintToV8Value(value.UInt64()))
 		case reflect.Float64:
 			result.SetValueByIndex(int32(i), V8ValueRef.NewDouble(value.Float()))
 		case reflect.Bool:
@@ -259,9 +278,9 @@ func (m *v8ValueProcessMessageConvert) BindDataToV8Value(data json.JSONObject) *
 	case reflect.String:
 		return V8ValueRef.NewString(data.GetStringByKey("V"))
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return V8ValueRef.NewInt(int32(data.GetIntByKey("V")))
+		return m.intToV8Value(data.GetInt64ByKey("V"))
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return V8ValueRef.NewUInt(uint32(data.GetUIntByKey("V")))
+		return m.uintToV8Value(data.GetUInt64ByKey("V"))
 	case reflect.Float32, reflect.Float64:
 		return V8ValueRef.NewDouble(data.GetFloatByKey("V"))
 	case reflect.Bool:
@@ -296,9 +315,9 @@ func (m *v8ValueProcessMessageConvert) JSONObjectToV8Value(object json.JSONObjec
 		case reflect.String:
 			result.setValueByKey(key, V8ValueRef.NewString(value.String()), consts.V8_PROPERTY_ATTRIBUTE_NONE)
 		case reflect.Int:
-			result.setValueByKey(key, V8ValueRef.NewInt(int32(value.Int())), consts.V8_PROPERTY_ATTRIBUTE_NONE)
+			result.setValueByKey(key, m.intToV8Value(value.Int64()), consts.V8_PROPERTY_ATTRIBUTE_NONE)
 		case reflect.Uint:
-			result.setValueByKey(key, V8ValueRef.NewUInt(uint32(value.UInt())), consts.V8_PROPERTY_ATTRIBUTE_NONE)
+			result.setValueByKey(key, m.uintToV8Value(value.UInt64()), consts.V8_PROPERTY_ATTRIBUTE_NONE)
 		case reflect.Float64:
 			result.setValueByKey(key, V8ValueRef.NewDouble(value.Float()), consts.V8_PROPERTY_ATTRIBUTE_NONE)
 		case reflect.Bool:
@@ -0,0 +1,183 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package argument
+
+import (
+	"github.com/energye/energy/v2/pkgs/decimal"
+	"github.com/energye/energy/v2/pkgs/json"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// Lossless numbers
+//	JS numbers are IEEE 754 doubles, integers above 2^53 and decimals lose precision.
+//	Such values are sent as a decimal string wrapper object
+//	  { __energyBigNumber: "int64" | "uint64" | "bigint" | "decimal", value: "123456789012345678901" }
+//	JS: BigInt(arg.value), and the same object (or a plain decimal string) sent back to Go
+//	is converted to the type of the callback parameter.
+const (
+	BigNumberKey   = "__energyBigNumber" // wrapper object marker, value is the number kind
+	BigNumberValue = "value"             // wrapper object decimal string value
+)
+
+// number kinds
+const (
+	BigNumberInt64   = "int64"
+	BigNumberUint64  = "uint64"
+	BigNumberBigInt  = "bigint"
+	BigNumberDecimal = "decimal"
+)
+
+// maxSafeInteger JS Number.MAX_SAFE_INTEGER 2^53-1
+const maxSafeInteger = 1<<53 - 1
+
+var (
+	bigIntType     = reflect.TypeOf(big.Int{})
+	decimalType    = reflect.TypeOf(decimal.Decimal{})
+	bigIntPtrType  = reflect.PtrTo(bigIntType)
+	decimalPtrType = reflect.PtrTo(decimalType)
+)
+
+func newBigNumber(kind, value string) map[string]any {
+	return map[string]any{BigNumberKey: kind, BigNumberValue: value}
+}
+
+// EncodeBigNumber
+//	Returns the wrapper object of value when it can not be represented by a JS number,
+//	otherwise value itself
+//	  int, int64, uint, uint64: only outside ±(2^53-1)
+//	  *big.Int, big.Int, decimal.Decimal, *decimal.Decimal: always
+func EncodeBigNumber(value any) any {
+	switch v := value.(type) {
+	case int:
+		if v > maxSafeInteger || v < -maxSafeInteger {
+			return newBigNumber(BigNumberInt64, strconv.FormatInt(int64(v), 10))
+		}
+	case int64:
+		if v > maxSafeInteger || v < -maxSafeInteger {
+			return newBigNumber(BigNumberInt64, strconv.FormatInt(v, 10))
+		}
+	case uint:
+		if v > maxSafeInteger {
+			return newBigNumber(BigNumberUint64, strconv.FormatUint(uint64(v), 10))
+		}
+	case uint64:
+		if v > maxSafeInteger {
+			return newBigNumber(BigNumberUint64, strconv.FormatUint(v, 10))
+		}
+	case *big.Int:
+		if v != nil {
+			return newBigNumber(BigNumberBigInt, v.String())
+		}
+	case big.Int:
+		return newBigNumber(BigNumberBigInt, v.String())
+	case decimal.Decimal:
+		return newBigNumber(BigNumberDecimal, v.String())
+	case *decimal.Decimal:
+		if v != nil {
+			return newBigNumber(BigNumberDecimal, v.String())
+		}
+	}
+	return value
+}
+
+// EncodeBigNumbers
+//	EncodeBigNumber for each value of an argument list, returns a new list
+func EncodeBigNumbers(values []any) []any {
+	if len(values) == 0 {
+		return values
+	}
+	result := make([]any, len(values))
+	for i, value := range values {
+		result[i] = EncodeBigNumber(value)
+	}
+	return result
+}
+
+// IsBigNumberType
+//	Whether t is a type DecodeBigNumber converts to
+func IsBigNumberType(t reflect.Type) bool {
+	switch t {
+	case bigIntType, bigIntPtrType, decimalType, decimalPtrType:
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// DecodeBigNumber
+//	Converts value to type t losslessly
+//	value: wrapper object, decimal string or number
+//	t: int, int64, uint, uint64, big.Int, *big.Int, decimal.Decimal, *decimal.Decimal
+//	returns false when value is not a number or does not fit in t
+func DecodeBigNumber(value json.JSON, t reflect.Type) (reflect.Value, bool) {
+	if value == nil || !IsBigNumberType(t) {
+		return reflect.Value{}, false
+	}
+	var s string
+	switch {
+	case value.IsObject():
+		if value.GetStringByKey(BigNumberKey) == "" {
+			return reflect.Value{}, false
+		}
+		s = value.GetStringByKey(BigNumberValue)
+	case value.IsString():
+		s = value.String()
+	case value.IsInt():
+		s = strconv.FormatInt(value.Int64(), 10)
+	case value.IsUInt():
+		s = strconv.FormatUint(value.UInt64(), 10)
+	case value.IsFloat():
+		s = strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	default:
+		return reflect.Value{}, false
+	}
+	switch t {
+	case decimalType, decimalPtrType:
+		d, err := decimal.NewFromString(s)
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		if t == decimalPtrType {
+			return reflect.ValueOf(&d), true
+		}
+		return reflect.ValueOf(d), true
+	case bigIntType, bigIntPtrType:
+		i, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		if t == bigIntPtrType {
+			return reflect.ValueOf(i), true
+		}
+		return reflect.ValueOf(i).Elem(), true
+	}
+	result := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		result.SetInt(i)
+	case reflect.Uint, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		result.SetUint(u)
+	}
+	return result, true
+}
@@ -0,0 +1,59 @@
+package argument
+
+import (
+	"github.com/energye/energy/v2/pkgs/decimal"
+	"github.com/energye/energy/v2/pkgs/json"
+	jsoniter "github.com/json-iterator/go"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestBigNumber(t *testing.T) {
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	dec := decimal.RequireFromString("12345678901234567890.123456789")
+	values := []any{int64(1<<53 + 1), uint64(math.MaxUint64), bigInt, dec, int64(42), "text"}
+	encoded := EncodeBigNumbers(values)
+	if encoded[4] != int64(42) || encoded[5] != "text" {
+		t.Fatal("safe values must not be wrapped", encoded)
+	}
+	// JS receives the message bytes and sends the same values back
+	data, err := jsoniter.Marshal(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := json.NewJSONArray(data)
+	types := []reflect.Type{reflect.TypeOf(int64(0)), reflect.TypeOf(uint64(0)), reflect.TypeOf(bigInt), reflect.TypeOf(dec), reflect.TypeOf(int64(0))}
+	for i, typ := range types {
+		v, ok := DecodeBigNumber(list.GetByIndex(i), typ)
+		if !ok {
+			t.Fatalf("index %d: decode failed", i)
+		}
+		switch want := values[i].(type) {
+		case *big.Int:
+			if v.Interface().(*big.Int).Cmp(want) != 0 {
+				t.Errorf("index %d: expected %s, got %s", i, want, v.Interface())
+			}
+		case decimal.Decimal:
+			if !v.Interface().(decimal.Decimal).Equal(want) {
+				t.Errorf("index %d: expected %s, got %s", i, want, v.Interface())
+			}
+		default:
+			if v.Interface() != want {
+				t.Errorf("index %d: expected %v, got %v", i, want, v.Interface())
+			}
+		}
+	}
+	// plain decimal string from JS
+	v, ok := DecodeBigNumber(json.NewJSONArray(`["18446744073709551615"]`).GetByIndex(0), reflect.TypeOf(uint(0)))
+	if !ok || v.Interface() != uint(math.MaxUint64) {
+		t.Errorf("expected max uint, got %v", v)
+	}
+	if _, ok = DecodeBigNumber(json.NewJSONArray(`["abc"]`).GetByIndex(0), reflect.TypeOf(int64(0))); ok {
+		t.Error("expected decode failure")
+	}
+	if _, ok = DecodeBigNumber(json.NewJSONArray(`["9223372036854775808"]`).GetByIndex(0), reflect.TypeOf(int64(0))); ok {
+		t.Error("expected overflow failure")
+	}
+}
@@ -11,6 +11,7 @@
 package callback
 
 import (
+	"github.com/energye/energy/v2/cef/ipc/argument"
 	"github.com/energye/energy/v2/cef/ipc/context"
 	"github.com/energye/energy/v2/pkgs/json"
 	jsoniter "github.com/json-iterator/go"
@@ -42,12 +43,28 @@ type Callback struct {
 //	Callback function with context
 type ContextCallback struct {
 	Callback EmitContextCallback
+	Schema   *json.Schema // argument list schema, nil: no validation
 }
 
 // ArgumentCallback
 //	Callback function with parameters
 type ArgumentCallback struct {
 	Callback *reflect.Value
+	Schema   *json.Schema // argument list schema, nil: no validation
+}
+
+// ArgumentError
+//	The result returned to the caller when the argument list does not match the schema
+//	JS receives: { error: { name, message, errors: [{ path, keyword, message }] } }
+type ArgumentError struct {
+	Error ArgumentErrorInfo `json:"error"`
+}
+
+// ArgumentErrorInfo argument validation error details
+type ArgumentErrorInfo struct {
+	Name    string             `json:"name"`
+	Message string             `json:"message"`
+	Errors  []json.SchemaError `json:"errors"`
 }
 
 type argumentChannel struct {
@@ -72,8 +89,33 @@ func (m *Callback) ContextCallback() *ContextCallback {
 	return m.Context
 }
 
+// validateArgument
+//	Validate the argument list against the schema
+//	on failure the ArgumentError is set as the result and false is returned
+func validateArgument(schema *json.Schema, context context.IContext) bool {
+	if schema == nil {
+		return true
+	}
+	argsList := context.ArgumentList()
+	if argsList == nil {
+		argsList = json.NewJSONArray([]any{})
+	}
+	if err := schema.Validate(argsList.JsonData()); err != nil {
+		result := &ArgumentError{Error: ArgumentErrorInfo{Name: "ArgumentError", Message: err.Error()}}
+		if ve, ok := err.(*json.ValidationError); ok {
+			result.Error.Errors = ve.Errors
+		}
+		context.Result(result)
+		return false
+	}
+	return true
+}
+
 // Invoke context function
 func (m *ContextCallback) Invoke(context context.IContext) {
+	if !validateArgument(m.Schema, context) {
+		return
+	}
 	// call
 	m.Callback(context)
 	resultValues := context.Replay().Result()
@@ -85,7 +127,7 @@ func (m *ContextCallback) Invoke(context context.IContext) {
 			case error:
 				resultArgument[i] = result.(error).Error()
 			default:
-				resultArgument[i] = result
+				resultArgument[i] = argument.EncodeBigNumber(result)
 			}
 		}
 		// result
@@ -106,6 +148,9 @@ func (m *Callback) ArgumentCallback() *ArgumentCallback {
 
 // Invoke argument list function
 func (m *ArgumentCallback) Invoke(context context.IContext) {
+	if !validateArgument(m.Schema, context) {
+		return
+	}
 	var (
 		argsList     json.JSONArray
 		argsSize     int
@@ -124,73 +169,78 @@ func (m *ArgumentCallback) Invoke(context context.IContext) {
 		if inIdx < argsSize {
 			argsValue := argsList.GetByIndex(inIdx)
 			if argsValue != nil {
-				switch inType.Kind() {
-				case reflect.String:
-					inArgsValues[i] = reflect.ValueOf(argsValue.String())
-				case reflect.Int:
-					inArgsValues[i] = reflect.ValueOf(argsValue.Int())
-				case reflect.Int8:
-					inArgsValues[i] = reflect.ValueOf(int8(argsValue.Int()))
-				case reflect.Int16:
-					inArgsValues[i] = reflect.ValueOf(int16(argsValue.Int()))
-				case reflect.Int32:
-					inArgsValues[i] = reflect.ValueOf(int32(argsValue.Int()))
-				case reflect.Int64:
-					inArgsValues[i] = reflect.ValueOf(int64(argsValue.Int()))
-				case reflect.Uint:
-					inArgsValues[i] = reflect.ValueOf(uint(argsValue.Int()))
-				case reflect.Uint8:
-					inArgsValues[i] = reflect.ValueOf(uint8(argsValue.Int()))
-				case reflect.Uint16:
-					inArgsValues[i] = reflect.ValueOf(uint16(argsValue.Int()))
-				case reflect.Uint32:
-					inArgsValues[i] = reflect.ValueOf(uint32(argsValue.Int()))
-				case reflect.Uint64:
-					inArgsValues[i] = reflect.ValueOf(uint64(argsValue.Int()))
-				case reflect.Uintptr:
-					inArgsValues[i] = reflect.ValueOf(uintptr(argsValue.Int()))
-				case reflect.Float32:
-					inArgsValues[i] = reflect.ValueOf(float32(argsValue.Float()))
-				case reflect.Float64:
-					inArgsValues[i] = reflect.ValueOf(argsValue.Float())
-				case reflect.Bool:
-					inArgsValues[i] = reflect.ValueOf(argsValue.Bool())
-				case reflect.Struct:
-					if argsValue.IsObject() {
-						// struct
-						if jsonBytes := argsValue.Bytes(); jsonBytes != nil {
-							v := reflect.New(inType)
-							if err := jsoniter.Unmarshal(jsonBytes, v.Interface()); err == nil {
-								inArgsValues[i] = v.Elem()
+				if bigNumber, ok := argument.DecodeBigNumber(argsValue, inType); ok {
+					// int64, uint64, big.Int, decimal.Decimal: wrapper object or decimal string
+					inArgsValues[i] = bigNumber
+				} else {
+					switch inType.Kind() {
+					case reflect.String:
+						inArgsValues[i] = reflect.ValueOf(argsValue.String())
+					case reflect.Int:
+						inArgsValues[i] = reflect.ValueOf(argsValue.Int())
+					case reflect.Int8:
+						inArgsValues[i] = reflect.ValueOf(int8(argsValue.Int()))
+					case reflect.Int16:
+						inArgsValues[i] = reflect.ValueOf(int16(argsValue.Int()))
+					case reflect.Int32:
+						inArgsValues[i] = reflect.ValueOf(int32(argsValue.Int()))
+					case reflect.Int64:
+						inArgsValues[i] = reflect.ValueOf(int64(argsValue.Int()))
+					case reflect.Uint:
+						inArgsValues[i] = reflect.ValueOf(uint(argsValue.Int()))
+					case reflect.Uint8:
+						inArgsValues[i] = reflect.ValueOf(uint8(argsValue.Int()))
+					case reflect.Uint16:
+						inArgsValues[i] = reflect.ValueOf(uint16(argsValue.Int()))
+					case reflect.Uint32:
+						inArgsValues[i] = reflect.ValueOf(uint32(argsValue.Int()))
+					case reflect.Uint64:
+						inArgsValues[i] = reflect.ValueOf(uint64(argsValue.Int()))
+					case reflect.Uintptr:
+						inArgsValues[i] = reflect.ValueOf(uintptr(argsValue.Int()))
+					case reflect.Float32:
+						inArgsValues[i] = reflect.ValueOf(float32(argsValue.Float()))
+					case reflect.Float64:
+						inArgsValues[i] = reflect.ValueOf(argsValue.Float())
+					case reflect.Bool:
+						inArgsValues[i] = reflect.ValueOf(argsValue.Bool())
+					case reflect.Struct:
+						if argsValue.IsObject() {
+							// struct
+							if jsonBytes := argsValue.Bytes(); jsonBytes != nil {
+								v := reflect.New(inType)
+								if err := jsoniter.Unmarshal(jsonBytes, v.Interface()); err == nil {
+									inArgsValues[i] = v.Elem()
+								}
 							}
 						}
-					}
-				case reflect.Map:
-					if argsValue.IsObject() {
-						// map key=string : value != interface
-						if inType.Elem().Kind() != reflect.Interface {
-							if jsonBytes := argsValue.Bytes(); jsonBytes != nil {
-								vv := reflect.New(inType)
-								if err := jsoniter.Unmarshal(jsonBytes, vv.Interface()); err == nil {
-									inArgsValues[i] = vv.Elem()
+					case reflect.Map:
+						if argsValue.IsObject() {
+							// map key=string : value != interface
+							if inType.Elem().Kind() != reflect.Interface {
+								if jsonBytes := argsValue.Bytes(); jsonBytes != nil {
+									vv := reflect.New(inType)
+									if err := jsoniter.Unmarshal(jsonBytes, vv.Interface()); err == nil {
+										inArgsValues[i] = vv.Elem()
+									}
 								}
+							} else {
+								inArgsValues[i] = reflect.ValueOf(argsValue.Data())
 							}
-						} else {
-							inArgsValues[i] = reflect.ValueOf(argsValue.Data())
 						}
-					}
-				case reflect.Slice:
-					if argsValue.IsArray() {
-						// slice value != interface
-						if inType.Elem().Kind() != reflect.Interface {
-							if jsonBytes := argsValue.Bytes(); jsonBytes != nil {
-								vv := reflect.New(inType)
-								if err := jsoniter.Unmarshal(jsonBytes, vv.Interface()); err == nil {
-									inArgsValues[i] = vv.Elem()
+					case reflect.Slice:
+						if argsValue.IsArray() {
+							// slice value != interface
+							if inType.Elem().Kind() != reflect.Interface {
+								if jsonBytes := argsValue.Bytes(); jsonBytes != nil {
+									vv := reflect.New(inType)
+									if err := jsoniter.Unmarshal(jsonBytes, vv.Interface()); err == nil {
+										inArgsValues[i] = vv.Elem()
+									}
 								}
+							} else {
+								inArgsValues[i] = reflect.ValueOf(argsValue.Data())
 							}
-						} else {
-							inArgsValues[i] = reflect.ValueOf(argsValue.Data())
 						}
 					}
 				}
@@ -217,7 +267,7 @@ func (m *ArgumentCallback) Invoke(context context.IContext) {
 			case error:
 				resultArgument[i] = res.(error).Error()
 			default:
-				resultArgument[i] = res
+				resultArgument[i] = argument.EncodeBigNumber(res)
 			}
 		}
 		// result
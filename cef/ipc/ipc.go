@@ -28,10 +28,17 @@ import (
 //	 name: 事件名称
 //   fn : 事件回调函数 EmitContextCallback 或 func(...) [result...] {}
 //   options: 监听选项, 配置监听规则
+//     OnType: 监听进程类型
+//     Schema: 参数列表 JSON Schema, 回调执行前校验, 校验失败时不执行回调并返回错误信息
+//       { error: { name: "ArgumentError", message, errors: [{ path, keyword, message }] } }
+//       例: json.MustSchema(`{"type":"array","prefixItems":[{"type":"string","minLength":1}],"minItems":1}`)
 //
 // 入参
 //	 基本类型: int(int8 ~ uint64), bool, float(float32、float64), string
 //
+//   大数类型: int64, uint64, big.Int, *big.Int, decimal.Decimal, *decimal.Decimal
+//     无损接收 JS 传入的包装对象 { __energyBigNumber: "int64", value: "9007199254740993" } 或十进制字符串
+//
 //   复合类型: slice, map, struct
 //
 //   slice: 根据js实际类型定义, []any | []interface{} | [][data type]
@@ -71,6 +78,9 @@ func RemoveOn(name string) {
 //  []argument: 入参
 // 				基本类型: int(int8 ~ uint64), bool, float(float32、float64), string
 // 				复合类型: slice, map, struct
+// 				大数类型: 超出 JS 安全整数(2^53)的 int64/uint64, *big.Int, decimal.Decimal
+// 				  JS 接收字符串包装对象 { __energyBigNumber: "int64" | "uint64" | "bigint" | "decimal", value: "..." }
+// 				  使用 BigInt(arg.value) 转换, 原样传回 Go 时还原为监听函数参数类型
 func Emit(name string, argument ...any) {
 	ipc.Emit(name, argument...)
 }
@@ -13,6 +13,7 @@ package types
 import (
 	"github.com/energye/energy/v2/cef/ipc/target"
 	"github.com/energye/energy/v2/consts"
+	"github.com/energye/energy/v2/pkgs/json"
 )
 
 type IArrayValue interface {
@@ -86,5 +87,6 @@ const (
 
 // OnOptions Listening options
 type OnOptions struct {
-	OnType OnType // Listening type, default main process
+	OnType OnType       // Listening type, default main process
+	Schema *json.Schema // Argument list schema, validated before the callback runs, invalid calls are rejected. nil: no validation
 }
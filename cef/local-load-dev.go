@@ -0,0 +1,19 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+//go:build energy_dev
+// +build energy_dev
+
+// 开发构建, energy dev 使用: go build -tags="energy_dev"
+
+package cef
+
+// 开发构建时使用环境变量 ENERGY_DEV_SERVER 的前端开发服务地址
+const devBuild = true
@@ -0,0 +1,17 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+//go:build !energy_dev
+// +build !energy_dev
+
+package cef
+
+// 发布构建不读取环境变量 ENERGY_DEV_SERVER, 只使用 LocalLoadConfig.DevServer
+const devBuild = false
@@ -21,6 +21,8 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 	"unsafe"
 )
 
@@ -54,6 +56,7 @@ type LocalLoadConfig struct {
 	FS         *embed.FS // 内置加载资源对象, 不为nil时使用内置加载，默认: nil
 	Proxy      IXHRProxy // 数据请求代理, 在浏览器发送xhr请求时可通过该配置转发, 你可自定义实现该 IXHRProxy 接口
 	Home       string    // 默认首页HTML文件名: /index.html , 默认: /index.html
+	DevServer  string    // 前端开发服务地址, 例如: http://localhost:5173, 首页加载开发服务, XHRProxy 代理到开发服务, 为空时开发构建 (-tags="energy_dev") 使用环境变量 ENERGY_DEV_SERVER
 	exePath    string    // 执行文件当前目录
 }
 
@@ -106,6 +109,13 @@ func (m LocalLoadConfig) Build() *LocalLoadConfig {
 		config.Home = "/" + config.Home
 	}
 	m.exePath = ExeDir
+	if config.DevServer == "" && devBuild {
+		config.DevServer = os.Getenv(ENERGY_DEV_SERVER_KEY)
+	}
+	config.DevServer = strings.TrimRight(config.DevServer, "/")
+	if config.DevServer != "" {
+		config.Proxy = devServerProxy(config.DevServer, config.Proxy)
+	}
 	// 默认的资源目录
 	if config.ResRootDir == "" {
 		if config.FS != nil {
@@ -122,6 +132,30 @@ func (m LocalLoadConfig) Build() *LocalLoadConfig {
 	return config
 }
 
+// XHR 代理到前端开发服务, 未配置时创建 XHRProxy, 自定义的 IXHRProxy 实现不变
+func devServerProxy(devServer string, proxy IXHRProxy) IXHRProxy {
+	devURL, err := url.Parse(devServer)
+	if err != nil || devURL.Hostname() == "" {
+		logger.Error("LocalLoadConfig, invalid dev server:", devServer)
+		return proxy
+	}
+	var xhrProxy *XHRProxy
+	if proxy == nil {
+		xhrProxy = &XHRProxy{}
+	} else if p, ok := proxy.(*XHRProxy); ok {
+		xhrProxy = p
+	} else {
+		return proxy
+	}
+	xhrProxy.Scheme = LpsHttp
+	if devURL.Scheme == "https" {
+		xhrProxy.Scheme = LpsHttps
+	}
+	xhrProxy.IP = devURL.Hostname()
+	xhrProxy.Port, _ = strconv.Atoi(devURL.Port())
+	return xhrProxy
+}
+
 // Disable
 //  如果不想启用该代理配置，需要主动调用该函数，仅在应用出始化时有效
 func (m *LocalLoadConfig) Disable() *LocalLoadConfig {
@@ -134,6 +168,12 @@ func (m *LocalLoadResource) loadDefaultURL(window IBrowserWindow, browser *ICefB
 		var homeURL string
 		if BrowserWindow.Config.Url != defaultAboutBlank {
 			homeURL = window.WindowProperty().Url
+		} else if m.DevServer != "" {
+			// 开发模式, 加载前端开发服务
+			homeURL = m.DevServer
+			if m.Home != localHome {
+				homeURL += m.Home
+			}
 		} else {
 			defaultURL := new(bytes.Buffer)
 			defaultURL.WriteString(m.Scheme)
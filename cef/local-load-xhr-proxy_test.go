@@ -2,6 +2,7 @@ package cef
 
 import (
 	"bytes"
+	. "github.com/energye/energy/v2/consts"
 	"log"
 	"net/http"
 	"testing"
@@ -32,3 +33,15 @@ func TestXHRProxyClientSSLSend(t *testing.T) {
 	log.Println(c, err, httpResponse.StatusCode)
 	log.Println(buf.String())
 }
+
+func TestDevServerProxy(t *testing.T) {
+	t.Setenv(ENERGY_DEV_SERVER_KEY, "http://evil.example.com")
+	if config := (LocalLoadConfig{}).Build(); config.DevServer != "" || config.Proxy != nil {
+		t.Fatal("release build should ignore", ENERGY_DEV_SERVER_KEY, config.DevServer)
+	}
+	config := LocalLoadConfig{DevServer: "https://localhost:5173/", Proxy: &XHRProxy{IP: "api.example.com", Port: 8080}}.Build()
+	proxy, ok := config.Proxy.(*XHRProxy)
+	if !ok || config.DevServer != "https://localhost:5173" || proxy.Scheme != LpsHttps || proxy.IP != "localhost" || proxy.Port != 5173 {
+		t.Fatal("unexpected dev server proxy", config.DevServer, proxy)
+	}
+}
@@ -0,0 +1,437 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// Application command line flags
+//
+// Typed flags registered by the application, parsed from the same command line
+// as the CEF/Chromium switches. Unregistered switches are left to CEF.
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Flags
+//	Application flag registry of the current process command line
+//	Register flags before calling Flags.Parse()
+//
+//	port := process.Flags.Int("port", 8080, "local server port")
+//	debug := process.Flags.Bool("debug", false, "enable debug log")
+//	process.Flags.Forward("debug") // renderer and other sub processes receive --debug
+//	if err := process.Flags.Parse(); err != nil {
+//		println(err.Error())
+//	}
+var Flags = NewFlagSet(os.Args[1:])
+
+// FlagKind flag value type
+type FlagKind int8
+
+const (
+	FkBool FlagKind = iota
+	FkInt
+	FkString
+	FkDuration
+	FkList
+)
+
+func (m FlagKind) String() string {
+	switch m {
+	case FkBool:
+		return "bool"
+	case FkInt:
+		return "int"
+	case FkString:
+		return "string"
+	case FkDuration:
+		return "duration"
+	case FkList:
+		return "list"
+	}
+	return "unknown"
+}
+
+// Flag a registered flag
+type Flag struct {
+	Name     string   // flag name without --
+	Usage    string   // help text
+	Kind     FlagKind // value type
+	Default  string   // default value as text
+	forward  bool     // forward to sub processes
+	set      bool     // present in the command line
+	value    any      // *bool, *int, *string, *time.Duration, *[]string
+	listInit bool     // list value replaced by the first command line value
+}
+
+// IsSet returns true if the flag was present in the command line
+func (m *Flag) IsSet() bool {
+	return m.set
+}
+
+// Value returns the current value as command line text
+func (m *Flag) Value() string {
+	switch v := m.value.(type) {
+	case *bool:
+		return strconv.FormatBool(*v)
+	case *int:
+		return strconv.Itoa(*v)
+	case *string:
+		return *v
+	case *time.Duration:
+		return v.String()
+	case *[]string:
+		return strings.Join(*v, ",")
+	}
+	return ""
+}
+
+func (m *Flag) setValue(text string) error {
+	switch v := m.value.(type) {
+	case *bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		*v = b
+	case *int:
+		i, err := strconv.Atoi(text)
+		if err != nil {
+			return err
+		}
+		*v = i
+	case *string:
+		*v = text
+	case *time.Duration:
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		*v = d
+	case *[]string:
+		// repeated flags and comma separated values are appended, the default is replaced
+		if !m.listInit {
+			*v = nil
+			m.listInit = true
+		}
+		for _, s := range strings.Split(text, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				*v = append(*v, s)
+			}
+		}
+	}
+	return nil
+}
+
+// FlagError
+//	Command line parse errors
+type FlagError struct {
+	Invalid []string // invalid flag values, "name: reason"
+	Unknown []string // unregistered flags, only reported when FlagSet.Strict is true
+}
+
+func (m *FlagError) Error() string {
+	var msg []string
+	for _, s := range m.Invalid {
+		msg = append(msg, "invalid flag "+s)
+	}
+	if len(m.Unknown) > 0 {
+		msg = append(msg, "unknown flags: --"+strings.Join(m.Unknown, ", --"))
+	}
+	return strings.Join(msg, "; ")
+}
+
+// FlagSet
+//	Application flag registry
+type FlagSet struct {
+	// Strict reports unregistered flags as errors,
+	// except CEF/Chromium switches and names passed to Ignore.
+	// Sub processes (--type present) are not checked, CEF passes them many more switches
+	Strict  bool
+	args    []string
+	flags   map[string]*Flag
+	ignore  map[string]bool
+	unknown []string
+	rest    []string
+	parsed  bool
+	lock    sync.Mutex
+}
+
+// chromiumSwitches switches CEF/Chromium passes to its own processes
+var chromiumSwitches = []string{
+	"type", "lang", "locale", "user-data-dir", "log-file", "log-severity", "field-trial-handle",
+	"service-sandbox-type", "mojo-platform-channel-handle", "renderer-client-id", "launch-time-ticks",
+	"seatbelt-client", "enable-features", "disable-features", "device-scale-factor", "num-raster-threads",
+	"enable-main-frame-before-activation", "change-stack-guard-on-fork", "shared-files",
+	"first-renderer-process", "time-ticks-at-unix-epoch", "no-sandbox", "no-zygote", "use-gl", "use-angle",
+	"gpu-preferences", "locales-dir-path", "resources-dir-path", "enable-chrome-runtime", "disable-gpu",
+	"disable-gpu-compositing", "remote-debugging-port", "remote-allow-origins", "single-process",
+	"disable-web-security", "proxy-server", "no-proxy-server", "ignore-certificate-errors", "utility-sub-type",
+	"enable-logging", "v", "vmodule", "user-agent", "disable-extensions", "ppapi-flash-path", "ppapi-flash-version",
+}
+
+// NewFlagSet
+//	Creates a flag registry for args, args without the program name
+func NewFlagSet(args []string) *FlagSet {
+	m := &FlagSet{args: args, flags: make(map[string]*Flag), ignore: make(map[string]bool)}
+	m.Ignore(chromiumSwitches...)
+	return m
+}
+
+func (m *FlagSet) add(name, usage string, kind FlagKind, def string, value any) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.flags[name]; ok {
+		panic("flag redefined: " + name)
+	}
+	m.flags[name] = &Flag{Name: name, Usage: usage, Kind: kind, Default: def, value: value}
+}
+
+// Bool defines a bool flag: --name, --name=true, --name=false
+func (m *FlagSet) Bool(name string, value bool, usage string) *bool {
+	p := new(bool)
+	*p = value
+	m.add(name, usage, FkBool, strconv.FormatBool(value), p)
+	return p
+}
+
+// Int defines an int flag: --name=1, --name 1
+func (m *FlagSet) Int(name string, value int, usage string) *int {
+	p := new(int)
+	*p = value
+	m.add(name, usage, FkInt, strconv.Itoa(value), p)
+	return p
+}
+
+// String defines a string flag: --name=value, --name value
+func (m *FlagSet) String(name string, value string, usage string) *string {
+	p := new(string)
+	*p = value
+	m.add(name, usage, FkString, value, p)
+	return p
+}
+
+// Duration defines a time.Duration flag: --name=1m30s
+func (m *FlagSet) Duration(name string, value time.Duration, usage string) *time.Duration {
+	p := new(time.Duration)
+	*p = value
+	m.add(name, usage, FkDuration, value.String(), p)
+	return p
+}
+
+// List defines a list flag: --name=a,b --name=c => [a b c]
+func (m *FlagSet) List(name string, value []string, usage string) *[]string {
+	p := new([]string)
+	*p = append([]string{}, value...)
+	m.add(name, usage, FkList, strings.Join(value, ","), p)
+	return p
+}
+
+// Forward
+//	Marks flags to be forwarded to sub processes,
+//	see ICefCommandLine.AppendFlags used in SetOnBeforeChildProcessLaunch
+func (m *FlagSet) Forward(names ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, name := range names {
+		if f, ok := m.flags[name]; ok {
+			f.forward = true
+		}
+	}
+}
+
+// Ignore
+//	Names of unregistered switches that are not reported in Strict mode
+func (m *FlagSet) Ignore(names ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, name := range names {
+		m.ignore[name] = true
+	}
+}
+
+// Lookup returns the flag of name, nil if not registered
+func (m *FlagSet) Lookup(name string) *Flag {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.flags[name]
+}
+
+// Visit calls fn for each registered flag in name order
+func (m *FlagSet) Visit(fn func(flag *Flag)) {
+	m.lock.Lock()
+	var names = make([]string, 0, len(m.flags))
+	for name := range m.flags {
+		names = append(names, name)
+	}
+	m.lock.Unlock()
+	sort.Strings(names)
+	for _, name := range names {
+		fn(m.Lookup(name))
+	}
+}
+
+// Forwarded
+//	Returns name => value of forwarded flags present in the command line
+//	bool flags set to false and flags absent from the command line are not forwarded
+func (m *FlagSet) Forwarded() map[string]string {
+	result := make(map[string]string)
+	m.Visit(func(flag *Flag) {
+		if !flag.forward || !flag.set {
+			return
+		}
+		if flag.Kind == FkBool {
+			if flag.Value() == "true" {
+				result[flag.Name] = ""
+			}
+			return
+		}
+		result[flag.Name] = flag.Value()
+	})
+	return result
+}
+
+// Unknown returns the unregistered switch names found by Parse, including CEF/Chromium switches
+func (m *FlagSet) Unknown() []string {
+	return m.unknown
+}
+
+// Args returns the arguments that are not switches
+func (m *FlagSet) Args() []string {
+	return m.rest
+}
+
+// Parsed returns true if Parse has been called
+func (m *FlagSet) Parsed() bool {
+	return m.parsed
+}
+
+// Parse
+//	Parses the command line into the registered flags
+//	Can be called again after registering more flags
+//	returns *FlagError when values are invalid, or unknown flags are found in Strict mode
+func (m *FlagSet) Parse() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.parsed = true
+	m.unknown = nil
+	m.rest = nil
+	var ferr = &FlagError{}
+	for _, f := range m.flags {
+		f.listInit, f.set = false, false
+	}
+	// unknown flags are reported in the main process only
+	strict := m.Strict && !m.subProcess()
+	for i := 0; i < len(m.args); i++ {
+		arg := m.args[i]
+		if arg == "--" {
+			m.rest = append(m.rest, m.args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			m.rest = append(m.rest, arg)
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		value, hasValue := "", false
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name, value, hasValue = name[:idx], name[idx+1:], true
+		}
+		flag, ok := m.flags[name]
+		if !ok {
+			m.unknown = append(m.unknown, name)
+			if strict && !m.ignore[name] {
+				ferr.Unknown = append(ferr.Unknown, name)
+			}
+			continue
+		}
+		if !hasValue {
+			if flag.Kind == FkBool {
+				value, hasValue = "true", true
+			} else if i+1 < len(m.args) && (!strings.HasPrefix(m.args[i+1], "-") || flag.numeric() && isNegative(m.args[i+1])) {
+				i++
+				value, hasValue = m.args[i], true
+			} else {
+				ferr.Invalid = append(ferr.Invalid, fmt.Sprintf("--%s: missing %s value", name, flag.Kind))
+				continue
+			}
+		}
+		if err := flag.setValue(value); err != nil {
+			ferr.Invalid = append(ferr.Invalid, fmt.Sprintf("--%s=%s: %s value expected", name, value, flag.Kind))
+			continue
+		}
+		flag.set = true
+	}
+	if len(ferr.Invalid) > 0 || len(ferr.Unknown) > 0 {
+		return ferr
+	}
+	return nil
+}
+
+// subProcess returns true if --type is in the command line, CEF sub processes
+func (m *FlagSet) subProcess() bool {
+	for _, arg := range m.args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--type" || strings.HasPrefix(arg, "--type=") {
+			return true
+		}
+	}
+	return false
+}
+
+// numeric returns true if the value can be a negative number
+func (m *Flag) numeric() bool {
+	return m.Kind == FkInt || m.Kind == FkDuration
+}
+
+// isNegative returns true if s is a negative number: -5, -1.5, -1m30s
+func isNegative(s string) bool {
+	return len(s) > 1 && s[0] == '-' && s[1] >= '0' && s[1] <= '9'
+}
+
+// PrintDefaults
+//	Writes the help text of the registered flags
+func (m *FlagSet) PrintDefaults(w io.Writer) {
+	m.Visit(func(flag *Flag) {
+		line := "  --" + flag.Name
+		if flag.Kind != FkBool {
+			line += "=" + flag.Kind.String()
+		}
+		fmt.Fprintf(w, "%-28s %s", line, flag.Usage)
+		if flag.Default != "" && !(flag.Kind == FkBool && flag.Default == "false") {
+			fmt.Fprintf(w, " (default %s)", flag.Default)
+		}
+		fmt.Fprintln(w)
+	})
+}
+
+// ErrHelp is returned by Help when --help or -h is present
+var ErrHelp = errors.New("flag: help requested")
+
+// Help
+//	Writes the help text to w and returns ErrHelp if --help or -h is in the command line
+func (m *FlagSet) Help(w io.Writer) error {
+	for _, arg := range m.args {
+		if arg == "--help" || arg == "-h" {
+			m.PrintDefaults(w)
+			return ErrHelp
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package process
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlagSetParse(t *testing.T) {
+	flags := NewFlagSet([]string{"--type=renderer", "--debug", "--port", "9000", "--timeout=1m30s",
+		"--tag=a,b", "-tag=c", "--custom=1", "file.txt"})
+	debug := flags.Bool("debug", false, "debug")
+	port := flags.Int("port", 8080, "port")
+	name := flags.String("name", "app", "name")
+	timeout := flags.Duration("timeout", time.Second, "timeout")
+	tags := flags.List("tag", []string{"x"}, "tags")
+	flags.Forward("debug", "port", "name")
+	if err := flags.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	if !*debug || *port != 9000 || *name != "app" || *timeout != 90*time.Second {
+		t.Fatal("unexpected values", *debug, *port, *name, *timeout)
+	}
+	if len(*tags) != 3 || (*tags)[2] != "c" {
+		t.Fatal("unexpected list", *tags)
+	}
+	if len(flags.Unknown()) != 2 || len(flags.Args()) != 1 {
+		t.Fatal("unexpected unknown/args", flags.Unknown(), flags.Args())
+	}
+	forwarded := flags.Forwarded()
+	if len(forwarded) != 2 || forwarded["debug"] != "" || forwarded["port"] != "9000" {
+		t.Fatal("unexpected forwarded", forwarded)
+	}
+}
+
+func TestFlagSetErrors(t *testing.T) {
+	flags := NewFlagSet([]string{"--lang=en-US", "--port=abc", "--custom", "--name"})
+	flags.Int("port", 0, "port")
+	flags.String("name", "", "name")
+	flags.Strict = true
+	err := flags.Parse()
+	ferr, ok := err.(*FlagError)
+	if !ok {
+		t.Fatal("expected FlagError", err)
+	}
+	if len(ferr.Invalid) != 2 || len(ferr.Unknown) != 1 || ferr.Unknown[0] != "custom" {
+		t.Fatal("unexpected errors", ferr.Error())
+	}
+}
+
+func TestFlagSetSubProcess(t *testing.T) {
+	args := []string{"--type=renderer", "--crashpad-handler-pid=42", "--js-flags=--expose-gc", "--offset", "-5", "--delay", "-1s"}
+	flags := NewFlagSet(args)
+	offset := flags.Int("offset", 0, "offset")
+	delay := flags.Duration("delay", 0, "delay")
+	flags.Strict = true
+	if err := flags.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	if *offset != -5 || *delay != -time.Second {
+		t.Fatal("unexpected values", *offset, *delay)
+	}
+	// main process reports the unknown switches
+	flags = NewFlagSet(args[1:])
+	flags.Int("offset", 0, "offset")
+	flags.Duration("delay", 0, "delay")
+	flags.Strict = true
+	if ferr, ok := flags.Parse().(*FlagError); !ok || len(ferr.Unknown) != 2 || len(ferr.Invalid) != 0 {
+		t.Fatal("unexpected errors", ferr)
+	}
+}
@@ -12,6 +12,7 @@ package cef
 
 import (
 	"github.com/energye/energy/v2/cef/internal/def"
+	"github.com/energye/energy/v2/cef/process"
 	"github.com/energye/energy/v2/common/imports"
 	"github.com/energye/golcl/lcl"
 	"github.com/energye/golcl/lcl/api"
@@ -136,6 +137,28 @@ func (m *ICefCommandLine) AppendSwitchWithValue(name, value string) {
 	imports.Proc(def.CefCommandLine_AppendSwitchWithValue).Call(m.Instance(), api.PascalStr(name), api.PascalStr(value))
 }
 
+// AppendFlags
+//	Appends the forwarded flags of flags (process.FlagSet.Forward) that are present in the command line
+//	Used in SetOnBeforeChildProcessLaunch to pass application flags to sub processes
+//	 app.SetOnBeforeChildProcessLaunch(func(commandLine *cef.ICefCommandLine) {
+//		commandLine.AppendFlags(process.Flags)
+//	 })
+func (m *ICefCommandLine) AppendFlags(flags *process.FlagSet) {
+	if flags == nil {
+		return
+	}
+	for name, value := range flags.Forwarded() {
+		if m.HasSwitch(name) {
+			continue
+		}
+		if value == "" {
+			m.AppendSwitch(name)
+		} else {
+			m.AppendSwitchWithValue(name, value)
+		}
+	}
+}
+
 func (m *ICefCommandLine) HasArguments() bool {
 	r1, _, _ := imports.Proc(def.CefCommandLine_HasArguments).Call(m.Instance())
 	return api.GoBool(r1)
@@ -176,7 +176,7 @@ func (m *ICefFrame) EmitRender(messageId int32, eventName string, target target.
 			case error:
 				argumentJSONArray.Add(result.(error).Error())
 			default:
-				argumentJSONArray.Add(result)
+				argumentJSONArray.Add(argument.EncodeBigNumber(result))
 			}
 		}
 		message.Data = argumentJSONArray.Data()
@@ -13,6 +13,8 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/energye/energy/v2/cmd/internal"
 	"github.com/energye/energy/v2/cmd/internal/command"
 	"github.com/energye/energy/v2/cmd/internal/consts"
@@ -33,10 +35,20 @@ var commands = []*command.Command{
 	internal.CmdEnv,
 	internal.CmdInit,
 	internal.CmdBuild,
+	internal.CmdBundle,
+	internal.CmdList,
+	internal.CmdUse,
+	internal.CmdUninstall,
+	internal.CmdDoctor,
+	internal.CmdConfig,
+	internal.CmdDev,
+	internal.CmdUpgrade,
 }
 
 func main() {
-	term.GoENERGY()
+	if quiet, jsonOutput := outputMode(); !quiet && !jsonOutput {
+		term.GoENERGY()
+	}
 	termRun()
 }
 
@@ -46,18 +58,26 @@ func termRun() {
 	parser := flags.NewParser(cc, flags.HelpFlag|flags.PassDoubleDash)
 	if len(os.Args) < 2 {
 		parser.WriteHelp(term.TermOut)
-		//exit <- 1
-		os.Exit(1)
+		os.Exit(command.ExitUsage)
 	}
 	if extraArgs, err := parser.ParseArgs(os.Args[1:]); err != nil {
-		if extraArgs[0] == "-v" || extraArgs[0] == "v" {
+		if len(extraArgs) > 0 && (extraArgs[0] == "-v" || extraArgs[0] == "v") {
 			term.Section.Println(" ", term.CliVersion)
-		} else {
+			os.Exit(command.ExitOK)
+		}
+		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
 			println(err.Error())
+			os.Exit(command.ExitOK)
 		}
-		//exit <- 1
-		os.Exit(1)
+		_, jsonOutput := outputMode()
+		term.SetMode(false, false, jsonOutput)
+		exit(cc, "", command.WithCode(command.ExitUsage, err))
 	} else {
+		// doctor --json 同 --output json
+		if cc.Doctor.JSON {
+			cc.Output = "json"
+		}
+		term.SetMode(cc.Yes || cc.NonInteractive, cc.Quiet, cc.Output == "json")
 		switch parser.Active.Name {
 		case "install":
 			cc.Index = 1
@@ -73,6 +93,22 @@ func termRun() {
 			cc.Index = 6
 		case "build":
 			cc.Index = 7
+		case "bundle":
+			cc.Index = 8
+		case "list":
+			cc.Index = 9
+		case "use":
+			cc.Index = 10
+		case "uninstall":
+			cc.Index = 11
+		case "doctor":
+			cc.Index = 12
+		case "config":
+			cc.Index = 13
+		case "dev":
+			cc.Index = 14
+		case "upgrade":
+			cc.Index = 15
 		case "v":
 			term.Section.Println(" ", term.CliVersion)
 			return
@@ -80,17 +116,69 @@ func termRun() {
 		cmd := commands[cc.Index]
 		if len(extraArgs) < 1 || extraArgs[len(extraArgs)-1] != "." {
 			term.Section.Println(cmd.UsageLine, "\n", cmd.Long)
-			//exit <- 1
-			os.Exit(1)
+			exit(cc, parser.Active.Name, command.WithCode(command.ExitUsage, errors.New("missing . at the end of the command to execute it")))
 		}
 		term.Section.Println(cmd.Short)
 		readConfig(cc)
-		if err := cmd.Run(cc); err != nil {
+		if err := cmd.Run(cc); err != nil || term.JSON || os.Getenv(consts.EnergyResultFileKey) != "" {
+			exit(cc, parser.Active.Name, err)
+		}
+	}
+}
+
+// 解析参数前读取输出模式, --quiet 或 --output json 时不输出 logo
+func outputMode() (quiet, jsonOutput bool) {
+	args := os.Args[1:]
+	for i, arg := range args {
+		switch arg {
+		case "-q", "--quiet":
+			quiet = true
+		case "--json", "--output=json":
+			jsonOutput = true
+		case "--output":
+			if i+1 < len(args) && args[i+1] == "json" {
+				jsonOutput = true
+			}
+		}
+	}
+	return
+}
+
+// 输出结果并退出, 退出码见 command.ExitCode
+//  --output json: 输出 command.JSONResult, 包含命令结果
+//  --quiet: 错误写入标准错误
+//  环境变量 ENERGY_RESULT_FILE: command.JSONResult 同时写入该文件, 不影响输出模式
+func exit(c *command.Config, name string, err error) {
+	code := command.ExitCode(err)
+	result := &command.JSONResult{Command: name, Success: err == nil, Code: code, Result: c.Result}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if file := os.Getenv(consts.EnergyResultFileKey); file != "" {
+		if e := writeResultFile(file, result); e != nil {
+			fmt.Fprintln(os.Stderr, e.Error())
+		}
+	}
+	if term.JSON {
+		if e := term.WriteJSON(result); e != nil {
+			fmt.Fprintln(os.Stderr, e.Error())
+		}
+	} else if err != nil {
+		if term.Quiet {
+			fmt.Fprintln(os.Stderr, err.Error())
+		} else {
 			term.Section.Println(err.Error())
-			//exit <- 1
-			os.Exit(1)
 		}
 	}
+	os.Exit(code)
+}
+
+func writeResultFile(file string, result *command.JSONResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
 }
 
 func readConfig(c *command.Config) {
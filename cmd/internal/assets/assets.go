@@ -63,6 +63,11 @@ func ReadFile(proj *project.Project, assetsFSPath, file string) ([]byte, error)
 	return content, err
 }
 
+// Sub 返回内置资源子目录
+func Sub(dir string) (fs.FS, error) {
+	return fs.Sub(assets, dir)
+}
+
 // WriteFile 写文件到本地目录
 func WriteFile(projectData *project.Project, file string, content []byte) error {
 	buildOutDir := BuildOutPath(projectData)
@@ -0,0 +1,29 @@
+import {useEffect, useState} from 'react'
+import {available, emit, on} from './ipc.js'
+
+export default function App() {
+    const [osInfo, setOsInfo] = useState('--')
+    const [windowType, setWindowType] = useState('--')
+    const [count, setCount] = useState(0)
+
+    useEffect(() => {
+        on('windowType', (type) => setWindowType(type))
+        emit('osInfo', [], (os) => setOsInfo(os || 'not running in energy'))
+    }, [])
+
+    function increment() {
+        const value = count + 1
+        setCount(value)
+        emit('count', [value])
+    }
+
+    return (
+        <div className="module">
+            <p className="title">Welcome to your new project!</p>
+            <p>OS Info: {osInfo}</p>
+            <p>Window Type: {windowType}</p>
+            <button onClick={increment}>count {count}</button>
+            {!available() && <p>ipc is available when running in energy</p>}
+        </div>
+    )
+}
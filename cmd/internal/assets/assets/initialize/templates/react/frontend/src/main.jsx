@@ -0,0 +1,10 @@
+import React from 'react'
+import ReactDOM from 'react-dom/client'
+import App from './App.jsx'
+import './App.css'
+
+ReactDOM.createRoot(document.getElementById('app')).render(
+    <React.StrictMode>
+        <App/>
+    </React.StrictMode>,
+)
@@ -0,0 +1,16 @@
+import {defineConfig} from 'vite'
+import react from '@vitejs/plugin-react'
+
+// base ./ relative paths, dist is loaded by energy LocalLoadConfig
+export default defineConfig({
+    plugins: [react()],
+    base: './',
+    build: {
+        outDir: 'dist',
+        emptyOutDir: true,
+    },
+    server: {
+        port: 5173,
+        strictPort: true,
+    },
+})
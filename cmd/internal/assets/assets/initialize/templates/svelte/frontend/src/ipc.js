@@ -0,0 +1,29 @@
+// energy ipc bridge
+// window.ipc is injected by energy, in a normal browser (vite dev server) calls are ignored
+
+function bridge() {
+    return window.ipc
+}
+
+// available running in energy
+export function available() {
+    return !!bridge()
+}
+
+// on listen to events emitted by go: ipc.Emit(name, ...args)
+export function on(name, callback) {
+    const ipc = bridge()
+    if (ipc) {
+        ipc.on(name, callback)
+    }
+}
+
+// emit trigger a go event: ipc.On(name, func), callback receives the go return values
+export function emit(name, args = [], callback) {
+    const ipc = bridge()
+    if (ipc) {
+        ipc.emit(name, args, callback)
+    } else if (callback) {
+        callback()
+    }
+}
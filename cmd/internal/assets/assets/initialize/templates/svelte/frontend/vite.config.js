@@ -0,0 +1,16 @@
+import {defineConfig} from 'vite'
+import {svelte} from '@sveltejs/vite-plugin-svelte'
+
+// base ./ relative paths, dist is loaded by energy LocalLoadConfig
+export default defineConfig({
+    plugins: [svelte()],
+    base: './',
+    build: {
+        outDir: 'dist',
+        emptyOutDir: true,
+    },
+    server: {
+        port: 5173,
+        strictPort: true,
+    },
+})
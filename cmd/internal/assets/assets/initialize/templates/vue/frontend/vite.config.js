@@ -0,0 +1,16 @@
+import {defineConfig} from 'vite'
+import vue from '@vitejs/plugin-vue'
+
+// base ./ relative paths, dist is loaded by energy LocalLoadConfig
+export default defineConfig({
+    plugins: [vue()],
+    base: './',
+    build: {
+        outDir: 'dist',
+        emptyOutDir: true,
+    },
+    server: {
+        port: 5173,
+        strictPort: true,
+    },
+})
@@ -0,0 +1,15 @@
+#!/bin/sh
+
+# Relocatable launcher, {{.EXECUTE}} and CEF are in {{.LIBDIR}} relative to this script
+HERE="$(dirname "$(readlink -f "$0")")"
+APPDIR="$HERE/{{.LIBDIR}}"
+
+export LD_LIBRARY_PATH="$APPDIR${LD_LIBRARY_PATH:+:$LD_LIBRARY_PATH}"
+{{- if .PRELOAD}}
+
+# fix: linux arm: Error loading libcef.so
+# temp LD_PRELOAD, Load libcef.so correctly
+export LD_PRELOAD="$APPDIR/libcef.so"
+{{- end}}
+
+exec "$APPDIR/{{.EXECUTE}}" "$@"
@@ -15,10 +15,11 @@ package internal
 import (
 	"github.com/energye/energy/v2/cmd/internal/build"
 	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/workspace"
 )
 
 var CmdBuild = &command.Command{
-	UsageLine: "build -p [path] -u [upx] --UpxFlag --gtk -d [dll]",
+	UsageLine: "build -p [path] -u [upx] --UpxFlag --gtk -d [dll] --target [os/arch] --dir [root] --projects [names]",
 	Short:     "build energy project",
 	Long: `
 	Building energy project
@@ -35,6 +36,16 @@ var CmdBuild = &command.Command{
 	    gtk3 use latest: -tags="tempdll && gtk3" 
 	  macos:
 	    use latest: -tags="tempdll"
+	--target Cross compile targets, os/arch separated by comma: linux/amd64,linux/arm64,windows/amd64
+	  output: build/[os]-[arch]/, summary: build/build-summary.json
+	  framework: installed framework of the target, energy install --os=[os] --arch=[arch]
+	  cgo C cross compiler of linux and macos: CC_[os]_[arch] or CC, CC_linux_arm64=aarch64-linux-gnu-gcc
+	Reproducible build: -trimpath, build time from SOURCE_DATE_EPOCH or the git commit time
+	  version, git commit and build time are injected into github.com/energye/energy/v2/pkgs/buildinfo
+	--dir Workspace root, build all energy.json projects under it in parallel
+	--projects Workspace projects, names or relative paths separated by comma, default all
+	  without --dir the nearest energy.workspace.json or the current path is the workspace root
+	--jobs Number of projects built in parallel, default number of CPUs
 	
 	.  Execute command
 `,
@@ -45,5 +56,8 @@ func init() {
 }
 
 func runBuild(c *command.Config) error {
+	if workspace.Enabled(c, c.Build.Projects) {
+		return workspace.Run(c, "build", c.Build.Projects, c.Build.Jobs)
+	}
 	return build.Build(c)
 }
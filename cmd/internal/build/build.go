@@ -11,8 +11,15 @@
 package build
 
 import (
+	"errors"
+	"fmt"
 	"github.com/energye/energy/v2/cmd/internal/command"
 	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
 )
 
 const (
@@ -22,9 +29,52 @@ const (
 func Build(c *command.Config) error {
 	// 读取项目配置文件 energy.json 在main函数目录
 	if proj, err := project.NewProject(c.Build.Path); err != nil {
-		return err
+		return command.WithCode(command.ExitConfig, err)
 	} else {
 		proj.TempDll = c.Build.TempDll
-		return build(c, proj)
+		if !tools.CommandExists("go") {
+			return command.WithCode(command.ExitEnv, errors.New("go command not found, install: energy install ."))
+		}
+		if c.Build.Target != "" {
+			// 交叉编译
+			return command.WithCode(command.ExitBuild, buildTargets(c, proj))
+		}
+		start := time.Now()
+		err = build(c, proj)
+		c.Result = []*targetResult{hostResult(proj, start, err)}
+		return command.WithCode(command.ExitBuild, err)
+	}
+}
+
+// 当前系统的编译结果, 和交叉编译的构建结果格式相同
+func hostResult(proj *project.Project, start time.Time, err error) *targetResult {
+	info := NewBuildInfo(proj)
+	output := proj.OutputFilename
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(proj.ProjectPath, output)
+	}
+	result := &targetResult{
+		Target:    runtime.GOOS + "/" + runtime.GOARCH,
+		Output:    output,
+		Framework: proj.FrameworkPath,
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildTime: info.BuildTime(),
+		Duration:  time.Since(start).Round(time.Millisecond).String(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	} else if fi, err := os.Stat(output); err == nil {
+		result.Size = fi.Size()
+	}
+	return result
+}
+
+// 记录命令执行错误, 用于 toolsCommand.CMD.MessageCallback
+func commandError(name string, err *error) func([]byte, error) {
+	return func(_ []byte, e error) {
+		if e != nil && *err == nil {
+			*err = fmt.Errorf("%s failed: %v", name, e)
+		}
 	}
 }
@@ -32,9 +32,15 @@ func build(c *command.Config, proj *project.Project) (err error) {
 	if proj.TempDll {
 		args = append(args, "--tags=tempdll")
 	}
-	args = append(args, "-ldflags", "-s -w")
+	args = append(args, reproducibleArgs(NewBuildInfo(proj), "-s -w")...)
 	args = append(args, "-o", proj.OutputFilename)
+	cmd.MessageCallback = commandError("go build", &err)
 	cmd.Command("go", args...)
+	cmd.MessageCallback = nil
+	if err != nil {
+		cmd.Close()
+		return err
+	}
 	cmd.Command("strip", proj.OutputFilename)
 	// upx
 	if c.Build.Upx && tools.CommandExists("upx") {
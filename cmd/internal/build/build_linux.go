@@ -14,12 +14,12 @@
 package build
 
 import (
+	"errors"
 	"github.com/energye/energy/v2/cmd/internal/command"
 	"github.com/energye/energy/v2/cmd/internal/project"
 	"github.com/energye/energy/v2/cmd/internal/term"
 	"github.com/energye/energy/v2/cmd/internal/tools"
 	toolsCommand "github.com/energye/golcl/tools/command"
-	"os"
 	"strings"
 )
 
@@ -33,14 +33,19 @@ func build(c *command.Config, proj *project.Project) (err error) {
 	if proj.TempDll {
 		c.Build.Gtk = strings.ToLower(c.Build.Gtk)
 		if c.Build.Gtk != "gtk3" && c.Build.Gtk != "gtk2" {
-			term.Logger.Error("Compiling and enabling TempDll. gtk can only be gtk2 or gtk3")
-			os.Exit(1)
+			return command.WithCode(command.ExitUsage, errors.New("compiling and enabling TempDll. gtk can only be gtk2 or gtk3"))
 		}
 		args = append(args, "--tags=tempdll "+c.Build.Gtk)
 	}
-	args = append(args, "-ldflags", "-s -w")
+	args = append(args, reproducibleArgs(NewBuildInfo(proj), "-s -w")...)
 	args = append(args, "-o", proj.OutputFilename)
+	cmd.MessageCallback = commandError("go build", &err)
 	cmd.Command("go", args...)
+	cmd.MessageCallback = nil
+	if err != nil {
+		cmd.Close()
+		return err
+	}
 	cmd.Command("strip", proj.OutputFilename)
 	// upx
 	if c.Build.Upx && tools.CommandExists("upx") {
@@ -0,0 +1,123 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package build
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 应用读取构建信息的包, 见 pkgs/buildinfo
+const buildInfoPackage = "github.com/energye/energy/v2/pkgs/buildinfo"
+
+// BuildInfo 构建信息
+//  编译 -X 注入, windows 版本信息, linux control 使用相同的值
+type BuildInfo struct {
+	Version string    // energy.json info.productVersion
+	Commit  string    // git 提交, 有未提交修改时追加 -dirty
+	Time    time.Time // SOURCE_DATE_EPOCH, git 提交时间, 都没有时当前时间
+}
+
+// NewBuildInfo 读取项目构建信息
+func NewBuildInfo(proj *project.Project) *BuildInfo {
+	m := &BuildInfo{Version: proj.Info.ProductVersion}
+	var git = func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = proj.ProjectPath
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if m.Commit = git("rev-parse", "HEAD"); m.Commit != "" {
+		if git("status", "--porcelain", "--untracked-files=no") != "" {
+			m.Commit += "-dirty"
+		}
+	}
+	if epoch, ok := sourceDateEpoch(); ok {
+		m.Time = epoch
+	} else if ct, err := strconv.ParseInt(git("log", "-1", "--format=%ct"), 10, 64); err == nil {
+		m.Time = time.Unix(ct, 0).UTC()
+	} else {
+		m.Time = time.Now().UTC()
+	}
+	return m
+}
+
+// SOURCE_DATE_EPOCH, https://reproducible-builds.org/specs/source-date-epoch/
+func sourceDateEpoch() (time.Time, bool) {
+	value := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH"))
+	if value == "" {
+		return time.Time{}, false
+	}
+	epoch, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(epoch, 0).UTC(), true
+}
+
+// BuildTime RFC3339
+func (m *BuildInfo) BuildTime() string {
+	return m.Time.UTC().Format(time.RFC3339)
+}
+
+// FileVersion windows 文件版本 a.b.c.d, 非数字部分忽略
+func (m *BuildInfo) FileVersion() string {
+	var nums []string
+	for _, s := range strings.Split(strings.TrimPrefix(m.Version, "v"), ".") {
+		n := 0
+		for _, r := range s {
+			if r < '0' || r > '9' {
+				break
+			}
+			n = n*10 + int(r-'0')
+		}
+		nums = append(nums, strconv.Itoa(n))
+		if len(nums) == 4 {
+			break
+		}
+	}
+	for len(nums) < 4 {
+		nums = append(nums, "0")
+	}
+	return strings.Join(nums, ".")
+}
+
+// -X 注入参数
+func (m *BuildInfo) ldflags() string {
+	var flags []string
+	for _, item := range [][2]string{{"Version", m.Version}, {"Commit", m.Commit}, {"BuildTime", m.BuildTime()}} {
+		name, value := item[0], item[1]
+		if value == "" {
+			continue
+		}
+		flag := fmt.Sprintf("-X %s.%s=%s", buildInfoPackage, name, value)
+		if strings.ContainsAny(value, " '\"") {
+			flag = fmt.Sprintf("-X '%s.%s=%s'", buildInfoPackage, name, strings.ReplaceAll(value, "'", ""))
+		}
+		flags = append(flags, flag)
+	}
+	return strings.Join(flags, " ")
+}
+
+// 可重现构建参数
+//  -trimpath: 去除本地路径
+//  -ldflags: 原有参数, -buildid= 和构建信息
+func reproducibleArgs(info *BuildInfo, ldflags string) []string {
+	return []string{"-trimpath", "-ldflags", ldflags + " -buildid= " + info.ldflags()}
+}
@@ -0,0 +1,58 @@
+package build
+
+import (
+	"bytes"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildInfo(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	info := NewBuildInfo(&project.Project{ProjectPath: t.TempDir(), Info: project.Info{ProductVersion: "1.2.3-beta"}})
+	if info.BuildTime() != "2023-11-14T22:13:20Z" {
+		t.Fatal(info.BuildTime())
+	}
+	if info.FileVersion() != "1.2.3.0" {
+		t.Fatal(info.FileVersion())
+	}
+	flags := info.ldflags()
+	if !strings.Contains(flags, "-X "+buildInfoPackage+".Version=1.2.3-beta") || !strings.Contains(flags, buildInfoPackage+".BuildTime=2023-11-14T22:13:20Z") {
+		t.Fatal(flags)
+	}
+	info.Version = "1.0 beta"
+	if flags = info.ldflags(); !strings.Contains(flags, "-X '"+buildInfoPackage+".Version=1.0 beta'") {
+		t.Fatal(flags)
+	}
+}
+
+func TestReproducibleBuild(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	var outputs [][]byte
+	for i := 0; i < 2; i++ {
+		// 不同目录编译结果相同
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module demo\n\ngo 1.18\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() { println(\"demo\") }\n"), 0644)
+		proj := &project.Project{Name: "demo", ProjectPath: dir, OutputFilename: "demo", Info: project.Info{ProductVersion: "1.0.0"}}
+		result := buildTarget(&command.Config{}, proj, Target{"linux", "amd64"}, filepath.Join(dir, "build"))
+		if result.Error != "" {
+			t.Fatal(result.Error)
+		}
+		data, err := os.ReadFile(result.Output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, data)
+	}
+	if !bytes.Equal(outputs[0], outputs[1]) {
+		t.Fatal("build is not reproducible")
+	}
+}
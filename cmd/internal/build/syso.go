@@ -0,0 +1,156 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package build
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/assets"
+	"github.com/energye/energy/v2/cmd/internal/icons"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"github.com/energye/energy/v2/pkgs/winicon"
+	"github.com/tc-hib/winres"
+	"github.com/tc-hib/winres/version"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	windowManifest    = "windows/app.exe.manifest"
+	windowVersionInfo = "windows/version.info.json"
+)
+
+// 生成syso图标
+//  纯 Go 生成, 任意系统都可以生成 windows 资源
+//  文件名后缀 _windows_[arch].syso 只在编译对应 windows 架构时链接
+func generaSYSO(iconPath string, proj *project.Project, info *BuildInfo, goarch string) (string, error) {
+	rs := &winres.ResourceSet{}
+	iconFile, err := os.Open(iconPath)
+	if err != nil {
+		return "", err
+	}
+	defer iconFile.Close()
+	// icon
+	ico, err := winres.LoadICO(iconFile)
+	if err != nil {
+		return "", fmt.Errorf("couldn't load icon from icon.ico: %w", err)
+	}
+	err = rs.SetIcon(winres.RT_ICON, ico)
+	if err != nil {
+		return "", err
+	}
+	// Manifest
+	var manifestData []byte
+	if proj.Info.Manifest != "" {
+		manifestData, err = ioutil.ReadFile(proj.Info.Manifest)
+	}
+	if manifestData == nil || err != nil {
+		manifestData, err = assets.ReadFile(proj, assetsFSPath, windowManifest)
+	}
+	if err != nil {
+		return "", err
+	}
+	xmlData, err := winres.AppManifestFromXML(manifestData)
+	if err != nil {
+		return "", err
+	}
+	rs.SetManifest(xmlData)
+	// versionInfo
+	versionInfo, err := assets.ReadFile(proj, assetsFSPath, windowVersionInfo)
+	if err != nil {
+		return "", err
+	}
+	// 版本信息和编译注入的构建信息相同
+	data := make(map[string]any)
+	projInfo := proj.Info
+	projInfo.ProductVersion = info.Version
+	if projInfo.FileVersion == "" {
+		projInfo.FileVersion = info.FileVersion()
+	}
+	data["Info"] = projInfo
+	data["BuildInfo"] = info
+	versionInfo, err = tools.RenderTemplate(string(versionInfo), data)
+	if err != nil {
+		return "", err
+	}
+	if len(versionInfo) != 0 {
+		var v version.Info
+		if err := v.UnmarshalJSON(versionInfo); err != nil {
+			return "", err
+		}
+		rs.SetVersionInfo(v)
+	}
+	archs := map[string]winres.Arch{
+		"amd64": winres.ArchAMD64,
+		"arm64": winres.ArchARM64,
+		"386":   winres.ArchI386,
+	}
+	targetArch, supported := archs[goarch]
+	if !supported {
+		return "", fmt.Errorf("arch '%s' not supported", goarch)
+	}
+	targetFile := filepath.Join(proj.ProjectPath, fmt.Sprintf("%s_windows_%s.syso", proj.Name, goarch))
+	fout, err := os.Create(targetFile)
+	if err != nil {
+		return "", err
+	}
+	defer fout.Close()
+	err = rs.WriteObject(fout, targetArch)
+	if err != nil {
+		return targetFile, err
+	}
+	return targetFile, nil
+}
+
+// 检查 ico 能否解码, 缺少 windows 需要的尺寸等问题输出警告
+func validateICON(iconPath string) error {
+	f, err := os.Open(iconPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	warnings, err := winicon.Validate(f)
+	if err != nil {
+		return fmt.Errorf("invalid icon %s: %w", iconPath, err)
+	}
+	for _, warning := range warnings {
+		term.Logger.Warn(fmt.Sprintf("icon %s: %s", filepath.Base(iconPath), warning))
+	}
+	return nil
+}
+
+// GeneraICON 生成应用图标，如果配置的不是ico图标，把png转换ico
+func GeneraICON(proj *project.Project) (string, error) {
+	iconPath := proj.Info.Icon
+	if !tools.IsExist(iconPath) {
+		return "", fs.ErrNotExist
+	}
+	iconExt := filepath.Ext(iconPath)
+	if strings.ToLower(iconExt) == ".ico" {
+		// 嵌入前检查 ico
+		return iconPath, validateICON(iconPath)
+	} else {
+		// png => ico
+		img, err := icons.Load(iconPath)
+		if err != nil {
+			return "", err
+		}
+		iconPath = filepath.Join(assets.BuildOutPath(proj), "windows", "icon.ico")
+		if err = icons.ICO(img, iconPath); err != nil {
+			return "", err
+		}
+	}
+	return iconPath, nil
+}
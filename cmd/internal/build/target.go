@@ -0,0 +1,336 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/assets"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/framework"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"github.com/pterm/pterm"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 交叉编译
+//  energy build --target linux/amd64,linux/arm64,windows/amd64
+//  每个目标输出到 build/[os]-[arch]/, 构建结果写入 build/build-summary.json
+//  linux, darwin 使用 cgo, 交叉编译时通过环境变量 CC_[os]_[arch] 指定 C 编译器, 例如 CC_linux_arm64=aarch64-linux-gnu-gcc
+
+const buildSummaryFile = "build-summary.json"
+
+// 支持的目标系统架构
+var supportTargets = map[string][]string{
+	"windows": {"386", "amd64", "arm64"},
+	"linux":   {"amd64", "arm64"},
+	"darwin":  {"amd64", "arm64"},
+}
+
+// Target 编译目标
+type Target struct {
+	OS   string
+	Arch string
+}
+
+func (m Target) String() string {
+	return m.OS + "/" + m.Arch
+}
+
+// IsHost 是否为当前系统架构
+func (m Target) IsHost() bool {
+	return m.OS == runtime.GOOS && m.Arch == runtime.GOARCH
+}
+
+// 目标编译结果
+type targetResult struct {
+	Target    string `json:"target"`
+	Output    string `json:"output"`
+	Size      int64  `json:"size"`
+	Framework string `json:"framework"` // 目标使用的框架目录, 打包时使用
+	Tags      string `json:"tags,omitempty"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"buildTime"`
+	Duration  string `json:"duration"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ParseTargets 解析 --target, os/arch 逗号分隔, 去除重复
+func ParseTargets(value string) ([]Target, error) {
+	var (
+		targets []Target
+		exists  = make(map[string]bool)
+	)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		s := strings.Split(item, "/")
+		if len(s) != 2 {
+			return nil, fmt.Errorf("invalid target %s, expected os/arch", item)
+		}
+		t := Target{OS: strings.ToLower(s[0]), Arch: strings.ToLower(s[1])}
+		if !isSupportTarget(t) {
+			return nil, fmt.Errorf("unsupported target %s", item)
+		}
+		if !exists[t.String()] {
+			exists[t.String()] = true
+			targets = append(targets, t)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no target specified")
+	}
+	return targets, nil
+}
+
+func isSupportTarget(t Target) bool {
+	for _, arch := range supportTargets[t.OS] {
+		if arch == t.Arch {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetFramework 目标使用的框架
+//  当前系统架构使用项目框架, 其它从已安装框架中选择同系统架构的框架
+//  优先和项目框架版本相同, 其次最新版本
+func TargetFramework(proj *project.Project, t Target) *framework.Framework {
+	projectFramework, _ := framework.Find(proj.FrameworkPath)
+	if abs, err := filepath.Abs(proj.FrameworkPath); err == nil && projectFramework == nil {
+		projectFramework, _ = framework.Find(abs)
+	}
+	if t.IsHost() {
+		if projectFramework != nil {
+			return projectFramework
+		}
+		return &framework.Framework{Path: proj.FrameworkPath, OS: t.OS, Arch: t.Arch}
+	}
+	frameworks, _ := framework.List()
+	var result *framework.Framework
+	for _, f := range frameworks {
+		if f.OS != t.OS || f.Arch != t.Arch || !f.Exists() {
+			continue
+		}
+		if projectFramework != nil && f.Version == projectFramework.Version && f.CEFMajor() == projectFramework.CEFMajor() {
+			return f
+		}
+		if result == nil || tools.Compare(f.Version, result.Version) {
+			result = f
+		}
+	}
+	return result
+}
+
+// TempDll 编译标记
+//  windows: tempdll, CEF 109 tempdll win7
+//  linux: tempdll gtk3, CEF <= 106 或 --gtk=gtk2 时 tempdll gtk2
+//  darwin: tempdll
+func targetTags(c *command.Config, t Target, f *framework.Framework) (string, error) {
+	var cefMajor int
+	if f != nil {
+		cefMajor, _ = strconv.Atoi(f.CEFMajor())
+	}
+	switch t.OS {
+	case "windows":
+		if cefMajor == 109 {
+			return "tempdll win7", nil
+		}
+	case "linux":
+		gtk := strings.ToLower(c.Build.Gtk)
+		if gtk != "gtk3" && gtk != "gtk2" {
+			return "", fmt.Errorf("compiling and enabling TempDll. gtk can only be gtk2 or gtk3")
+		}
+		if cefMajor > 0 && cefMajor <= 106 {
+			gtk = "gtk2"
+		}
+		return "tempdll " + gtk, nil
+	}
+	return "tempdll", nil
+}
+
+// TargetOutputFilename 输出文件名, windows 添加 .exe
+func TargetOutputFilename(proj *project.Project, t Target) string {
+	name := strings.TrimSuffix(proj.OutputFilename, ".exe")
+	if t.OS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// TargetOutput 目标的执行文件 build/[os]-[arch]/[name]
+func TargetOutput(proj *project.Project, t Target) string {
+	return filepath.Join(assets.BuildOutPath(proj), t.OS+"-"+t.Arch, TargetOutputFilename(proj, t))
+}
+
+// 编译所有目标, 失败时继续编译其它目标
+func buildTargets(c *command.Config, proj *project.Project) error {
+	targets, err := ParseTargets(c.Build.Target)
+	if err != nil {
+		return command.WithCode(command.ExitUsage, err)
+	}
+	outDir := assets.BuildOutPath(proj)
+	var results []*targetResult
+	for _, t := range targets {
+		term.Section.Println("Building", t.String())
+		result := buildTarget(c, proj, t, filepath.Join(outDir, t.OS+"-"+t.Arch))
+		if result.Error != "" {
+			term.Logger.Error(t.String() + ": " + result.Error)
+		}
+		results = append(results, result)
+	}
+	c.Result = results
+	return buildSummary(outDir, results)
+}
+
+func buildTarget(c *command.Config, proj *project.Project, t Target, outDir string) (result *targetResult) {
+	start := time.Now()
+	result = &targetResult{Target: t.String()}
+	defer func() {
+		result.Duration = time.Since(start).Round(time.Millisecond).String()
+	}()
+	var fail = func(err error) *targetResult {
+		result.Error = err.Error()
+		return result
+	}
+	f := TargetFramework(proj, t)
+	if f != nil {
+		result.Framework = f.Path
+	} else {
+		term.Logger.Warn(fmt.Sprintf("No framework installed for %s, install: energy install --os=%s --arch=%s .", t, t.OS, t.Arch))
+	}
+	if err := os.MkdirAll(outDir, fs.ModePerm); err != nil {
+		return fail(err)
+	}
+	output := filepath.Join(outDir, TargetOutputFilename(proj, t))
+	result.Output = output
+	var args = []string{"build"}
+	if proj.TempDll {
+		tags, err := targetTags(c, t, f)
+		if err != nil {
+			return fail(err)
+		}
+		result.Tags = tags
+		args = append(args, "--tags="+tags)
+	}
+	info := NewBuildInfo(proj)
+	result.Version, result.Commit, result.BuildTime = info.Version, info.Commit, info.BuildTime()
+	ldflags := "-s -w"
+	if t.OS == "windows" {
+		ldflags += " -H windowsgui"
+		// windows 资源, 图标 manifest 版本信息
+		iconPath, err := GeneraICON(proj)
+		if err != nil {
+			return fail(err)
+		}
+		syso, err := generaSYSO(iconPath, proj, info, t.Arch)
+		if syso != "" {
+			defer os.Remove(syso)
+		}
+		if err != nil {
+			return fail(err)
+		}
+	}
+	args = append(args, reproducibleArgs(info, ldflags)...)
+	args = append(args, "-o", output)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = proj.ProjectPath
+	cmd.Env = append(append(os.Environ(), "GOOS="+t.OS, "GOARCH="+t.Arch), cgoEnv(t)...)
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		return fail(fmt.Errorf("%v\n%s", err, strings.TrimSpace(out.String())))
+	}
+	// upx, 不支持 macos
+	if c.Build.Upx && t.OS != "darwin" {
+		if tools.CommandExists("upx") {
+			term.Section.Println("Upx compression", t.String())
+			upxArgs := []string{"--best", "--no-color", "--no-progress"}
+			if c.Build.UpxFlag != "" {
+				upxArgs = strings.Split(c.Build.UpxFlag, " ")
+			}
+			if data, err := exec.Command("upx", append(upxArgs, output)...).CombinedOutput(); err != nil {
+				term.Logger.Warn("upx: " + err.Error() + " " + string(data))
+			}
+		} else {
+			term.Logger.Warn("upx command not found")
+		}
+	}
+	if info, err := os.Stat(output); err == nil {
+		result.Size = info.Size()
+	}
+	return result
+}
+
+// 交叉编译 linux, macos 需要 cgo, C 交叉编译器 CC_[os]_[arch] 或 CC
+//  go 交叉编译时默认关闭 cgo, 有编译器时设置 CGO_ENABLED=1, 没有时提示
+func cgoEnv(t Target) []string {
+	if t.IsHost() || t.OS == "windows" {
+		return nil
+	}
+	if cc := os.Getenv("CC_" + t.OS + "_" + t.Arch); cc != "" {
+		return []string{"CGO_ENABLED=1", "CC=" + cc}
+	}
+	if os.Getenv("CC") != "" {
+		return []string{"CGO_ENABLED=1"}
+	}
+	term.Logger.Warn(fmt.Sprintf("%s requires cgo, set CC_%s_%s or CC to a C cross compiler", t, t.OS, t.Arch))
+	return nil
+}
+
+// 输出构建结果, 写入 build-summary.json
+func buildSummary(outDir string, results []*targetResult) error {
+	tableData := pterm.TableData{
+		{"Target", "Status", "Output", "Size", "Framework", "Duration"},
+	}
+	var failed []string
+	for _, r := range results {
+		status := pterm.Green("OK")
+		if r.Error != "" {
+			status = pterm.Red("FAIL")
+			failed = append(failed, r.Target)
+		}
+		var size string
+		if r.Size > 0 {
+			size = fmt.Sprintf("%.2f MB", float64(r.Size)/1024/1024)
+		}
+		tableData = append(tableData, []string{r.Target, status, r.Output, size, r.Framework, r.Duration})
+	}
+	term.Section.Println("Build Summary")
+	if err := pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithBoxed().WithData(tableData).Render(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(results, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(outDir, buildSummaryFile), data, 0644); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("build failed: %s", strings.Join(failed, ", "))
+	}
+	term.Section.Println("Build Successfully")
+	return nil
+}
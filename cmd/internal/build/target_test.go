@@ -0,0 +1,115 @@
+package build
+
+import (
+	"bytes"
+	"debug/pe"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/framework"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/pkgs/winicon"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := ParseTargets("linux/amd64, Windows/AMD64,linux/amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 || targets[1] != (Target{OS: "windows", Arch: "amd64"}) {
+		t.Fatal(targets)
+	}
+	for _, value := range []string{"", "linux", "linux/mips", "plan9/amd64"} {
+		if _, err := ParseTargets(value); err == nil {
+			t.Fatal("expected error:", value)
+		}
+	}
+}
+
+func TestTargetTags(t *testing.T) {
+	c := &command.Config{Build: command.Build{Gtk: "gtk3"}}
+	for _, item := range []struct {
+		target Target
+		cef    string
+		tags   string
+	}{
+		{Target{"windows", "amd64"}, "118.7.1", "tempdll"},
+		{Target{"windows", "386"}, "109.1.18", "tempdll win7"},
+		{Target{"linux", "amd64"}, "118.7.1", "tempdll gtk3"},
+		{Target{"linux", "arm64"}, "106.1.1", "tempdll gtk2"},
+		{Target{"darwin", "arm64"}, "", "tempdll"},
+	} {
+		tags, err := targetTags(c, item.target, &framework.Framework{CEFVersion: item.cef})
+		if err != nil || tags != item.tags {
+			t.Fatal(item.target, tags, err)
+		}
+	}
+	if name := TargetOutputFilename(&project.Project{OutputFilename: "demo"}, Target{"windows", "amd64"}); name != "demo.exe" {
+		t.Fatal(name)
+	}
+	if name := TargetOutputFilename(&project.Project{OutputFilename: "demo.exe"}, Target{"linux", "amd64"}); name != "demo" {
+		t.Fatal(name)
+	}
+}
+
+func TestCgoEnv(t *testing.T) {
+	target := Target{"linux", "arm64"}
+	if target.IsHost() {
+		target.Arch = "amd64"
+	}
+	key := "CC_linux_" + target.Arch
+	t.Setenv(key, "")
+	t.Setenv("CC", "")
+	if env := cgoEnv(target); len(env) != 0 {
+		t.Fatal("unexpected cgo env without compiler", env)
+	}
+	t.Setenv("CC", "zig cc")
+	if env := cgoEnv(target); len(env) != 1 || env[0] != "CGO_ENABLED=1" {
+		t.Fatal("CC: unexpected cgo env", env)
+	}
+	t.Setenv(key, "aarch64-linux-gnu-gcc")
+	if env := cgoEnv(target); len(env) != 2 || env[0] != "CGO_ENABLED=1" || env[1] != "CC=aarch64-linux-gnu-gcc" {
+		t.Fatal(key+": unexpected cgo env", env)
+	}
+	if env := cgoEnv(Target{"windows", "amd64"}); len(env) != 0 {
+		t.Fatal("windows does not require cgo", env)
+	}
+}
+
+func TestBuildWindowsTarget(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module demo\n\ngo 1.18\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	img.Set(1, 1, color.White)
+	var pngData, icoData bytes.Buffer
+	png.Encode(&pngData, img)
+	if err := winicon.GenerateIcon(&pngData, &icoData, []int{32, 16}); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "icon.ico"), icoData.Bytes(), 0644)
+	proj := &project.Project{Name: "demo", ProjectPath: dir, OutputFilename: "demo", AssetsDir: "assets", Info: project.Info{Icon: filepath.Join(dir, "icon.ico"), ProductVersion: "1.0.0"}}
+	result := buildTarget(&command.Config{}, proj, Target{"windows", "amd64"}, filepath.Join(dir, "build", "windows-amd64"))
+	if result.Error != "" {
+		t.Fatal(result.Error)
+	}
+	f, err := pe.Open(result.Output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if f.Section(".rsrc") == nil {
+		t.Fatal("windows resources not linked")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.syso")); len(matches) != 0 {
+		t.Fatal("syso not removed", matches)
+	}
+}
@@ -0,0 +1,45 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 创建离线安装包
+
+package internal
+
+import (
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/install"
+)
+
+var CmdBundle = &command.Command{
+	UsageLine: "bundle -o [out] -v [version] -d [download] --os --arch --cef --golang --nsis --7za",
+	Short:     "Create an offline installation bundle",
+	Long: `
+	Download the version config, extract config and installation packages into one bundle file,
+	install on an offline machine: energy install --from-archive [bundle file]
+	-o Bundle file, default energy-bundle-[version]-[os]-[arch].tar.gz
+	-v Specifying a version number, Default latest
+	-d Download Source, 0:gitee or 1:github, Default empty
+	--os Specify bundle OS: [windows, linux, darwin], default current system: os
+	--arch Specify bundle ARCH: [386, amd64, arm64], Default current system: architecture
+	--cef Bundle CEF version: 109, 106, 87, default empty
+	--golang Include the Golang installation package
+	--nsis Include NSIS and NSIS7z installation packages, windows only
+	--7za Include the 7za installation package, windows only
+	.  Execute command
+`,
+}
+
+func init() {
+	CmdBundle.Run = runBundle
+}
+
+func runBundle(c *command.Config) error {
+	return install.Bundle(c)
+}
@@ -17,14 +17,30 @@ type Config struct {
 	Index     int
 	Wd        string
 	EnergyCfg EnergyConfig
-	Install   Install `command:"install" description:"install energy development dependency environment"`
-	Package   Package `command:"package" description:"energy application production and installation package"`
-	Version   Version `command:"version" description:"list all release version numbers of energy"`
-	Setenv    Setenv  `command:"setenv" description:"set ENERGY_ HOME framework environment"`
-	Env       Env     `command:"env" description:"display ENERGY_ HOME framework environment directory"`
-	Init      Init    `command:"init" description:"initialize the energy application project"`
-	Build     Build   `command:"build" description:"building an energy project"`
-	V         string  `command:"v" description:"energy cli version"`
+	Result    any       // 命令结果, --output json 时输出
+	Install   Install   `command:"install" description:"install energy development dependency environment"`
+	Package   Package   `command:"package" description:"energy application production and installation package"`
+	Version   Version   `command:"version" description:"list all release version numbers of energy"`
+	Setenv    Setenv    `command:"setenv" description:"set ENERGY_ HOME framework environment"`
+	Env       Env       `command:"env" description:"display ENERGY_ HOME framework environment directory"`
+	Init      Init      `command:"init" description:"initialize the energy application project"`
+	Build     Build     `command:"build" description:"building an energy project"`
+	Bundle    Bundle    `command:"bundle" description:"create an offline installation bundle for energy install --from-archive"`
+	List      List      `command:"list" description:"list installed energy frameworks"`
+	Use       Use       `command:"use" description:"use an installed energy framework globally or in a project"`
+	Uninstall Uninstall `command:"uninstall" description:"uninstall an installed energy framework"`
+	Doctor    Doctor    `command:"doctor" description:"diagnose the energy development environment"`
+	Configure Configure `command:"config" description:"validate, migrate or print the json schema of the project energy.json"`
+	Dev       Dev       `command:"dev" description:"run the application with the frontend dev server, rebuild and restart on changes"`
+	Upgrade   Upgrade   `command:"upgrade" description:"upgrade the energy cli to the latest version"`
+	V         string    `command:"v" description:"energy cli version"`
+
+	// 全局参数, 所有命令可用, 用于 CI 等非交互环境
+	Yes            bool   `short:"y" long:"yes" description:"Answer yes to all prompts and use the default options"`
+	NonInteractive bool   `long:"non-interactive" description:"Do not prompt, same as --yes"`
+	Quiet          bool   `short:"q" long:"quiet" description:"Only output errors, to stderr"`
+	Output         string `long:"output" description:"Output format, json: only the json result is written to stdout" choice:"text" choice:"json" default:"text"`
+	Dir            string `long:"dir" description:"Workspace root, build and package run all energy.json projects under it, see energy.workspace.json"`
 }
 
 type Command struct {
@@ -33,25 +49,39 @@ type Command struct {
 }
 
 type Install struct {
-	Path     string `short:"p" long:"path" description:"Installation directory Default current directory"`
-	Version  string `short:"v" long:"version" description:"Specifying a version number"`
-	Name     string `short:"n" long:"name" description:"Name of the framework directory after installation" default:"EnergyFramework"`
-	Download string `short:"d" long:"download" description:"Download Source, 0:gitee or 1:github, Default empty" default:""`
-	OS       OS     `long:"os" description:"Specify install OS: [windows, linux, darwin], default current system: os"`
-	Arch     Arch   `long:"arch" description:"Specify install ARCH: [386, amd64, arm64], Default current system: architecture"`
-	CEF      string `long:"cef" description:"Install system supports CEF version, provide 4 options, default empty. options: 109(support windows7), 106(support linux gtk2), 87(support flash)" default:""`
-	IGolang  bool   // 是否安装Golang
-	ICEF     bool   // 是否安装CEF
-	INSIS    bool   // 是否安装nsis
-	IUPX     bool   // 是否安装upx
-	I7za     bool   // 是否安装7za
-	IsSame   bool   // 安装的OS和Arch是否为当前系统架构, 默认当前系统架构
+	Path            string            `short:"p" long:"path" description:"Installation directory Default current directory"`
+	Version         string            `short:"v" long:"version" description:"Specifying a version number"`
+	Name            string            `short:"n" long:"name" description:"Name of the framework directory after installation" default:"EnergyFramework"`
+	Download        string            `short:"d" long:"download" description:"Download Source, 0:gitee or 1:github, Default empty" default:""`
+	OS              OS                `long:"os" description:"Specify install OS: [windows, linux, darwin], default current system: os"`
+	Arch            Arch              `long:"arch" description:"Specify install ARCH: [386, amd64, arm64], Default current system: architecture"`
+	CEF             string            `long:"cef" description:"Install system supports CEF version, provide 4 options, default empty. options: 109(support windows7), 106(support linux gtk2), 87(support flash)" default:""`
+	Mirror          string            `long:"mirror" description:"Offline install from a local directory or http(s) mirror, containing edv.json, extract.json and installation packages"`
+	FromArchive     string            `long:"from-archive" description:"Offline install from a bundle file created by the energy bundle command"`
+	VerifySignature bool              `long:"verify-signature" description:"Require a valid ed25519 signature for each installation package"`
+	Retries         int               `long:"retries" description:"Download retries on failure, default 3, or retries in energy.json"`
+	Proxy           string            `long:"proxy" description:"Download proxy, default proxy in energy.json or HTTP_PROXY, HTTPS_PROXY environment"`
+	IGolang         bool              // 是否安装Golang
+	ICEF            bool              // 是否安装CEF
+	INSIS           bool              // 是否安装nsis
+	IUPX            bool              // 是否安装upx
+	I7za            bool              // 是否安装7za
+	IsSame          bool              // 安装的OS和Arch是否为当前系统架构, 默认当前系统架构
+	Checksums       map[string]string // 安装包 sha256, 文件名 => 摘要
+	Signatures      map[string]string // 安装包 ed25519 签名, 文件名 => base64
+	Err             error             // 第一个安装错误, 决定退出码
 }
 
 type Package struct {
 	Path     string `short:"p" long:"path" description:"Project path, default current path. Can be configured in energy.json" default:""`
 	Clean    bool   `short:"c" long:"clean" description:"Clear configuration and regenerate the default configuration"`
 	Pkgbuild bool   `long:"pkg" description:"Using pkgbuild to create pkg development installation packages"`
+	Format   string `long:"format" description:"Linux package formats, comma separated: deb, rpm, appimage, tar.gz. Can be configured in energy.json"`
+	Dpkg     bool   `long:"dpkg" description:"Using the dpkg command to create deb packages, default built-in writer"`
+	DryRun   bool   `long:"dry-run" description:"Print the files, sizes and destination paths to be packaged without creating the installation package"`
+	Target   string `long:"target" description:"Package targets, os/arch separated by comma. windows targets can be packaged on linux and macos with makensis"`
+	Projects string `long:"projects" description:"Workspace projects, names or relative paths separated by comma, default all"`
+	Jobs     int    `long:"jobs" description:"Number of workspace projects run in parallel, default number of CPUs"`
 }
 
 type Env struct {
@@ -66,27 +96,96 @@ type Version struct {
 }
 
 type Init struct {
-	Name    string `short:"n" long:"name" description:"Initialized project name"`
-	ResLoad string `short:"r" long:"resload" description:"Resource loading method, 1: HTTP, 2: Local Load, default 1 HTTP"`
-	IGo     bool
-	INSIS   bool
-	IUPX    bool
-	IEnv    bool
-	INpm    bool
+	Name     string            `short:"n" long:"name" description:"Initialized project name"`
+	ResLoad  string            `short:"r" long:"resload" description:"Resource loading method, 1: HTTP, 2: Local Load, default 1 HTTP"`
+	Template string            `short:"t" long:"template" description:"Project template: plain, vue, react, svelte, a template in ~/.energy/templates, a template directory or a .tar.gz file or URL"`
+	Vars     map[string]string `long:"var" description:"Template variable name:value, can be repeated"`
+	NoHooks  bool              `long:"no-hooks" description:"Do not run the template commands after generation, e.g. npm install"`
+	List     bool              `short:"l" long:"list" description:"List the available project templates"`
+	IGo      bool
+	INSIS    bool
+	IUPX     bool
+	IEnv     bool
+	INpm     bool
 }
 
 type Build struct {
-	Path    string `short:"p" long:"path" description:"Project path, default current path. Can be configured in energy.json" default:""`
-	Upx     bool   `short:"u" long:"upx" description:"Set this parameter and install upx. Use upx to compress the execution file."`
-	UpxFlag string `long:"upxFlag" description:"Upx command line parameters" default:""`
-	Gtk     string `long:"gtk" description:"Compile on Linux, enable TempDll. gtk2 or gtk3" default:"gtk3"`
-	TempDll bool   `short:"d" long:"dll" description:"Enable built-in liblcl build"`
+	Path     string `short:"p" long:"path" description:"Project path, default current path. Can be configured in energy.json" default:""`
+	Upx      bool   `short:"u" long:"upx" description:"Set this parameter and install upx. Use upx to compress the execution file."`
+	UpxFlag  string `long:"upxFlag" description:"Upx command line parameters" default:""`
+	Gtk      string `long:"gtk" description:"Compile on Linux, enable TempDll. gtk2 or gtk3" default:"gtk3"`
+	TempDll  bool   `short:"d" long:"dll" description:"Enable built-in liblcl build"`
+	Target   string `long:"target" description:"Cross compile targets, os/arch separated by comma: linux/amd64,linux/arm64,windows/amd64"`
+	Projects string `long:"projects" description:"Workspace projects, names or relative paths separated by comma, default all"`
+	Jobs     int    `long:"jobs" description:"Number of workspace projects run in parallel, default number of CPUs"`
+}
+
+type Bundle struct {
+	Out      string `short:"o" long:"out" description:"Bundle file, default energy-bundle-[version]-[os]-[arch].tar.gz"`
+	Version  string `short:"v" long:"version" description:"Specifying a version number, Default latest"`
+	Download string `short:"d" long:"download" description:"Download Source, 0:gitee or 1:github, Default empty" default:""`
+	OS       OS     `long:"os" description:"Specify bundle OS: [windows, linux, darwin], default current system: os"`
+	Arch     Arch   `long:"arch" description:"Specify bundle ARCH: [386, amd64, arm64], Default current system: architecture"`
+	CEF      string `long:"cef" description:"Bundle CEF version, options: 109(support windows7), 106(support linux gtk2), 87(support flash)" default:""`
+	Golang   bool   `long:"golang" description:"Include the Golang installation package"`
+	NSIS     bool   `long:"nsis" description:"Include NSIS and NSIS7z installation packages, windows only"`
+	Z7za     bool   `long:"7za" description:"Include the 7za installation package, windows only"`
+}
+
+type List struct {
+	Path string `short:"p" long:"path" description:"Project path, default current path. Marks the framework used by the project"`
+}
+
+type Use struct {
+	Path string `short:"p" long:"path" description:"Project path, set frameworkVersion in the energy.json of the project instead of the global framework"`
+	Args struct {
+		Framework string `positional-arg-name:"framework" description:"Framework name, energy version, CEF version or latest"`
+	} `positional-args:"yes"`
+}
+
+type Uninstall struct {
+	Args struct {
+		Framework string `positional-arg-name:"framework" description:"Framework name, energy version or CEF version"`
+	} `positional-args:"yes"`
+}
+
+type Doctor struct {
+	Path string `short:"p" long:"path" description:"Project path, default current path. Checks the framework used by the project"`
+	JSON bool   `long:"json" description:"Output the report as json, same as --output json"`
+}
+
+type Configure struct {
+	Path   string `short:"p" long:"path" description:"Project path, default current path"`
+	DryRun bool   `long:"dry-run" description:"Print the migrated energy.json without writing it"`
+	Args   struct {
+		Action string `positional-arg-name:"action" description:"validate, migrate or schema"`
+	} `positional-args:"yes"`
+}
+
+type Dev struct {
+	Path       string `short:"p" long:"path" description:"Project path, default current path"`
+	Frontend   string `long:"frontend" description:"Frontend directory, default frontend if frontend/package.json exists. Can be configured in energy.json"`
+	Command    string `long:"command" description:"Frontend dev server command, default npm run dev. Can be configured in energy.json"`
+	URL        string `long:"url" description:"Frontend dev server URL, default http://localhost:5173. Can be configured in energy.json"`
+	NoFrontend bool   `long:"no-frontend" description:"Do not start the frontend dev server, only rebuild and restart the application"`
+}
+
+type Upgrade struct {
+	Check  bool   `long:"check" description:"Only compare the current and latest version, do not upgrade"`
+	Force  bool   `short:"f" long:"force" description:"Upgrade even if the current version is the latest"`
+	Mirror string `long:"mirror" description:"Upgrade from a local directory or http(s) mirror, containing upgrade.json and the energy cli files"`
+	Proxy  string `long:"proxy" description:"Download proxy, default proxy in energy.json or HTTP_PROXY, HTTPS_PROXY environment"`
+	// 没有公钥时只校验 sha256
+	ChecksumOnly bool `long:"checksum-only" description:"Without a public key, verify only the sha256, upgrade.json and the energy cli must be downloaded over https or from a local mirror"`
 }
 
 type EnergyConfig struct {
 	Framework string         `json:"framework"`
 	Version   string         `json:"version"`
 	Source    DownloadSource `json:"source"`
+	PublicKey string         `json:"publicKey,omitempty"` // 安装包签名验证 ed25519 公钥, base64
+	Proxy     string         `json:"proxy,omitempty"`     // 下载代理, http://host:port, 空时使用环境变量
+	Retries   int            `json:"retries,omitempty"`   // 下载失败重试次数
 }
 
 type DownloadSource struct {
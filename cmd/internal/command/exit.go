@@ -0,0 +1,75 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package command
+
+import "errors"
+
+// 退出码, 按失败类型区分, 已发布的值保持不变
+const (
+	ExitOK          = 0   // 成功
+	ExitFailure     = 1   // 其它错误
+	ExitUsage       = 2   // 命令行参数错误
+	ExitEnv         = 3   // 开发环境错误, 缺少 Go, CEF 框架或工具, energy doctor 检查失败
+	ExitConfig      = 4   // 项目配置 energy.json 错误
+	ExitNetwork     = 5   // 网络请求或下载失败
+	ExitVerify      = 6   // 安装包 sha256 或签名校验失败
+	ExitBuild       = 7   // 编译失败
+	ExitPackage     = 8   // 安装包制作失败
+	ExitInteractive = 9   // 需要用户输入, 但当前为非交互模式
+	ExitInterrupt   = 130 // 用户中断 Ctrl+C
+)
+
+// Error 带退出码的错误
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (m *Error) Error() string {
+	return m.Err.Error()
+}
+
+func (m *Error) Unwrap() error {
+	return m.Err
+}
+
+// WithCode 设置错误的退出码, 已设置退出码的错误保持不变
+func WithCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// ExitCode 错误的退出码, 未设置时为 ExitFailure
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ExitFailure
+}
+
+// JSONResult --output json 输出
+type JSONResult struct {
+	Command string `json:"command"`
+	Success bool   `json:"success"`
+	Code    int    `json:"code"` // 退出码
+	Error   string `json:"error,omitempty"`
+	Result  any    `json:"result,omitempty"` // 命令结果, 失败时可能包含部分结果
+}
@@ -0,0 +1,27 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	if code := ExitCode(nil); code != ExitOK {
+		t.Fatal("nil", code)
+	}
+	if code := ExitCode(errors.New("failed")); code != ExitFailure {
+		t.Fatal("error", code)
+	}
+	err := WithCode(ExitNetwork, errors.New("timeout"))
+	if code := ExitCode(fmt.Errorf("install: %w", err)); code != ExitNetwork {
+		t.Fatal("wrapped", code)
+	}
+	// 已设置的退出码不变
+	if code := ExitCode(WithCode(ExitFailure, err)); code != ExitNetwork {
+		t.Fatal("keep", code)
+	}
+	if WithCode(ExitBuild, nil) != nil {
+		t.Fatal("nil error")
+	}
+}
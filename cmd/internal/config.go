@@ -0,0 +1,117 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package internal
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"os"
+	"path/filepath"
+)
+
+var CmdConfig = &command.Command{
+	UsageLine: "config [validate|migrate|schema] -p [path]",
+	Short:     "Validate, migrate or print the json schema of energy.json",
+	Long: `
+	Project configuration energy.json
+	validate Check energy.json, prints errors and warnings with line and column
+	migrate  Upgrade energy.json to the current configuration version, the original is saved as energy.json.bak
+	schema   Print the JSON Schema of energy.json
+	-p Project path, default current path
+	--dry-run Print the migrated energy.json without writing it
+	.  Execute command
+
+	energy.json
+	String values support environment variables: ${NAME} or ${NAME:-default}, $${ outputs ${
+	windows, linux, darwin: configuration merged on the system, e.g. "linux": {"info": {"icon": "resources/icon.png"}}
+`,
+}
+
+func init() {
+	CmdConfig.Run = runConfig
+}
+
+func runConfig(c *command.Config) error {
+	m := &c.Configure
+	if m.Path == "" {
+		m.Path = c.Wd
+	}
+	file := filepath.Join(m.Path, consts.EnergyProjectConfig)
+	switch m.Args.Action {
+	case "schema":
+		_, err := os.Stdout.Write(project.Schema())
+		return err
+	case "", "validate":
+		return validateConfig(file)
+	case "migrate":
+		return migrateConfig(file, m.DryRun)
+	}
+	return command.WithCode(command.ExitUsage, fmt.Errorf("unknown action %s, use validate, migrate or schema", m.Args.Action))
+}
+
+func validateConfig(file string) error {
+	issues, err := project.Validate(file)
+	if err != nil {
+		return command.WithCode(command.ExitConfig, err)
+	}
+	var errs int
+	for _, issue := range issues {
+		if issue.Warning {
+			term.Logger.Warn(issue.Error())
+		} else {
+			term.Logger.Error(issue.Error())
+			errs++
+		}
+	}
+	if errs > 0 {
+		return command.WithCode(command.ExitConfig, fmt.Errorf("%s: %d errors", file, errs))
+	}
+	term.Logger.Info("Config OK", term.Logger.Args("file", file, "warnings", len(issues)))
+	return nil
+}
+
+func migrateConfig(file string, dryRun bool) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	migrated, changes, err := project.Migrate(file, data)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		term.Logger.Info("Config is up to date", term.Logger.Args("file", file, "configVersion", project.ConfigVersion))
+		return nil
+	}
+	for _, change := range changes {
+		term.Section.Println("  ", change)
+	}
+	if dryRun {
+		_, err = os.Stdout.Write(migrated)
+		return err
+	}
+	backup := file + ".bak"
+	if tools.IsExist(backup) {
+		return fmt.Errorf("backup %s exists, remove it and try again", backup)
+	}
+	if err = os.WriteFile(backup, data, 0666); err != nil {
+		return err
+	}
+	if err = os.WriteFile(file, migrated, 0666); err != nil {
+		return err
+	}
+	term.Logger.Info("Config migrated", term.Logger.Args("file", file, "backup", backup))
+	return nil
+}
@@ -67,11 +67,19 @@ const (
 )
 
 const (
-	CefKey              = "cef"
-	LiblclKey           = "liblcl"
-	FrameworkCache      = "EnergyFrameworkDownloadCache"
-	EnergyHomeKey       = "ENERGY_HOME"
-	EnergyProjectConfig = "energy.json"
+	// SignaturePublicKey 安装包签名验证 ed25519 公钥, base64
+	//  可在 ~/.energy/energy.json publicKey 配置
+	SignaturePublicKey = ""
+)
+
+const (
+	CefKey                = "cef"
+	LiblclKey             = "liblcl"
+	FrameworkCache        = "EnergyFrameworkDownloadCache"
+	EnergyHomeKey         = "ENERGY_HOME"
+	EnergyResultFileKey   = "ENERGY_RESULT_FILE" // 命令结果写入的文件, 格式同 --output json, 工作区子进程使用
+	EnergyProjectConfig   = "energy.json"
+	EnergyWorkspaceConfig = "energy.workspace.json" // monorepo 工作区, 多个项目共享默认配置
 )
 
 const (
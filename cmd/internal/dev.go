@@ -0,0 +1,44 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package internal
+
+import (
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/dev"
+)
+
+var CmdDev = &command.Command{
+	UsageLine: "dev -p [path] --frontend [dir] --command [command] --url [url] --no-frontend",
+	Short:     "Run the application in development mode",
+	Long: `
+	Run the frontend dev server and the application, rebuild and restart the application when .go files change
+	-p Project path, default current path
+	--frontend Frontend directory, default frontend if frontend/package.json exists
+	--command Frontend dev server command run in the frontend directory, default npm run dev
+	--url Frontend dev server URL, default http://localhost:5173
+	  the application is built with -tags="energy_dev" and started with ENERGY_DEV_SERVER=[url]
+	  LocalLoadConfig loads the home page from it and XHRProxy forwards to it, release builds ignore ENERGY_DEV_SERVER
+	--no-frontend Do not start the frontend dev server
+	.  Execute command
+
+	energy.json
+	"dev": {"frontend": "frontend", "command": "npm run dev", "url": "http://localhost:5173", "timeout": 60, "exclude": [], "args": []}
+	Output: [frontend] frontend dev server, [build] go build, [app] application. Ctrl+C to stop
+`,
+}
+
+func init() {
+	CmdDev.Run = runDev
+}
+
+func runDev(c *command.Config) error {
+	return dev.Dev(c)
+}
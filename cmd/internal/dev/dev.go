@@ -0,0 +1,318 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 开发模式 energy dev
+//  启动前端开发服务, 等待端口可用
+//  构建 (-tags="energy_dev") 并启动应用, 环境变量 ENERGY_DEV_SERVER 设置为前端开发服务地址
+//  LocalLoadConfig 首页加载该地址, XHRProxy 代理到该地址, 发布构建不读取该环境变量
+//  .go 文件修改后重新构建, 构建成功后重启应用, 构建失败时保持当前应用运行
+//  前端, 构建和应用的输出添加前缀输出到终端
+
+package dev
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"github.com/pterm/pterm"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultFrontend = "frontend"
+	defaultCommand  = "npm run dev"
+	defaultURL      = "http://localhost:5173"
+	defaultTimeout  = 60
+	devServerKey    = "ENERGY_DEV_SERVER" // 和 consts.ENERGY_DEV_SERVER_KEY 一致
+	devBuildTag     = "energy_dev"        // 开发构建, 只有开发构建的应用读取 ENERGY_DEV_SERVER
+	energyHomeKey   = "ENERGY_HOME"
+	pollInterval    = 500 * time.Millisecond
+	debounce        = 300 * time.Millisecond // 等待连续的修改完成, 例如编辑器保存多个文件
+	stopTimeout     = 5 * time.Second
+)
+
+var (
+	frontendPrefix = pterm.FgCyan.Sprint("[frontend]")
+	buildPrefix    = pterm.FgYellow.Sprint("[build]")
+	appPrefix      = pterm.FgGreen.Sprint("[app]")
+)
+
+// 子进程
+type process struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+}
+
+// 启动子进程, 输出写入 out
+func start(cmd *exec.Cmd, out *prefixWriter) (*process, error) {
+	cmd.Stdout, cmd.Stderr = out, out
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &process{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		p.err = cmd.Wait()
+		out.Flush()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+func (m *process) exited() bool {
+	select {
+	case <-m.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// 结束进程, 超时后强制结束
+func (m *process) stop() {
+	if m == nil || m.exited() {
+		return
+	}
+	killProcess(m.cmd, false)
+	select {
+	case <-m.done:
+	case <-time.After(stopTimeout):
+		killProcess(m.cmd, true)
+		<-m.done
+	}
+}
+
+// 应用, 构建到 build/dev/next 成功后替换 build/dev 中的执行文件并重启
+//  windows 不能覆盖运行中的执行文件
+type app struct {
+	proj   *project.Project
+	output string
+	next   string
+	args   []string
+	env    []string
+	out    *output
+	proc   *process
+}
+
+func (m *app) build() error {
+	// 开发构建, 应用读取环境变量 ENERGY_DEV_SERVER
+	cmd := exec.Command("go", "build", "-tags", devBuildTag, "-o", m.next)
+	cmd.Dir = m.proj.ProjectPath
+	w := m.out.writer(buildPrefix)
+	cmd.Stdout, cmd.Stderr = w, w
+	err := cmd.Run()
+	w.Flush()
+	return err
+}
+
+// 重新构建并重启应用
+func (m *app) reload() {
+	begin := time.Now()
+	term.Logger.Info("Building", term.Logger.Args("output", m.output))
+	if err := m.build(); err != nil {
+		term.Logger.Error("Build failed, waiting for changes", term.Logger.Args("error", err.Error()))
+		return
+	}
+	m.stop()
+	if err := os.Rename(m.next, m.output); err != nil {
+		term.Logger.Error(err.Error())
+		return
+	}
+	cmd := exec.Command(m.output, m.args...)
+	cmd.Dir = m.proj.ProjectPath
+	cmd.Env = m.env
+	proc, err := start(cmd, m.out.writer(appPrefix))
+	if err != nil {
+		term.Logger.Error(err.Error())
+		return
+	}
+	m.proc = proc
+	term.Logger.Info("Application started", term.Logger.Args("pid", proc.cmd.Process.Pid, "time", time.Since(begin).Round(time.Millisecond).String()))
+}
+
+func (m *app) stop() {
+	m.proc.stop()
+	m.proc = nil
+}
+
+// 应用退出的通知, 未运行时为 nil
+func (m *app) done() <-chan struct{} {
+	if m.proc == nil {
+		return nil
+	}
+	return m.proc.done
+}
+
+// 合并命令行参数和 energy.json 配置, 设置默认值
+func options(c *command.Config, proj *project.Project) project.Dev {
+	m := proj.Dev
+	if c.Dev.Frontend != "" {
+		m.Frontend = c.Dev.Frontend
+	}
+	if c.Dev.Command != "" {
+		m.Command = c.Dev.Command
+	}
+	if c.Dev.URL != "" {
+		m.URL = c.Dev.URL
+	}
+	if m.Frontend == "" && tools.IsExist(filepath.Join(proj.ProjectPath, defaultFrontend, "package.json")) {
+		m.Frontend = defaultFrontend
+	}
+	if c.Dev.NoFrontend {
+		m.Command = ""
+		return m
+	}
+	if m.Frontend != "" {
+		if m.Command == "" {
+			m.Command = defaultCommand
+		}
+		if m.URL == "" {
+			m.URL = defaultURL
+		}
+	}
+	if m.Timeout <= 0 {
+		m.Timeout = defaultTimeout
+	}
+	return m
+}
+
+// 等待前端开发服务端口可用
+func waitServer(rawURL string, timeout time.Duration, frontend *process, sig chan os.Signal) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "https" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+	term.Logger.Info("Waiting for frontend dev server", term.Logger.Args("url", rawURL))
+	deadline := time.After(timeout)
+	for {
+		if conn, err := net.DialTimeout("tcp", host, time.Second); err == nil {
+			conn.Close()
+			return nil
+		}
+		select {
+		case <-frontend.done:
+			return fmt.Errorf("frontend dev server exited: %v", frontend.err)
+		case <-deadline:
+			return fmt.Errorf("frontend dev server %s is not available after %s", rawURL, timeout)
+		case <-sig:
+			return fmt.Errorf("interrupted")
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Dev 运行开发模式, Ctrl+C 结束
+func Dev(c *command.Config) error {
+	proj, err := project.NewProject(c.Dev.Path)
+	if err != nil {
+		return err
+	}
+	opts := options(c, proj)
+	// 前端目录和构建输出不监听
+	exclude := append([]string{"/build/"}, opts.Exclude...)
+	if opts.Frontend != "" {
+		exclude = append(exclude, "/"+filepath.ToSlash(opts.Frontend)+"/")
+	}
+	matcher, err := project.NewMatcher(exclude)
+	if err != nil {
+		return err
+	}
+	out := &output{w: os.Stdout}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	var frontendDone <-chan struct{}
+	if opts.Command != "" {
+		cmd := shellCommand(opts.Command)
+		cmd.Dir = filepath.Join(proj.ProjectPath, opts.Frontend)
+		term.Logger.Info("Start frontend dev server", term.Logger.Args("command", opts.Command, "dir", cmd.Dir))
+		frontend, err := start(cmd, out.writer(frontendPrefix))
+		if err != nil {
+			return err
+		}
+		defer frontend.stop()
+		if opts.URL != "" {
+			if err = waitServer(opts.URL, time.Duration(opts.Timeout)*time.Second, frontend, sig); err != nil {
+				return err
+			}
+		}
+		frontendDone = frontend.done
+	}
+
+	dir := filepath.Join(proj.ProjectPath, "build", "dev")
+	if err = os.MkdirAll(filepath.Join(dir, "next"), os.ModePerm); err != nil {
+		return err
+	}
+	a := &app{
+		proj:   proj,
+		output: filepath.Join(dir, proj.OutputFilename),
+		next:   filepath.Join(dir, "next", proj.OutputFilename),
+		args:   opts.Args,
+		env:    append(os.Environ(), energyHomeKey+"="+proj.FrameworkPath),
+		out:    out,
+	}
+	if opts.URL != "" {
+		a.env = append(a.env, devServerKey+"="+opts.URL)
+	}
+	if runtime.GOOS == "darwin" {
+		// 开发环境需要参数 energy_env=dev
+		a.args = append(a.args, "energy_env=dev")
+	}
+	defer a.stop()
+	w := newWatcher(proj.ProjectPath, matcher)
+	a.reload()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sig:
+			term.Logger.Info("Stopping")
+			return nil
+		case <-frontendDone:
+			return fmt.Errorf("frontend dev server exited")
+		case <-a.done():
+			var exit any = 0
+			if a.proc.err != nil {
+				exit = a.proc.err.Error()
+			}
+			a.proc = nil
+			term.Logger.Info("Application exited, waiting for changes", term.Logger.Args("exit", exit))
+		case <-ticker.C:
+			changed := w.changed()
+			if len(changed) == 0 {
+				continue
+			}
+			time.Sleep(debounce)
+			changed = append(changed, w.changed()...)
+			term.Logger.Info("Files changed", term.Logger.Args("file", changed[0], "count", len(changed)))
+			a.reload()
+		}
+	}
+}
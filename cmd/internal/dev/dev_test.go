@@ -0,0 +1,73 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package dev
+
+import (
+	"bytes"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out := &output{w: buf}
+	a, b := out.writer("[a]"), out.writer("[b]")
+	a.Write([]byte("one\r\ntw"))
+	b.Write([]byte("three\n"))
+	a.Write([]byte("o\nfour"))
+	a.Flush()
+	want := "[a] one\n[b] three\n[a] two\n[a] four\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	write := func(name string) {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), os.ModePerm)
+		if err := os.WriteFile(file, []byte(name), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go")
+	write("go.mod")
+	write("frontend/vite.config.go")
+	matcher, err := project.NewMatcher([]string{"/build/", "/frontend/", "*_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newWatcher(root, matcher)
+	if len(w.files) != 2 {
+		t.Errorf("files %v", w.files)
+	}
+	write("app/app.go")
+	write("app/app_test.go")
+	write("build/dev/gen.go")
+	write("frontend/src/gen.go")
+	write("node_modules/x/x.go")
+	write("README.md")
+	later := time.Now().Add(time.Second)
+	os.Chtimes(filepath.Join(root, "main.go"), later, later)
+	os.Remove(filepath.Join(root, "go.mod"))
+	want := []string{"app/app.go", "go.mod", "main.go"}
+	if got := w.changed(); !reflect.DeepEqual(got, want) {
+		t.Errorf("changed %v, want %v", got, want)
+	}
+	if got := w.changed(); len(got) != 0 {
+		t.Errorf("changed again %v", got)
+	}
+}
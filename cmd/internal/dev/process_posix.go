@@ -0,0 +1,38 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+//go:build !windows
+// +build !windows
+
+package dev
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// 子进程使用新的进程组, 结束时同时结束它启动的进程, 例如 npm 启动的 node
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// 结束进程组, force 为 false 时发送 SIGTERM
+func killProcess(cmd *exec.Cmd, force bool) {
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	syscall.Kill(-cmd.Process.Pid, sig)
+}
+
+// 通过 shell 执行命令
+func shellCommand(command string) *exec.Cmd {
+	return exec.Command("sh", "-c", command)
+}
@@ -0,0 +1,38 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+//go:build windows
+// +build windows
+
+package dev
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// 子进程使用新的进程组, 不接收终端的 Ctrl+C
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// 结束进程树, 例如 npm 启动的 node
+//  控制台进程不能接收关闭消息, 总是强制结束
+func killProcess(cmd *exec.Cmd, force bool) {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		cmd.Process.Kill()
+	}
+}
+
+// 通过 shell 执行命令
+func shellCommand(command string) *exec.Cmd {
+	return exec.Command("cmd", "/C", command)
+}
@@ -0,0 +1,91 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package dev
+
+import (
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 默认不监听的目录
+var skipDirs = map[string]bool{
+	".git":         true,
+	".idea":        true,
+	".vscode":      true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// watcher 轮询项目中的 .go 文件和 go.mod, go.sum 的修改时间
+type watcher struct {
+	root    string
+	exclude *project.Matcher
+	files   map[string]time.Time
+}
+
+func newWatcher(root string, exclude *project.Matcher) *watcher {
+	m := &watcher{root: root, exclude: exclude}
+	m.files = m.scan()
+	return m
+}
+
+// 是否监听的文件
+func isWatched(name string) bool {
+	return strings.HasSuffix(name, ".go") || name == "go.mod" || name == "go.sum"
+}
+
+func (m *watcher) scan() map[string]time.Time {
+	files := make(map[string]time.Time)
+	filepath.WalkDir(m.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == m.root {
+			return nil
+		}
+		rel, _ := filepath.Rel(m.root, path)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if skipDirs[d.Name()] || m.exclude.SkipDir(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isWatched(d.Name()) || m.exclude.Match(rel, false) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[rel] = info.ModTime()
+		}
+		return nil
+	})
+	return files
+}
+
+// changed 返回上次调用后修改, 添加或删除的文件
+func (m *watcher) changed() []string {
+	files := m.scan()
+	var result []string
+	for name, modTime := range files {
+		if old, ok := m.files[name]; !ok || !old.Equal(modTime) {
+			result = append(result, name)
+		}
+	}
+	for name := range m.files {
+		if _, ok := files[name]; !ok {
+			result = append(result, name)
+		}
+	}
+	m.files = files
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,36 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package internal
+
+import (
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/doctor"
+)
+
+var CmdDoctor = &command.Command{
+	UsageLine: "doctor -p [path] --json",
+	Short:     "Diagnose the energy development environment",
+	Long: `
+	Check Golang, ENERGY_HOME, CEF framework files, liblcl and CEF version compatibility,
+	GTK and shared libraries on Linux, prints a pass/warn/fail report with fix hints
+	-p Project path, default current path. Checks the framework used by the project
+	--json Output the report as json, same as --output json
+	.  Execute command
+`,
+}
+
+func init() {
+	CmdDoctor.Run = runDoctor
+}
+
+func runDoctor(c *command.Config) error {
+	return doctor.Doctor(c)
+}
@@ -0,0 +1,256 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/framework"
+	"github.com/energye/energy/v2/cmd/internal/install"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"github.com/pterm/pterm"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// 最低 Go 版本, 同 go.mod
+const minGoVersion = "1.18"
+
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+// Check 单项检查结果
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"` // 修复提示
+}
+
+// Report 检查报告
+type Report struct {
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+	Framework string   `json:"framework"` // 框架目录
+	Checks    []*Check `json:"checks"`
+	Pass      int      `json:"pass"`
+	Warn      int      `json:"warn"`
+	Fail      int      `json:"fail"`
+}
+
+func (m *Report) add(name string, status Status, message, hint string) {
+	m.Checks = append(m.Checks, &Check{Name: name, Status: status, Message: message, Hint: hint})
+	switch status {
+	case Pass:
+		m.Pass++
+	case Warn:
+		m.Warn++
+	case Fail:
+		m.Fail++
+	}
+}
+
+// Doctor 检查开发环境并输出报告, 有失败项时返回 error
+func Doctor(c *command.Config) error {
+	report := Diagnose(c)
+	c.Result = report
+	if err := printReport(report); err != nil {
+		return err
+	}
+	if report.Fail > 0 {
+		return command.WithCode(command.ExitEnv, fmt.Errorf("%d check(s) failed", report.Fail))
+	}
+	return nil
+}
+
+// Diagnose 执行所有检查
+func Diagnose(c *command.Config) *Report {
+	report := &Report{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	checkGolang(report)
+	checkEnergyHome(report)
+	frameworkPath, record := checkFramework(c, report)
+	if frameworkPath != "" {
+		report.Framework = frameworkPath
+		checkFrameworkFiles(report, frameworkPath)
+		checkCompatibility(report, frameworkPath, record)
+		if consts.IsLinux {
+			checkGTK(report, frameworkPath)
+			checkSharedLibraries(report, frameworkPath)
+		}
+	}
+	checkTools(c, report)
+	return report
+}
+
+func printReport(report *Report) error {
+	tableData := pterm.TableData{
+		{"Status", "Check", "Message"},
+	}
+	for _, check := range report.Checks {
+		var status string
+		switch check.Status {
+		case Pass:
+			status = pterm.Green("PASS")
+		case Warn:
+			status = pterm.Yellow("WARN")
+		case Fail:
+			status = pterm.Red("FAIL")
+		}
+		tableData = append(tableData, []string{status, check.Name, check.Message})
+	}
+	term.Section.Println("Energy Development Environment", report.OS, report.Arch)
+	if err := pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithBoxed().WithData(tableData).Render(); err != nil {
+		return err
+	}
+	for _, check := range report.Checks {
+		if check.Status != Pass && check.Hint != "" {
+			term.Section.Println(check.Name+":", check.Hint)
+		}
+	}
+	term.Section.Println(fmt.Sprintf("pass: %d, warn: %d, fail: %d", report.Pass, report.Warn, report.Fail))
+	return nil
+}
+
+// go 命令和版本
+func checkGolang(report *Report) {
+	const name = "Golang"
+	if !tools.CommandExists("go") {
+		report.add(name, Fail, "go command not found", "energy install . and select Golang, or add GOROOT/bin to PATH")
+		return
+	}
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		report.add(name, Warn, "go version unavailable: "+err.Error(), "")
+		return
+	}
+	version := strings.TrimPrefix(strings.TrimSpace(string(out)), "go")
+	if !versionAtLeast(version, minGoVersion) {
+		report.add(name, Warn, "go "+version+", go "+minGoVersion+" or later is required", "energy install . and select Golang")
+		return
+	}
+	report.add(name, Pass, "go "+version, "")
+}
+
+// ENERGY_HOME 环境变量
+func checkEnergyHome(report *Report) {
+	home := os.Getenv(consts.EnergyHomeKey)
+	if home == "" {
+		report.add(consts.EnergyHomeKey, Warn, "not set", "energy setenv -p [framework path] . or energy use [framework] .")
+		return
+	}
+	if !tools.IsExist(home) {
+		report.add(consts.EnergyHomeKey, Fail, "directory does not exist: "+home, "energy install . or energy setenv -p [framework path] .")
+		return
+	}
+	report.add(consts.EnergyHomeKey, Pass, home, "")
+}
+
+// 项目或全局使用的框架, 返回框架目录和安装记录
+func checkFramework(c *command.Config, report *Report) (string, *framework.Framework) {
+	const name = "Framework"
+	projectPath := c.Doctor.Path
+	if projectPath == "" {
+		projectPath = c.Wd
+	}
+	frameworkPath, frameworkVersion := projectFramework(projectPath)
+	if frameworkPath == "" {
+		path, err := framework.Resolve(frameworkVersion)
+		if err != nil {
+			report.add(name, Fail, err.Error(), "energy install . or energy use [framework] .")
+			return "", nil
+		}
+		frameworkPath = path
+	}
+	if !tools.IsExist(frameworkPath) {
+		report.add(name, Fail, "directory does not exist: "+frameworkPath, "energy install . or energy use [framework] .")
+		return "", nil
+	}
+	if abs, err := filepath.Abs(frameworkPath); err == nil {
+		frameworkPath = abs
+	}
+	record, _ := framework.Find(frameworkPath)
+	if record != nil {
+		report.add(name, Pass, record.Name+" "+frameworkPath, "")
+	} else {
+		report.add(name, Pass, frameworkPath, "")
+	}
+	return frameworkPath, record
+}
+
+// 项目 energy.json 的 frameworkPath 和 frameworkVersion, 只读取框架配置
+func projectFramework(projectPath string) (frameworkPath, frameworkVersion string) {
+	data, err := os.ReadFile(filepath.Join(projectPath, consts.EnergyProjectConfig))
+	if err != nil {
+		return
+	}
+	var proj struct {
+		FrameworkPath    string `json:"frameworkPath"`
+		FrameworkVersion string `json:"frameworkVersion"`
+	}
+	if json.Unmarshal(data, &proj) == nil {
+		frameworkPath, frameworkVersion = proj.FrameworkPath, proj.FrameworkVersion
+	}
+	return
+}
+
+// 开发和打包工具, 可选
+func checkTools(c *command.Config, report *Report) {
+	for _, software := range install.CheckEnv(c) {
+		if software.Name == "Golang" || strings.HasPrefix(software.Name, "CEF") {
+			continue
+		}
+		if software.Installed {
+			report.add(software.Name, Pass, "installed", "")
+		} else {
+			report.add(software.Name, Warn, "not installed, required by energy package", "energy install . and select "+software.Name)
+		}
+	}
+}
+
+// version >= min, 只比较数字部分: 1.20.3 >= 1.18
+func versionAtLeast(version, min string) bool {
+	var parse = func(v string) []int {
+		var result []int
+		for _, s := range strings.Split(strings.TrimPrefix(v, "v"), ".") {
+			var n int
+			for _, r := range s {
+				if r < '0' || r > '9' {
+					break
+				}
+				n = n*10 + int(r-'0')
+			}
+			result = append(result, n)
+		}
+		return result
+	}
+	v, m := parse(version), parse(min)
+	for i := 0; i < len(m); i++ {
+		var n int
+		if i < len(v) {
+			n = v[i]
+		}
+		if n != m[i] {
+			return n > m[i]
+		}
+	}
+	return true
+}
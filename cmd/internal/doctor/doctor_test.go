@@ -0,0 +1,68 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestVersionAtLeast(t *testing.T) {
+	for version, ok := range map[string]bool{"1.18": true, "1.18.10": true, "1.20.3": true, "1.9": false, "1.17.13": false, "2.0": true, "1.21rc2": true} {
+		if versionAtLeast(version, "1.18") != ok {
+			t.Fatalf("%s >= 1.18: expected %v", version, ok)
+		}
+	}
+}
+
+func TestLDConfigPaths(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "ld.so.conf.d"), os.ModePerm)
+	os.WriteFile(filepath.Join(dir, "ld.so.conf"), []byte("# comment\ninclude ld.so.conf.d/*.conf\n/opt/lib # local\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "ld.so.conf.d", "a.conf"), []byte("/usr/lib/a\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "ld.so.conf.d", "b.conf"), []byte("/usr/lib/b\n"), 0644)
+	paths := ldConfigPaths(filepath.Join(dir, "ld.so.conf"), 0)
+	if !reflect.DeepEqual(paths, []string{"/usr/lib/a", "/usr/lib/b", "/opt/lib"}) {
+		t.Fatal(paths)
+	}
+}
+
+func TestCheckFrameworkFiles(t *testing.T) {
+	dir := t.TempDir()
+	report := &Report{}
+	checkFrameworkFiles(report, dir)
+	if report.Fail == 0 || report.Pass != 0 {
+		t.Fatalf("empty framework: pass %d, fail %d", report.Pass, report.Fail)
+	}
+	for _, file := range append(append(cefFiles[runtime.GOOS], liblclFiles[runtime.GOOS], localeFile), resourceFiles...) {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), os.ModePerm)
+		os.WriteFile(filepath.Join(dir, file), nil, 0644)
+	}
+	report = &Report{}
+	checkFrameworkFiles(report, dir)
+	if report.Fail != 0 {
+		t.Fatal(report.Checks)
+	}
+}
+
+func TestMissingLibraries(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil || runtime.GOOS != "linux" {
+		t.Skip("linux only")
+	}
+	needed, err := importedLibraries(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, err := missingLibraries(exe, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != len(needed) {
+		t.Fatalf("no search path, expected all missing: %v %v", needed, missing)
+	}
+	if gtkVersion([]string{"libc.so.6", "libgtk-x11-2.0.so.0"}) != 2 || gtkVersion([]string{"libgtk-3.so.0"}) != 3 {
+		t.Fatal("gtk version")
+	}
+}
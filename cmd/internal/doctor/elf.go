@@ -0,0 +1,234 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package doctor
+
+import (
+	"bufio"
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Linux 共享库依赖检查
+//  读取 ELF DT_NEEDED, 在 LD_LIBRARY_PATH, 框架目录, /etc/ld.so.conf 和系统默认目录查找
+
+// 常用依赖库对应的 Debian/Ubuntu 安装包
+var libraryPackages = map[string]string{
+	"libgtk-3.so.0":          "libgtk-3-0",
+	"libgdk-3.so.0":          "libgtk-3-0",
+	"libgtk-x11-2.0.so.0":    "libgtk2.0-0",
+	"libgdk-x11-2.0.so.0":    "libgtk2.0-0",
+	"libnss3.so":             "libnss3",
+	"libnssutil3.so":         "libnss3",
+	"libsmime3.so":           "libnss3",
+	"libnspr4.so":            "libnspr4",
+	"libasound.so.2":         "libasound2",
+	"libatk-1.0.so.0":        "libatk1.0-0",
+	"libatk-bridge-2.0.so.0": "libatk-bridge2.0-0",
+	"libatspi.so.0":          "libatspi2.0-0",
+	"libcups.so.2":           "libcups2",
+	"libdrm.so.2":            "libdrm2",
+	"libgbm.so.1":            "libgbm1",
+	"libxkbcommon.so.0":      "libxkbcommon0",
+	"libXcomposite.so.1":     "libxcomposite1",
+	"libXdamage.so.1":        "libxdamage1",
+	"libXfixes.so.3":         "libxfixes3",
+	"libXrandr.so.2":         "libxrandr2",
+	"libpango-1.0.so.0":      "libpango-1.0-0",
+	"libcairo.so.2":          "libcairo2",
+	"libdbus-1.so.3":         "libdbus-1-3",
+}
+
+// ELF 依赖的共享库
+func importedLibraries(file string) ([]string, error) {
+	f, err := elf.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.ImportedLibraries()
+}
+
+// 共享库查找目录
+func librarySearchPaths(frameworkPath string) []string {
+	var paths []string
+	for _, path := range filepath.SplitList(os.Getenv("LD_LIBRARY_PATH")) {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	paths = append(paths, frameworkPath)
+	paths = append(paths, ldConfigPaths("/etc/ld.so.conf", 0)...)
+	if triplet := multiarchTriplet(runtime.GOARCH); triplet != "" {
+		paths = append(paths, filepath.Join("/lib", triplet), filepath.Join("/usr/lib", triplet))
+	}
+	return append(paths, "/lib64", "/usr/lib64", "/lib", "/usr/lib", "/usr/local/lib")
+}
+
+// 读取 ld.so.conf 目录, 支持 include
+func ldConfigPaths(file string, depth int) []string {
+	if depth > 8 {
+		return nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "include") {
+			pattern := strings.TrimSpace(strings.TrimPrefix(line, "include"))
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join(filepath.Dir(file), pattern)
+			}
+			matches, _ := filepath.Glob(pattern)
+			sort.Strings(matches)
+			for _, match := range matches {
+				paths = append(paths, ldConfigPaths(match, depth+1)...)
+			}
+			continue
+		}
+		paths = append(paths, line)
+	}
+	return paths
+}
+
+// Debian 多架构目录名
+func multiarchTriplet(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64-linux-gnu"
+	case "arm64":
+		return "aarch64-linux-gnu"
+	case "386":
+		return "i386-linux-gnu"
+	case "arm":
+		return "arm-linux-gnueabihf"
+	}
+	return ""
+}
+
+// 查找共享库, 未找到返回空
+func findLibrary(name string, paths []string) string {
+	for _, path := range paths {
+		file := filepath.Join(path, name)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+	return ""
+}
+
+// ELF 未找到的依赖库
+func missingLibraries(file string, paths []string) ([]string, error) {
+	needed, err := importedLibraries(file)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, name := range needed {
+		if findLibrary(name, paths) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// liblcl 使用的 GTK 版本, 0: 未使用 GTK
+func gtkVersion(needed []string) int {
+	for _, name := range needed {
+		if strings.HasPrefix(name, "libgtk-3.") {
+			return 3
+		} else if strings.HasPrefix(name, "libgtk-x11-2.0.") {
+			return 2
+		}
+	}
+	return 0
+}
+
+// liblcl GTK 版本和系统 GTK
+func checkGTK(report *Report, frameworkPath string) {
+	const name = "GTK"
+	needed, err := importedLibraries(filepath.Join(frameworkPath, liblclFiles["linux"]))
+	if err != nil {
+		report.add(name, Warn, "read liblcl.so: "+err.Error(), "")
+		return
+	}
+	version := gtkVersion(needed)
+	if version == 0 {
+		report.add(name, Warn, "liblcl.so does not link GTK", "")
+		return
+	}
+	lib, pkg := "libgtk-3.so.0", "libgtk-3-0"
+	if version == 2 {
+		lib, pkg = "libgtk-x11-2.0.so.0", "libgtk2.0-0"
+	}
+	if path := findLibrary(lib, librarySearchPaths(frameworkPath)); path != "" {
+		report.add(name, Pass, fmt.Sprintf("GTK%d %s", version, path), "")
+	} else {
+		report.add(name, Fail, fmt.Sprintf("liblcl.so requires GTK%d, %s not found", version, lib), "sudo apt install "+pkg)
+	}
+}
+
+// libcef.so 和 liblcl.so 依赖库
+func checkSharedLibraries(report *Report, frameworkPath string) {
+	const name = "Shared Libraries"
+	paths := librarySearchPaths(frameworkPath)
+	var missing = make(map[string]bool)
+	for _, lib := range []string{"libcef.so", liblclFiles["linux"]} {
+		file := filepath.Join(frameworkPath, lib)
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		libs, err := missingLibraries(file, paths)
+		if err != nil {
+			report.add(name, Warn, "read "+lib+": "+err.Error(), "")
+			return
+		}
+		for _, l := range libs {
+			missing[l] = true
+		}
+	}
+	if len(missing) == 0 {
+		report.add(name, Pass, "all dependencies found", "")
+		return
+	}
+	var libs, pkgs []string
+	var pkgSet = make(map[string]bool)
+	for l := range missing {
+		libs = append(libs, l)
+		if pkg, ok := libraryPackages[l]; ok && !pkgSet[pkg] {
+			pkgSet[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Strings(libs)
+	sort.Strings(pkgs)
+	hint := "install the packages providing the missing libraries"
+	if len(pkgs) > 0 {
+		hint = "sudo apt install " + strings.Join(pkgs, " ")
+	}
+	report.add(name, Fail, "missing "+strings.Join(libs, ", "), hint)
+}
@@ -0,0 +1,110 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package doctor
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/framework"
+	"github.com/energye/energy/v2/cmd/internal/install"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// 框架目录必须的文件, 同 cef.CheckDLLs, CheckResources, CheckLocales
+//  不加载 liblcl, 只检查文件是否存在
+var (
+	cefFiles = map[string][]string{
+		"windows": {"libcef.dll", "chrome_elf.dll", "d3dcompiler_47.dll", "libEGL.dll", "libGLESv2.dll", "icudtl.dat", "snapshot_blob.bin", "v8_context_snapshot.bin"},
+		"linux":   {"libcef.so", "libEGL.so", "libGLESv2.so", "icudtl.dat", "snapshot_blob.bin", "v8_context_snapshot.bin"},
+		"darwin":  {"Chromium Embedded Framework.framework"},
+	}
+	liblclFiles = map[string]string{
+		"windows": "liblcl.dll",
+		"linux":   "liblcl.so",
+		"darwin":  "liblcl.dylib",
+	}
+	resourceFiles = []string{"resources.pak", "chrome_100_percent.pak", "chrome_200_percent.pak"}
+	localeFile    = filepath.Join("locales", "en-US.pak")
+)
+
+// 缺失的文件
+func missingFiles(dir string, files []string) []string {
+	var missing []string
+	for _, file := range files {
+		if !tools.IsExist(filepath.Join(dir, file)) {
+			missing = append(missing, file)
+		}
+	}
+	return missing
+}
+
+// 框架文件
+func checkFrameworkFiles(report *Report, frameworkPath string) {
+	const hint = "reinstall the framework: energy install ."
+	if missing := missingFiles(frameworkPath, cefFiles[runtime.GOOS]); len(missing) > 0 {
+		report.add("CEF", Fail, "missing "+strings.Join(missing, ", "), hint)
+	} else {
+		report.add("CEF", Pass, "binaries found", "")
+	}
+	if missing := missingFiles(frameworkPath, []string{liblclFiles[runtime.GOOS]}); len(missing) > 0 {
+		report.add("liblcl", Fail, "missing "+liblclFiles[runtime.GOOS], `energy install ., or build with the built-in liblcl: go build -tags="tempdll"`)
+	} else {
+		report.add("liblcl", Pass, liblclFiles[runtime.GOOS], "")
+	}
+	if consts.IsDarwin {
+		// MacOS 资源和语言包在 Chromium Embedded Framework.framework/Resources
+		return
+	}
+	if missing := missingFiles(frameworkPath, resourceFiles); len(missing) > 0 {
+		report.add("CEF Resources", Fail, "missing "+strings.Join(missing, ", "), hint)
+	} else {
+		report.add("CEF Resources", Pass, "resources found", "")
+	}
+	if missing := missingFiles(frameworkPath, []string{localeFile}); len(missing) > 0 {
+		report.add("CEF Locales", Fail, "missing "+localeFile, hint)
+	} else {
+		report.add("CEF Locales", Pass, "locales found", "")
+	}
+}
+
+// liblcl 和 CEF 版本兼容
+//  框架安装记录: 版本和系统架构
+//  Windows 7/8: CEF <= 109
+//  Linux: liblcl GTK2 只能使用 CEF <= 106
+func checkCompatibility(report *Report, frameworkPath string, record *framework.Framework) {
+	const name = "Compatibility"
+	if record == nil {
+		report.add(name, Warn, "framework is not installed by energy install, version unknown", "energy install . or energy use [framework] .")
+		return
+	}
+	if !record.IsHost() {
+		report.add(name, Fail, fmt.Sprintf("framework %s/%s does not match the system %s/%s", record.OS, record.Arch, runtime.GOOS, runtime.GOARCH), "energy install . or energy use [framework] .")
+		return
+	}
+	cefMajor, _ := strconv.Atoi(record.CEFMajor())
+	if consts.IsWindows {
+		if major, _, _ := install.SystemVersion(); major > 0 && major < 10 && cefMajor > 109 {
+			report.add(name, Fail, fmt.Sprintf("CEF %s does not support Windows %d, CEF 109 is the last version supporting Windows 7", record.CEFVersion, major), "energy install --cef=109 .")
+			return
+		}
+	}
+	if consts.IsLinux && cefMajor > 106 {
+		if needed, err := importedLibraries(filepath.Join(frameworkPath, liblclFiles["linux"])); err == nil && gtkVersion(needed) == 2 {
+			report.add(name, Fail, fmt.Sprintf("liblcl GTK2 does not support CEF %s", record.CEFVersion), "energy install --cef=106 .")
+			return
+		}
+	}
+	report.add(name, Pass, fmt.Sprintf("energy %s, CEF %s, liblcl %s", record.Version, record.CEFVersion, record.Liblcl), "")
+}
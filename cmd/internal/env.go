@@ -30,14 +30,18 @@ func init() {
 }
 
 func runGetEnv(c *command.Config) error {
-	term.Section.Println(consts.GolanHomeKey, os.Getenv(consts.GolanHomeKey))
-	term.Section.Println(consts.EnergyHomeKey, os.Getenv(consts.EnergyHomeKey))
+	keys := []string{consts.GolanHomeKey, consts.EnergyHomeKey}
 	if consts.IsWindows {
-		term.Section.Println(consts.NSISHomeKey, os.Getenv(consts.NSISHomeKey))
-		term.Section.Println(consts.Z7ZHomeKey, os.Getenv(consts.Z7ZHomeKey))
+		keys = append(keys, consts.NSISHomeKey, consts.Z7ZHomeKey)
 	}
 	if !consts.IsDarwin {
-		term.Section.Println(consts.UPXHomeKey, os.Getenv(consts.UPXHomeKey))
+		keys = append(keys, consts.UPXHomeKey)
 	}
+	result := make(map[string]string)
+	for _, key := range keys {
+		result[key] = os.Getenv(key)
+		term.Section.Println(key, result[key])
+	}
+	c.Result = result
 	return nil
 }
@@ -0,0 +1,302 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 已安装框架管理
+//  ~/.energy/frameworks.json 记录所有安装的框架 (CEF + liblcl) 目录和版本
+//  ~/.energy/energy.json framework 当前全局使用的框架名称
+//  项目 energy.json frameworkVersion 项目使用的框架名称
+
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"github.com/energye/golcl/energy/homedir"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	storeFile  = "frameworks.json"
+	configFile = "energy.json"
+)
+
+// Framework 已安装的框架
+type Framework struct {
+	Name       string `json:"name"`       // 名称, 版本-cef主版本号[-os-arch]
+	Path       string `json:"path"`       // 安装目录
+	Version    string `json:"version"`    // energy 版本
+	CEF        string `json:"cef"`        // CEF 模块, cef, cef-109, cef-106, cef-87
+	CEFVersion string `json:"cefVersion"` // CEF 版本, 109.1.18
+	Liblcl     string `json:"liblcl"`     // liblcl 版本
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	Installed  string `json:"installed"` // 安装时间
+}
+
+type store struct {
+	Frameworks []*Framework `json:"frameworks"`
+}
+
+// NotFoundError 未找到框架
+type NotFoundError struct {
+	Spec string
+}
+
+func (m *NotFoundError) Error() string {
+	return fmt.Sprintf("framework %s is not installed, see: energy list", m.Spec)
+}
+
+// AmbiguousError 匹配到多个框架
+type AmbiguousError struct {
+	Spec  string
+	Names []string
+}
+
+func (m *AmbiguousError) Error() string {
+	return fmt.Sprintf("framework %s matches %s, use the full name", m.Spec, strings.Join(m.Names, ", "))
+}
+
+// NameOf 框架名称 版本-cef主版本号, 非当前系统架构时追加 -os-arch
+//  v2.3.0-cef109, v2.3.0-cef118-linux-arm64
+func NameOf(version, cefVersion, goos, goarch string) string {
+	name := version + "-cef" + strings.Split(cefVersion, ".")[0]
+	if goos != runtime.GOOS || goarch != runtime.GOARCH {
+		name += "-" + goos + "-" + goarch
+	}
+	return name
+}
+
+// CEFMajor CEF 主版本号
+func (m *Framework) CEFMajor() string {
+	return strings.Split(m.CEFVersion, ".")[0]
+}
+
+// IsHost 是否为当前系统架构
+func (m *Framework) IsHost() bool {
+	return m.OS == runtime.GOOS && m.Arch == runtime.GOARCH
+}
+
+// Exists 安装目录是否存在
+func (m *Framework) Exists() bool {
+	return tools.IsExist(m.Path)
+}
+
+// Dir ~/.energy
+func Dir() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".energy"), nil
+}
+
+func load() (*store, string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, "", err
+	}
+	file := filepath.Join(dir, storeFile)
+	s := &store{}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return s, file, nil
+	} else if err != nil {
+		return nil, "", err
+	}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, "", fmt.Errorf("%s: %v", file, err)
+	}
+	return s, file, nil
+}
+
+func (m *store) save(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// List 所有已安装框架, 按名称排序
+func List() ([]*Framework, error) {
+	s, _, err := load()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(s.Frameworks, func(i, j int) bool {
+		return s.Frameworks[i].Name < s.Frameworks[j].Name
+	})
+	return s.Frameworks, nil
+}
+
+// Add 记录安装的框架, 名称和版本相同时替换, 不同版本并存
+func Add(f *Framework) error {
+	s, file, err := load()
+	if err != nil {
+		return err
+	}
+	var result = []*Framework{f}
+	for _, item := range s.Frameworks {
+		if item.Name != f.Name || item.Version != f.Version {
+			result = append(result, item)
+		}
+	}
+	s.Frameworks = result
+	return s.save(file)
+}
+
+// Remove 删除框架记录, 不删除安装目录
+func Remove(name string) error {
+	s, file, err := load()
+	if err != nil {
+		return err
+	}
+	var result []*Framework
+	for _, item := range s.Frameworks {
+		if item.Name != name {
+			result = append(result, item)
+		}
+	}
+	s.Frameworks = result
+	if current, _ := currentName(); current == name {
+		if err = SetCurrent(""); err != nil {
+			return err
+		}
+	}
+	return s.save(file)
+}
+
+// Find 查找框架
+//  spec: 名称, 安装目录, energy 版本 (v2.3.0), CEF 版本 (109, cef109, cef-109) 或 latest
+//  版本和CEF匹配到多个时只保留当前系统架构, 仍有多个时返回 AmbiguousError
+func Find(spec string) (*Framework, error) {
+	frameworks, err := List()
+	if err != nil {
+		return nil, err
+	}
+	spec = strings.TrimSpace(spec)
+	for _, f := range frameworks {
+		if f.Name == spec || (filepath.IsAbs(spec) && filepath.Clean(f.Path) == filepath.Clean(spec)) {
+			return f, nil
+		}
+	}
+	var candidates []*Framework
+	if spec == "latest" {
+		var latest *Framework
+		for _, f := range frameworks {
+			if f.IsHost() && f.CEF == consts.CefKey && (latest == nil || tools.Compare(f.Version, latest.Version)) {
+				latest = f
+			}
+		}
+		if latest == nil {
+			return nil, &NotFoundError{Spec: spec}
+		}
+		return latest, nil
+	}
+	cef := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(spec), "cef"), "-")
+	for _, f := range frameworks {
+		if strings.TrimPrefix(f.Version, "v") == strings.TrimPrefix(spec, "v") || f.CEFMajor() == cef {
+			candidates = append(candidates, f)
+		}
+	}
+	if len(candidates) > 1 {
+		var host []*Framework
+		for _, f := range candidates {
+			if f.IsHost() {
+				host = append(host, f)
+			}
+		}
+		if len(host) > 0 {
+			candidates = host
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, &NotFoundError{Spec: spec}
+	case 1:
+		return candidates[0], nil
+	}
+	var names []string
+	for _, f := range candidates {
+		names = append(names, f.Name)
+	}
+	return nil, &AmbiguousError{Spec: spec, Names: names}
+}
+
+// 全局配置 ~/.energy/energy.json 的 framework
+func currentName() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, configFile))
+	if err != nil {
+		return "", nil
+	}
+	var config struct {
+		Framework string `json:"framework"`
+	}
+	if err = json.Unmarshal(data, &config); err != nil {
+		return "", err
+	}
+	return config.Framework, nil
+}
+
+// Current 全局使用的框架, 未设置时返回 nil
+func Current() (*Framework, error) {
+	name, err := currentName()
+	if err != nil || name == "" {
+		return nil, err
+	}
+	return Find(name)
+}
+
+// SetCurrent 设置全局使用的框架, name 为空时清除
+func SetCurrent(name string) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	return tools.SetJSONFields(filepath.Join(dir, configFile), map[string]any{"framework": name})
+}
+
+// Resolve 项目使用的框架目录
+//  1. frameworkVersion: 项目 energy.json 指定的框架
+//  2. 全局使用的框架, energy use
+//  3. 环境变量 ENERGY_HOME
+func Resolve(frameworkVersion string) (string, error) {
+	if frameworkVersion != "" {
+		f, err := Find(frameworkVersion)
+		if err != nil {
+			return "", err
+		}
+		return f.Path, nil
+	}
+	if f, err := Current(); err == nil && f != nil && f.Exists() {
+		return f.Path, nil
+	}
+	if home := os.Getenv(consts.EnergyHomeKey); home != "" {
+		return home, nil
+	}
+	return "", errors.New("energy framework is not configured, see: energy install, energy use")
+}
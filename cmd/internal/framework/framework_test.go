@@ -0,0 +1,75 @@
+package framework
+
+import (
+	"github.com/energye/golcl/energy/homedir"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func testHome(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+	return home
+}
+
+func TestFind(t *testing.T) {
+	home := testHome(t)
+	add := func(version, cef, goos, goarch string) *Framework {
+		f := &Framework{Name: NameOf(version, cef, goos, goarch), Path: filepath.Join(home, version+cef+goos), Version: version, CEF: "cef", CEFVersion: cef, OS: goos, Arch: goarch}
+		os.MkdirAll(f.Path, os.ModePerm)
+		if err := Add(f); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	a := add("v2.3.0", "109.1.18", runtime.GOOS, runtime.GOARCH)
+	b := add("v2.4.0", "118.7.1", runtime.GOOS, runtime.GOARCH)
+	add("v2.4.0", "118.7.1", "other", "arch")
+	for spec, name := range map[string]string{"v2.3.0": a.Name, "109": a.Name, "cef-118": b.Name, "v2.4.0": b.Name, "latest": b.Name, a.Path: a.Name} {
+		f, err := Find(spec)
+		if err != nil {
+			t.Fatal(spec, err)
+		}
+		if f.Name != name {
+			t.Fatalf("%s: expected %s, got %s", spec, name, f.Name)
+		}
+	}
+	if _, err := Find("87"); err == nil {
+		t.Fatal("expected not found")
+	}
+	if err := SetCurrent(a.Name); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := Resolve(""); err != nil || path != a.Path {
+		t.Fatal(path, err)
+	}
+	if err := Remove(a.Name); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := Current(); f != nil {
+		t.Fatal("current not cleared")
+	}
+}
+
+func TestAddVersions(t *testing.T) {
+	home := testHome(t)
+	a := &Framework{Name: NameOf("v2.4.0", "118.7.1", runtime.GOOS, runtime.GOARCH), Path: filepath.Join(home, "EnergyFramework-v2.4.0-cef118"), Version: "v2.4.0", CEFVersion: "118.7.1", OS: runtime.GOOS, Arch: runtime.GOARCH}
+	b := &Framework{Name: NameOf("v2.5.0", "118.7.1", runtime.GOOS, runtime.GOARCH), Path: filepath.Join(home, "EnergyFramework-v2.5.0-cef118"), Version: "v2.5.0", CEFVersion: "118.7.1", OS: runtime.GOOS, Arch: runtime.GOARCH}
+	for _, f := range []*Framework{a, b, {Name: a.Name, Path: a.Path, Version: a.Version, CEFVersion: a.CEFVersion, Liblcl: "2.4.1", OS: a.OS, Arch: a.Arch}} {
+		if err := Add(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frameworks, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frameworks) != 2 || frameworks[0].Name != a.Name || frameworks[0].Liblcl != "2.4.1" || frameworks[1].Name != b.Name {
+		t.Fatal("expected both versions, reinstall replaces the same version", frameworks)
+	}
+}
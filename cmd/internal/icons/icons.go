@@ -0,0 +1,119 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 应用图标
+//  一个高分辨率 PNG (建议 1024x1024) 生成 windows ico, macos icns, linux freedesktop hicolor 图标
+//  纯 Go 实现, 任何系统都可以生成, 不依赖 sips, iconutil
+//  SVG 需要先转换为 PNG
+
+package icons
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/pkgs/winicon"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ICOSizes windows ico 尺寸
+var ICOSizes = []int{256, 128, 64, 48, 32, 16}
+
+// HicolorSizes freedesktop hicolor 图标尺寸
+var HicolorSizes = []int{16, 22, 24, 32, 48, 64, 128, 256, 512}
+
+// 建议的最小尺寸
+const minSourceSize = 512
+
+// Load 读取源图标
+//  非正方形或小于 512 时警告, 生成的图标会变形或模糊
+func Load(source string) (image.Image, error) {
+	if strings.ToLower(filepath.Ext(source)) == ".svg" {
+		return nil, fmt.Errorf("svg icon is not supported, rasterise %s to a 1024x1024 png", source)
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode icon %s: %w", source, err)
+	}
+	b := img.Bounds()
+	if b.Dx() != b.Dy() {
+		term.Logger.Warn(fmt.Sprintf("icon %s is not square (%dx%d), it will be stretched", source, b.Dx(), b.Dy()))
+	} else if b.Dx() < minSourceSize {
+		term.Logger.Warn(fmt.Sprintf("icon %s is %dx%d, use at least %dx%d for sharp large icons", source, b.Dx(), b.Dy(), minSourceSize, minSourceSize))
+	}
+	return img, nil
+}
+
+// ICO 生成 windows ico
+func ICO(img image.Image, out string) error {
+	return writeFile(out, func(buf *bytes.Buffer) error {
+		return winicon.EncodeIcon(buf, img, ICOSizes)
+	})
+}
+
+// ICNS 生成 macos icns
+func ICNS(img image.Image, out string) error {
+	return writeFile(out, func(buf *bytes.Buffer) error {
+		return winicon.EncodeICNS(buf, img)
+	})
+}
+
+// Hicolor 生成 freedesktop hicolor 图标
+//  dir/[size]x[size]/apps/[name].png, 返回生成的文件
+func Hicolor(img image.Image, dir, name string) ([]string, error) {
+	var files []string
+	for _, size := range HicolorSizes {
+		data, err := winicon.ScalePNG(img, size)
+		if err != nil {
+			return nil, err
+		}
+		file := filepath.Join(dir, fmt.Sprintf("%dx%d", size, size), "apps", name+".png")
+		if err = writeFile(file, func(buf *bytes.Buffer) error {
+			_, err := buf.Write(data)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// PNG 生成指定尺寸 png
+func PNG(img image.Image, size int, out string) error {
+	return writeFile(out, func(buf *bytes.Buffer) error {
+		data, err := winicon.ScalePNG(img, size)
+		if err != nil {
+			return err
+		}
+		_, err = buf.Write(data)
+		return err
+	})
+}
+
+func writeFile(out string, encode func(buf *bytes.Buffer) error) error {
+	var buf bytes.Buffer
+	if err := encode(&buf); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0644)
+}
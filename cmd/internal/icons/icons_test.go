@@ -0,0 +1,104 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package icons
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/pkgs/winicon"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	src := image.NewRGBA(image.Rect(0, 0, 512, 512))
+	for i := 0; i < 512; i++ {
+		src.Set(i, i, color.RGBA{R: 255, A: 255})
+	}
+	source := filepath.Join(dir, "icon.png")
+	f, _ := os.Create(source)
+	if err := png.Encode(f, src); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	img, err := Load(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := func(images []image.Image) []int {
+		var result []int
+		for _, img := range images {
+			result = append(result, img.Bounds().Dx())
+		}
+		return result
+	}
+
+	ico := filepath.Join(dir, "windows", "icon.ico")
+	if err = ICO(img, ico); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = os.Open(ico)
+	images, err := winicon.Decode(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sizes(images); !reflect.DeepEqual(got, ICOSizes) {
+		t.Errorf("ico sizes %v, want %v", got, ICOSizes)
+	}
+
+	icns := filepath.Join(dir, "darwin", "icon.icns")
+	if err = ICNS(img, icns); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = os.Open(icns)
+	images, err = winicon.DecodeICNS(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sizes(images), []int{16, 32, 64, 128, 256, 512, 1024, 32, 64, 256, 512}; !reflect.DeepEqual(got, want) {
+		t.Errorf("icns sizes %v, want %v", got, want)
+	}
+
+	hicolor := filepath.Join(dir, "hicolor")
+	files, err := Hicolor(img, hicolor, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, size := range HicolorSizes {
+		want = append(want, filepath.Join(hicolor, fmt.Sprintf("%dx%d", size, size), "apps", "demo.png"))
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("hicolor files %v, want %v", files, want)
+	}
+	for i, file := range files {
+		f, _ = os.Open(file)
+		cfg, err := png.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Width != HicolorSizes[i] || cfg.Height != HicolorSizes[i] {
+			t.Errorf("%s: %dx%d", file, cfg.Width, cfg.Height)
+		}
+	}
+
+	if _, err = Load(filepath.Join(dir, "icon.svg")); err == nil {
+		t.Error("expected svg error")
+	}
+}
@@ -13,6 +13,7 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"github.com/energye/energy/v2/cmd/internal/command"
 	"github.com/energye/energy/v2/cmd/internal/initialize"
@@ -28,6 +29,15 @@ var CmdInit = &command.Command{
 	Long: `
 	Initialize energy golang project
 	-n Initialized project name
+	-r Resource loading method of the plain template, 1: HTTP, 2: Local Load
+	-t Project template, default select
+		plain: static html in resources
+		vue, react, svelte: vite frontend in frontend, frontend/dist loaded by Local Load, use the ipc bridge in frontend/src/ipc.js
+		user templates: template name in ~/.energy/templates, template directory, .tar.gz file or http(s) URL
+	-l List the available project templates
+	--var Template variable name:value, e.g. --var Title:MyApp
+	--no-hooks Do not run the template commands after generation, e.g. npm install
+		commands of non built-in templates are printed and run after confirmation or with --yes
 	.  Execute command
 `,
 }
@@ -38,7 +48,17 @@ func init() {
 
 func runInit(c *command.Config) error {
 	m := &c.Init
+	if m.List {
+		return listTemplates()
+	}
+	// -y, --yes 使用默认模板和资源加载方式, 必须指定项目名
+	interactive, promptErr := term.Interactive()
 	if strings.TrimSpace(m.Name) == "" {
+		if promptErr != nil {
+			return promptErr
+		} else if !interactive {
+			return command.WithCode(command.ExitUsage, errors.New("project name is required, -n [name]"))
+		}
 		for strings.TrimSpace(m.Name) == "" {
 			print("Project Name: ")
 			fmt.Scan(&m.Name)
@@ -47,23 +67,85 @@ func runInit(c *command.Config) error {
 	}
 	m.Name = strings.TrimSpace(m.Name)
 
-	options := []string{"HTTP", "Local Load"}
-	printer := term.DefaultInteractiveSelect.WithOnInterruptFunc(func() {
-		os.Exit(1)
-	}).WithOptions(options)
-	printer.CheckmarkANSI()
-	printer.DefaultText = "Resource Loading. Default HTTP"
-	printer.Filter = false
-	selectedOption, err := printer.Show()
+	if m.Template == "" && promptErr != nil {
+		return promptErr
+	} else if m.Template == "" && !interactive {
+		m.Template = initialize.DefaultTemplate
+	} else if m.Template == "" {
+		templates, err := initialize.Templates()
+		if err != nil {
+			return err
+		}
+		var options []string
+		for _, t := range templates {
+			options = append(options, fmt.Sprintf("%s - %s", t.Name, t.Description))
+		}
+		printer := term.DefaultInteractiveSelect.WithOnInterruptFunc(func() {
+			os.Exit(command.ExitInterrupt)
+		}).WithOptions(options)
+		printer.CheckmarkANSI()
+		printer.DefaultText = "Project Template. Default " + initialize.DefaultTemplate
+		printer.Filter = false
+		selectedOption, err := printer.Show()
+		if err != nil {
+			return err
+		}
+		m.Template = strings.SplitN(selectedOption, " - ", 2)[0]
+	}
+	tmpl, err := initialize.LoadTemplate(m.Template)
 	if err != nil {
 		return err
 	}
-	pterm.Info.Printfln("Selected: %s", pterm.Green(selectedOption))
-	if selectedOption == "" || selectedOption == "HTTP" {
-		m.ResLoad = "1"
-	} else if selectedOption == "Local Load" {
+	defer tmpl.Close()
+	pterm.Info.Printfln("Template: %s", pterm.Green(tmpl.Name))
+
+	if !tmpl.ResLoad {
+		// 前端模板使用 Local Load
 		m.ResLoad = "2"
+	} else if m.ResLoad != "1" && m.ResLoad != "2" && promptErr != nil {
+		return promptErr
+	} else if m.ResLoad != "1" && m.ResLoad != "2" && !interactive {
+		m.ResLoad = "1"
+	} else if m.ResLoad != "1" && m.ResLoad != "2" {
+		options := []string{"HTTP", "Local Load"}
+		printer := term.DefaultInteractiveSelect.WithOnInterruptFunc(func() {
+			os.Exit(command.ExitInterrupt)
+		}).WithOptions(options)
+		printer.CheckmarkANSI()
+		printer.DefaultText = "Resource Loading. Default HTTP"
+		printer.Filter = false
+		selectedOption, err := printer.Show()
+		if err != nil {
+			return err
+		}
+		pterm.Info.Printfln("Selected: %s", pterm.Green(selectedOption))
+		if selectedOption == "" || selectedOption == "HTTP" {
+			m.ResLoad = "1"
+		} else if selectedOption == "Local Load" {
+			m.ResLoad = "2"
+		}
 	}
 
-	return initialize.InitEnergyProject(c)
+	return initialize.InitEnergyProject(c, tmpl)
+}
+
+// 可用的项目模板
+func listTemplates() error {
+	templates, err := initialize.Templates()
+	if err != nil {
+		return err
+	}
+	tableData := pterm.TableData{
+		{"Name", "Description", "Source"},
+	}
+	for _, t := range templates {
+		tableData = append(tableData, []string{t.Name, t.Description, t.Source})
+	}
+	if err = pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithBoxed().WithData(tableData).Render(); err != nil {
+		return err
+	}
+	if dir, err := initialize.TemplatesDir(); err == nil {
+		term.Logger.Info("User templates", term.Logger.Args("dir", dir))
+	}
+	return nil
 }
@@ -29,16 +29,29 @@ import (
 	"strings"
 )
 
-func InitEnergyProject(c *command.Config) error {
+func InitEnergyProject(c *command.Config, tmpl *Template) error {
 	// 检查环境
 	checkEnv(&c.Init)
 	// 生成项目
-	if err := generaProject(c); err != nil {
+	if err := generaProject(c, tmpl); err != nil {
 		return err
 	}
 	pterm.Println()
 	term.Section.Println("Successfully initialized the energy application project:", c.Init.Name)
 	term.Logger.Info("Website", term.Logger.Args("Github", "https://github.com/energye/energy", "ENERGY", "https://energy.yanghy.cn"))
+	if len(tmpl.Hooks) > 0 {
+		term.Section.Println("Template commands, run again after changing the frontend")
+		tableData := pterm.TableData{
+			{"dir", "command"},
+		}
+		for _, hook := range tmpl.Hooks {
+			tableData = append(tableData, []string{hook.Dir, strings.Join(hook.Command, " ")})
+		}
+		err := pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithBoxed().WithData(tableData).Render()
+		if err != nil {
+			return err
+		}
+	}
 	term.Section.Println("Run Application")
 	tableData := pterm.TableData{
 		{"command"}, {"go run main.go"},
@@ -68,7 +81,7 @@ func InitEnergyProject(c *command.Config) error {
 	return nil
 }
 
-func generaProject(c *command.Config) error {
+func generaProject(c *command.Config, tmpl *Template) error {
 	pterm.Println()
 	projectPath := filepath.Join(c.Wd, c.Init.Name)
 	term.Logger.Info("Create Project", term.Logger.Args("Name", c.Init.Name))
@@ -135,10 +148,6 @@ func generaProject(c *command.Config) error {
 		return err
 	}
 
-	// 创建 main.go
-	if err := createFile(fmt.Sprintf("assets/initialize/main.go.%s", c.Init.ResLoad), "main.go", nil, 0666); err != nil {
-		return err
-	}
 	term.Logger.Info("Get latest release number")
 	latest := "latest" // 默认
 	latestVersionJSON, err := tools.HttpRequestGET(consts.LatestVersionURL)
@@ -158,22 +167,27 @@ func generaProject(c *command.Config) error {
 		term.Logger.Error(err.Error())
 	}
 	term.Logger.Info("ENERGY latest release number: " + latest)
-	// 创建 go.mod
+	// 模板变量
 	data = make(map[string]any)
 	data["Name"] = c.Init.Name
+	data["ProjectPath"] = filepath.ToSlash(projectPath)
+	data["FrameworkPath"] = filepath.ToSlash(os.Getenv(consts.EnergyHomeKey))
 	data["GoVersion"] = "1.18"
 	data["EnergyVersion"] = latest
+	data["ResLoad"] = c.Init.ResLoad
+	data, err = tmpl.Data(data, c.Init.Vars)
+	if err != nil {
+		return err
+	}
+	// 创建 go.mod
 	if err := createFile("assets/initialize/go.mod.t", "go.mod", data, 0666); err != nil {
 		return err
 	}
 
-	// 创建 resources/index.html
+	// 创建 resources 图标
 	if err := os.MkdirAll(filepath.Join(projectPath, "resources"), fs.ModePerm); err != nil {
 		return err
 	}
-	if err := createFile("assets/initialize/index.html", filepath.Join("resources", "index.html"), nil, 0666); err != nil {
-		return err
-	}
 	if err := createFile("assets/icon.ico", filepath.Join("resources", "icon.ico"), nil, 0666); err != nil {
 		return err
 	}
@@ -182,12 +196,28 @@ func generaProject(c *command.Config) error {
 	}
 
 	// 创建 README.md
-	data = make(map[string]any)
-	data["Name"] = c.Init.Name
 	if err := createFile("assets/initialize/README.md", "README.md", data, 0666); err != nil {
 		return err
 	}
 
+	// 模板文件, 可覆盖以上默认文件
+	term.Logger.Info("Generate template", term.Logger.Args("Template", tmpl.Name, "Source", tmpl.Source))
+	files, err := tmpl.Generate(projectPath, data)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		term.Section.Println("  ", file)
+	}
+	if c.Init.NoHooks {
+		term.Logger.Info("Skip template commands")
+	} else if !confirmHooks(tmpl, data) {
+		term.Logger.Warn("Skip template commands, run them manually or use --yes")
+	} else if err = tmpl.RunHooks(projectPath, data); err != nil {
+		// 项目已生成, 可以手动执行
+		term.Logger.Warn(fmt.Sprintf("Template command failed, run it manually: %v", err))
+	}
+
 	// cmd
 	term.Section.Println("Config Go Environment")
 	cmd := toolsCommand.NewCMD()
@@ -210,6 +240,36 @@ func generaProject(c *command.Config) error {
 	return nil
 }
 
+// 是否执行模板命令, 内置模板直接执行
+//  其它模板 (用户目录, 压缩包, http 地址) 先显示命令, 交互式确认或 --yes 后执行
+func confirmHooks(tmpl *Template, data map[string]any) bool {
+	if tmpl.Builtin() {
+		return true
+	}
+	commands, err := tmpl.HookCommands(data)
+	if err != nil {
+		term.Logger.Error(err.Error())
+		return false
+	}
+	if len(commands) == 0 {
+		return true
+	}
+	term.Logger.Warn("Template " + tmpl.Name + " (" + tmpl.Source + ") runs the following commands")
+	for _, line := range commands {
+		term.Section.Println("  ", line)
+	}
+	interactive, err := term.Interactive()
+	if err != nil {
+		// --quiet, --output json 无法确认
+		return false
+	} else if !interactive {
+		// -y, --yes
+		return true
+	}
+	ok, err := pterm.DefaultInteractiveConfirm.Show("Run template commands")
+	return err == nil && ok
+}
+
 func checkEnv(init *command.Init) {
 	term.Logger.Info("Check the current environment and follow the prompts if there are any")
 	// 检查Go环境
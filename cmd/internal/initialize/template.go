@@ -0,0 +1,439 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 项目模板
+//  内置模板: assets/initialize/templates/[name]
+//  用户模板: ~/.energy/templates/[name], 模板目录, 或模板 .tar.gz 压缩包 (本地文件或 http 地址)
+//  模板目录中 template.json 为模板配置, 其它文件按相对路径生成到项目目录
+//  .tmpl 结尾的文件使用 text/template 渲染变量, 生成时去掉 .tmpl, 其它文件原样复制
+//  hooks 生成后执行的命令, 内置模板直接执行, 其它模板显示命令, 确认或 --yes 后执行
+
+package initialize
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/assets"
+	"github.com/energye/energy/v2/cmd/internal/framework"
+	"github.com/energye/energy/v2/cmd/internal/install"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	templateConfig  = "template.json"
+	templateExt     = ".tmpl"
+	templatesFSPath = "assets/initialize/templates"
+	DefaultTemplate = "plain"
+	builtinSource   = "builtin"
+)
+
+// Template 项目模板
+type Template struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	ResLoad     bool              `json:"resLoad"`   // 可选择资源加载方式 HTTP 或 Local Load
+	Variables   []*Variable       `json:"variables"` // 模板变量
+	Rename      map[string]string `json:"rename"`    // 生成时重命名, 模板路径 => 项目路径, 例如内置资源不能包含的 .gitignore
+	Hooks       []*Hook           `json:"hooks"`     // 生成后执行的命令
+	Source      string            `json:"-"`         // 模板来源, builtin, 目录或压缩包
+	fs          fs.FS
+	temp        string // 压缩包释放的临时目录
+}
+
+// Variable 模板变量
+//  默认值可以使用其它变量, 例如 {{.Name}}, 通过 energy init --var name:value 设置
+type Variable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     string `json:"default"`
+}
+
+// Hook 生成项目后执行的命令
+//  命令参数可以使用模板变量, requires 命令不存在时跳过
+type Hook struct {
+	Name     string   `json:"name"`
+	Dir      string   `json:"dir"` // 相对项目目录
+	Command  []string `json:"command"`
+	Requires string   `json:"requires"`
+}
+
+// TemplatesDir 用户模板目录 ~/.energy/templates
+func TemplatesDir() (string, error) {
+	dir, err := framework.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "templates"), nil
+}
+
+// Templates 返回内置模板和用户模板目录中的模板, 同名时用户模板优先
+func Templates() ([]*Template, error) {
+	var result []*Template
+	names := make(map[string]int)
+	add := func(t *Template) {
+		if i, ok := names[t.Name]; ok {
+			result[i] = t
+			return
+		}
+		names[t.Name] = len(result)
+		result = append(result, t)
+	}
+	builtin, err := assets.Sub(templatesFSPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(builtin, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		sub, err := fs.Sub(builtin, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		t, err := readTemplate(sub, entry.Name(), builtinSource)
+		if err != nil {
+			return nil, err
+		}
+		add(t)
+	}
+	if dir, err := TemplatesDir(); err == nil {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			templateDir := filepath.Join(dir, entry.Name())
+			if !entry.IsDir() || !tools.IsExist(filepath.Join(templateDir, templateConfig)) {
+				continue
+			}
+			t, err := readTemplate(os.DirFS(templateDir), entry.Name(), templateDir)
+			if err != nil {
+				term.Logger.Warn(fmt.Sprintf("template %s: %v", templateDir, err))
+				continue
+			}
+			add(t)
+		}
+	}
+	// 默认模板在前
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name == DefaultTemplate && result[j].Name != DefaultTemplate
+	})
+	return result, nil
+}
+
+// LoadTemplate 加载模板
+//  name: 模板名称, 模板目录, .tar.gz/.tgz 文件或 http(s) 地址
+//  使用完调用 Close 删除临时文件
+func LoadTemplate(name string) (*Template, error) {
+	if name == "" {
+		name = DefaultTemplate
+	}
+	switch {
+	case isTarball(name):
+		return loadTarball(name)
+	case strings.ContainsAny(name, `/\`) || name == ".":
+		if !tools.IsExist(filepath.Join(name, templateConfig)) {
+			return nil, fmt.Errorf("template %s: %s not found", name, templateConfig)
+		}
+		dir, err := filepath.Abs(name)
+		if err != nil {
+			return nil, err
+		}
+		return readTemplate(os.DirFS(dir), filepath.Base(dir), dir)
+	}
+	templates, err := Templates()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, t := range templates {
+		if t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return nil, fmt.Errorf("template %s not found, available: %s", name, strings.Join(names, ", "))
+}
+
+func isTarball(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// 读取 template.json, 名称为空时使用目录名
+func readTemplate(fsys fs.FS, name, source string) (*Template, error) {
+	data, err := fs.ReadFile(fsys, templateConfig)
+	if err != nil {
+		return nil, err
+	}
+	t := &Template{}
+	if err = json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("%s: %w", templateConfig, err)
+	}
+	if t.Name == "" {
+		t.Name = name
+	}
+	t.Source, t.fs = source, fsys
+	return t, nil
+}
+
+// 释放压缩包到临时目录, 压缩包只有一个根目录时 (例如 github archive) 使用该目录
+func loadTarball(name string) (*Template, error) {
+	var reader io.Reader
+	if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") {
+		term.Logger.Info("Download template", term.Logger.Args("URL", name))
+		// 超时, 重试, 非 2xx 状态码返回错误
+		d, err := install.NewDownloader("", 0)
+		if err != nil {
+			return nil, err
+		}
+		data, err := d.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		reader = f
+	}
+	temp, err := os.MkdirTemp("", "energy-template-")
+	if err != nil {
+		return nil, err
+	}
+	if err = extractTarGz(reader, temp); err != nil {
+		os.RemoveAll(temp)
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	dir := temp
+	if entries, _ := os.ReadDir(dir); len(entries) == 1 && entries[0].IsDir() && !tools.IsExist(filepath.Join(dir, templateConfig)) {
+		dir = filepath.Join(dir, entries[0].Name())
+	}
+	base := path.Base(filepath.ToSlash(name))
+	t, err := readTemplate(os.DirFS(dir), strings.TrimSuffix(strings.TrimSuffix(base, ".tgz"), ".tar.gz"), name)
+	if err != nil {
+		os.RemoveAll(temp)
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	t.temp = temp
+	return t, nil
+}
+
+// 释放 tar.gz, 拒绝跳出目标目录的路径
+func extractTarGz(r io.Reader, targetPath string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		target, err := safeJoin(targetPath, header.Name)
+		if err != nil {
+			return fmt.Errorf("illegal file path in archive: %s", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fs.FileMode(header.Mode)&0755|0644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// 路径拼接目标目录, 拒绝绝对路径和跳出目标目录的路径
+func safeJoin(dir, name string) (string, error) {
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) || strings.HasPrefix(name, string(filepath.Separator)) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("absolute path %s", name)
+	}
+	target := filepath.Join(dir, name)
+	if rel, err := filepath.Rel(dir, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s outside %s", name, dir)
+	}
+	return target, nil
+}
+
+// Close 删除压缩包模板的临时目录
+func (m *Template) Close() {
+	if m.temp != "" {
+		os.RemoveAll(m.temp)
+		m.temp = ""
+	}
+}
+
+// Data 模板变量, 变量默认值使用 data 渲染, vars 覆盖默认值
+func (m *Template) Data(data map[string]any, vars map[string]string) (map[string]any, error) {
+	result := make(map[string]any, len(data)+len(m.Variables)+len(vars))
+	for k, v := range data {
+		result[k] = v
+	}
+	for _, v := range m.Variables {
+		if value, ok := vars[v.Name]; ok {
+			result[v.Name] = value
+			continue
+		}
+		value, err := tools.RenderTemplate(v.Default, result)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", v.Name, err)
+		}
+		result[v.Name] = string(value)
+	}
+	for k, v := range vars {
+		if _, ok := result[k]; !ok {
+			result[k] = v
+		}
+	}
+	return result, nil
+}
+
+// Generate 生成模板文件到项目目录, 返回生成的文件, 相对项目目录
+//  rename 的项目路径不能是绝对路径或项目目录之外的路径
+func (m *Template) Generate(projectPath string, data map[string]any) ([]string, error) {
+	for from, to := range m.Rename {
+		if _, err := safeJoin(projectPath, to); err != nil {
+			return nil, fmt.Errorf("%s: rename %s: illegal %w", templateConfig, from, err)
+		}
+	}
+	var files []string
+	err := fs.WalkDir(m.fs, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filePath == templateConfig {
+			return nil
+		}
+		content, err := fs.ReadFile(m.fs, filePath)
+		if err != nil {
+			return err
+		}
+		target := filePath
+		if rename, ok := m.Rename[filePath]; ok {
+			target = rename
+		}
+		if strings.HasSuffix(target, templateExt) {
+			target = strings.TrimSuffix(target, templateExt)
+			if content, err = tools.RenderTemplate(string(content), data); err != nil {
+				return fmt.Errorf("render %s: %w", filePath, err)
+			}
+		}
+		outPath, err := safeJoin(projectPath, target)
+		if err != nil {
+			return fmt.Errorf("%s: illegal %w", filePath, err)
+		}
+		if err = os.MkdirAll(filepath.Dir(outPath), fs.ModePerm); err != nil {
+			return err
+		}
+		if err = os.WriteFile(outPath, content, 0666); err != nil {
+			return err
+		}
+		files = append(files, target)
+		return nil
+	})
+	return files, err
+}
+
+// Builtin 是否为内置模板, 内置模板的命令直接执行, 其它模板的命令需要确认
+func (m *Template) Builtin() bool {
+	return m.Source == builtinSource
+}
+
+// HookCommands 生成后执行的命令行, 用于执行前确认
+func (m *Template) HookCommands(data map[string]any) ([]string, error) {
+	var result []string
+	for _, hook := range m.Hooks {
+		if len(hook.Command) == 0 {
+			continue
+		}
+		args, err := hook.args(data)
+		if err != nil {
+			return nil, err
+		}
+		line := strings.Join(args, " ")
+		if hook.Dir != "" {
+			line = "(" + hook.Dir + ") " + line
+		}
+		result = append(result, line)
+	}
+	return result, nil
+}
+
+// 渲染模板变量后的命令参数
+func (m *Hook) args(data map[string]any) ([]string, error) {
+	args := make([]string, len(m.Command))
+	for i, arg := range m.Command {
+		value, err := tools.RenderTemplate(arg, data)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = string(value)
+	}
+	return args, nil
+}
+
+// RunHooks 执行生成后命令, 失败时返回错误, 项目文件已生成
+func (m *Template) RunHooks(projectPath string, data map[string]any) error {
+	for _, hook := range m.Hooks {
+		if len(hook.Command) == 0 {
+			continue
+		}
+		if hook.Requires != "" && !tools.CommandExists(hook.Requires) {
+			term.Logger.Warn(fmt.Sprintf("%s not installed, skip: %s", hook.Requires, hook.Name))
+			continue
+		}
+		args, err := hook.args(data)
+		if err != nil {
+			return err
+		}
+		term.Logger.Info(hook.Name, term.Logger.Args("command-line", strings.Join(args, " "), "dir", hook.Dir))
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = filepath.Join(projectPath, filepath.FromSlash(hook.Dir))
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", hook.Name, err)
+		}
+	}
+	return nil
+}
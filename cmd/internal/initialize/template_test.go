@@ -0,0 +1,175 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package initialize
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	templates, err := Templates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	if names[0] != DefaultTemplate {
+		t.Errorf("first template %s, want %s", names[0], DefaultTemplate)
+	}
+	for _, name := range []string{"vue", "react", "svelte"} {
+		if !strings.Contains(strings.Join(names, ","), name) {
+			t.Errorf("template %s not found in %v", name, names)
+		}
+	}
+	for _, tc := range []struct {
+		template, resLoad string
+		want              []string
+	}{
+		{"plain", "1", []string{"assetserve.NewAssetsHttpServer", `Title = "My App"`}},
+		{"plain", "2", []string{"cef.LocalLoadConfig", `ResRootDir: "resources"`}},
+		{"vue", "2", []string{"//go:embed frontend/dist", `ResRootDir: "frontend/dist"`}},
+		{"react", "2", []string{`ResRootDir: "frontend/dist"`}},
+		{"svelte", "2", []string{`ResRootDir: "frontend/dist"`}},
+	} {
+		tmpl, err := LoadTemplate(tc.template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := tmpl.Data(map[string]any{"Name": "demo", "ResLoad": tc.resLoad}, map[string]string{"Title": "My App"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		if _, err = tmpl.Generate(dir, data); err != nil {
+			t.Fatal(err)
+		}
+		mainGo, err := os.ReadFile(filepath.Join(dir, "main.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = parser.ParseFile(token.NewFileSet(), "main.go", mainGo, parser.AllErrors); err != nil {
+			t.Errorf("%s %s: %v", tc.template, tc.resLoad, err)
+		}
+		for _, want := range tc.want {
+			if !bytes.Contains(mainGo, []byte(want)) {
+				t.Errorf("%s %s: main.go does not contain %s", tc.template, tc.resLoad, want)
+			}
+		}
+		if tc.template != "plain" {
+			for _, file := range []string{"frontend/.gitignore", "frontend/index.html", "frontend/dist/index.html", "frontend/src/ipc.js"} {
+				if _, err = os.Stat(filepath.Join(dir, file)); err != nil {
+					t.Errorf("%s: %v", tc.template, err)
+				}
+			}
+			if index, _ := os.ReadFile(filepath.Join(dir, "frontend", "index.html")); !bytes.Contains(index, []byte("<title>My App</title>")) {
+				t.Errorf("%s: title not rendered\n%s", tc.template, index)
+			}
+		}
+	}
+}
+
+func tarGz(t *testing.T, files map[string]string) string {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gw.Close()
+	file := filepath.Join(t.TempDir(), "my-template.tar.gz")
+	if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestTarballTemplate(t *testing.T) {
+	file := tarGz(t, map[string]string{
+		"my-template-main/template.json":    `{"variables": [{"name": "Greeting", "default": "hello {{.Name}}"}], "hooks": [{"name": "install", "dir": "frontend", "command": ["npm", "install", "{{.Name}}"]}]}`,
+		"my-template-main/main.go.tmpl":     "package main\n\n// {{.Greeting}}\n",
+		"my-template-main/assets/style.css": "body {}\n",
+	})
+	tmpl, err := LoadTemplate(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Name != "my-template" {
+		t.Errorf("name %s", tmpl.Name)
+	}
+	data, err := tmpl.Data(map[string]any{"Name": "demo"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 压缩包模板的命令需要确认
+	if commands, err := tmpl.HookCommands(data); tmpl.Builtin() || err != nil || strings.Join(commands, ",") != "(frontend) npm install demo" {
+		t.Errorf("hook commands %v %v", commands, err)
+	}
+	dir := t.TempDir()
+	files, err := tmpl.Generate(dir, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(files, ",") != "assets/style.css,main.go" {
+		t.Errorf("files %v", files)
+	}
+	if mainGo, _ := os.ReadFile(filepath.Join(dir, "main.go")); !strings.Contains(string(mainGo), "// hello demo") {
+		t.Errorf("main.go\n%s", mainGo)
+	}
+	temp := tmpl.temp
+	tmpl.Close()
+	if _, err = os.Stat(temp); !os.IsNotExist(err) {
+		t.Errorf("temp dir not removed: %v", err)
+	}
+
+	if _, err = LoadTemplate(tarGz(t, map[string]string{"../evil": "x"})); err == nil || !strings.Contains(err.Error(), "illegal file path") {
+		t.Errorf("expected illegal file path error, got %v", err)
+	}
+
+	// rename 不能写入项目目录之外
+	for _, rename := range []string{"../../.bashrc", "sub/../../x", "/tmp/energy-rename"} {
+		tmpl, err = LoadTemplate(tarGz(t, map[string]string{
+			"evil/template.json": fmt.Sprintf(`{"rename": {"x.txt": %q}}`, rename),
+			"evil/x.txt":         "x",
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		project := filepath.Join(t.TempDir(), "a", "b")
+		if _, err = tmpl.Generate(project, nil); err == nil || !strings.Contains(err.Error(), "illegal") {
+			t.Errorf("rename %s: expected illegal path error, got %v", rename, err)
+		}
+		tmpl.Close()
+		if _, err = os.Stat(filepath.Join(project, "..", "..", ".bashrc")); err == nil {
+			t.Fatalf("rename %s: file written outside project", rename)
+		}
+	}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	if _, err = LoadTemplate(server.URL + "/template.tar.gz"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected 404 error, got %v", err)
+	}
+}
@@ -16,12 +16,13 @@ import (
 )
 
 var CmdInstall = &command.Command{
-	UsageLine: "install -p [path] -v [version] -n [name] -d [download] -os -arch -cef",
+	UsageLine: "install -p [path] -v [version] -n [name] -d [download] -os -arch -cef --mirror --from-archive --verify-signature --retries --proxy",
 	Short:     "Automatic installation and configuration of the energy framework complete development environment",
 	Long: `
 	-p Installation directory Default current directory
 	-v Specifying a version number,Default latest
 	-n Name of the framework directory after installation, Default EnergyFramework
+		each version is installed to [path]/energy/[name]-[version]-cef[major][-os-arch]
 	-d Download Source, 0:gitee or 1:github, Default empty
 	-os Specify install OS: [windows, linux, darwin], default current system: os
 	-arch Specify install ARCH: [386, amd64, arm64], Default current system: architecture
@@ -30,6 +31,16 @@ var CmdInstall = &command.Command{
 		109 : CEF 109.1.18 is the last one to support Windows 7, windows version < 10
 		106 : CEF 106.1.1 is the last default support for GTK2 in Linux.
 		87  : CEF 87.1.14 is the last one to support Flash.
+	--mirror Offline install from a local directory or http(s) mirror
+		containing edv.json, extract.json and the installation packages by download file name
+	--from-archive Offline install from a bundle file created by: energy bundle
+	--verify-signature Require a valid ed25519 signature for each installation package,
+		public key: publicKey in ~/.energy/energy.json
+	--retries Download retries on failure, default 3, or retries in ~/.energy/energy.json
+	--proxy Download proxy, default proxy in ~/.energy/energy.json or HTTP_PROXY, HTTPS_PROXY environment
+	Installation packages are verified with the sha256 checksums of the version config when configured.
+	Signed installation packages require the public key, installation fails without it.
+	Interrupted downloads are resumed from the [name].download file in the download cache.
 	.  Execute command
 
 Auto installation and configuration of the energy framework complete development environment.
@@ -0,0 +1,199 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package install
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"github.com/pterm/pterm"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Bundle 创建离线安装包
+//  在联网机器下载版本配置、提取配置和安装包, 打包为 tar.gz
+//  离线机器安装: energy install --from-archive [bundle]
+func Bundle(c *command.Config) error {
+	// 使用安装配置解析下载地址
+	ic := *c
+	ic.Install = command.Install{
+		Path:     c.Wd,
+		Version:  c.Bundle.Version,
+		Download: c.Bundle.Download,
+		OS:       c.Bundle.OS,
+		Arch:     c.Bundle.Arch,
+		CEF:      c.Bundle.CEF,
+	}
+	defaultInstallConfig(&ic)
+	cacheDir := filepath.Join(ic.Install.Path, consts.FrameworkCache)
+	if err := os.MkdirAll(cacheDir, fs.ModePerm); err != nil {
+		return err
+	}
+	term.Section.Println("Bundle", ic.Install.OS, ic.Install.Arch, "cache", cacheDir)
+
+	// 版本配置和提取配置
+	extractData, err := requestConfig(&ic, consts.DownloadExtractURL, MirrorExtractFile)
+	if err != nil {
+		return err
+	}
+	versionData, err := requestConfig(&ic, consts.DownloadVersionURL, MirrorVersionFile)
+	if err != nil {
+		return err
+	}
+	var edv map[string]any
+	if err = json.Unmarshal(versionData, &edv); err != nil {
+		return err
+	}
+	if ic.Install.Version == "latest" {
+		ic.Install.Version = tools.ToString(edv["latest"])
+	}
+	loadChecksums(&ic, edv)
+	info := &BundleInfo{Version: ic.Install.Version, OS: string(ic.Install.OS), Arch: string(ic.Install.Arch), CEF: ic.Install.CEF, Checksums: make(map[string]string)}
+
+	// 下载列表, 文件名 => 下载地址
+	var files = make(map[string]string)
+	downloads, _, err := cefFrameworkDownloads(&ic, edv)
+	if err != nil {
+		return err
+	}
+	for key, dl := range downloads {
+		if !dl.isSupport {
+			term.Logger.Warn("Warn module is not built or configured [" + dl.module + "]")
+			continue
+		}
+		term.Section.Println("Bundle", key, ":", dl.fileName)
+		files[dl.fileName] = dl.url
+	}
+	if c.Bundle.Golang {
+		fileName := golangFileName(consts.GolangDefaultVersion, string(ic.Install.OS), string(ic.Install.Arch))
+		files[fileName] = golangDownloadURL(&ic, fileName)
+	}
+	if ic.Install.OS.IsWindows() {
+		if c.Bundle.NSIS {
+			fileName := fmt.Sprintf("nsis.windows.386-%s.zip", consts.NSISDownloadVersion)
+			files[fileName] = fmt.Sprintf(consts.NSISDownloadURL, fileName)
+			fileName = fmt.Sprintf("nsis7z.windows.386-%s.zip", consts.NSIS7zDownloadVersion)
+			files[fileName] = fmt.Sprintf(consts.NSIS7zDownloadURL, fileName)
+		}
+		if c.Bundle.Z7za {
+			fileName := fmt.Sprintf("7za.windows.all-%s.zip", consts.Z7ZDownloadVersion)
+			files[fileName] = fmt.Sprintf(consts.Z7ZDownloadURL, fileName)
+		}
+	} else if c.Bundle.NSIS || c.Bundle.Z7za {
+		term.Logger.Warn("NSIS and 7za are windows only, skipping")
+	}
+	if len(files) == 0 {
+		return errors.New("no installation package to bundle")
+	}
+	var tasks []*downloadTask
+	for fileName, url := range files {
+		tasks = append(tasks, &downloadTask{name: fileName, url: url, path: filepath.Join(cacheDir, fileName)})
+	}
+	pterm.Println()
+	if err = downloadFiles(&ic, tasks); err != nil {
+		return err
+	}
+	for fileName := range files {
+		savePath := filepath.Join(cacheDir, fileName)
+		if err = verifyChecksum(&ic, savePath); err != nil {
+			return err
+		}
+		sum, err := fileSHA256(savePath)
+		if err != nil {
+			return err
+		}
+		info.Files = append(info.Files, fileName)
+		info.Checksums[fileName] = hex.EncodeToString(sum)
+	}
+	infoData, err := json.MarshalIndent(info, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	out := c.Bundle.Out
+	if out == "" {
+		out = filepath.Join(c.Wd, fmt.Sprintf("energy-bundle-%s-%s-%s.tar.gz", info.Version, info.OS, info.Arch))
+	}
+	term.Section.Println("Write bundle", out)
+	if err = writeBundle(out, cacheDir, info.Files, map[string][]byte{
+		MirrorVersionFile: versionData,
+		MirrorExtractFile: extractData,
+		MirrorBundleFile:  infoData,
+	}); err != nil {
+		return err
+	}
+	term.Logger.Info("Bundle Created Successfully", term.Logger.Args("Version", info.Version, "File", out))
+	term.Section.Println("Offline install: energy install --from-archive", filepath.Base(out), ".")
+	return nil
+}
+
+// 写入 bundle, 所有文件在根目录
+func writeBundle(out, cacheDir string, files []string, configs map[string][]byte) error {
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for name, data := range configs {
+		if err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		if _, err = tw.Write(data); err != nil {
+			return err
+		}
+	}
+	for _, name := range files {
+		if err = writeBundleFile(tw, filepath.Join(cacheDir, name)); err != nil {
+			return err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	if err = gw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeBundleFile(tw *tar.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(stat, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.Base(path)
+	if err = tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, file)
+	return err
+}
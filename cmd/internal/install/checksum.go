@@ -0,0 +1,184 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package install
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 安装包校验
+//  版本配置 (edv.json) 中的 sha256 和签名, 文件名 => 值
+//   {
+//     "checksums": {"go1.18.10.linux-amd64.tar.gz": "sha256 hex"},
+//     "signatures": {"go1.18.10.linux-amd64.tar.gz": "base64 ed25519 signature"},
+//     "versionList": {"v2.3.0": {"modules": {"cef": {"checksums": {...}, "signatures": {...}}}}}
+//   }
+//  签名: ed25519 对文件 sha256 摘要 (32字节) 签名
+//  公钥: ~/.energy/energy.json publicKey 或 consts.SignaturePublicKey
+
+// ChecksumError 安装包 sha256 不一致
+type ChecksumError struct {
+	File     string
+	Expected string
+	Actual   string
+}
+
+func (m *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch %s\n\texpected sha256: %s\n\tactual   sha256: %s", m.File, m.Expected, m.Actual)
+}
+
+// SignatureError 安装包签名验证失败
+type SignatureError struct {
+	File   string
+	Reason string
+}
+
+func (m *SignatureError) Error() string {
+	return fmt.Sprintf("signature verification failed %s: %s", m.File, m.Reason)
+}
+
+// 从版本配置读取 checksums 和 signatures
+func loadChecksums(c *command.Config, edv map[string]any) {
+	if c.Install.Checksums == nil {
+		c.Install.Checksums = make(map[string]string)
+	}
+	if c.Install.Signatures == nil {
+		c.Install.Signatures = make(map[string]string)
+	}
+	var load = func(config map[string]any) {
+		if sums, ok := config["checksums"].(map[string]any); ok {
+			for name, sum := range sums {
+				c.Install.Checksums[name] = strings.ToLower(strings.TrimSpace(tools.ToString(sum)))
+			}
+		}
+		if signs, ok := config["signatures"].(map[string]any); ok {
+			for name, sign := range signs {
+				c.Install.Signatures[name] = strings.TrimSpace(tools.ToString(sign))
+			}
+		}
+	}
+	load(edv)
+	if versionList, ok := edv["versionList"].(map[string]any); ok {
+		for _, version := range versionList {
+			if version, ok := version.(map[string]any); ok {
+				if modules, ok := version["modules"].(map[string]any); ok {
+					for _, module := range modules {
+						if module, ok := module.(map[string]any); ok {
+							load(module)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// 获取版本配置中的校验值, 获取失败时不校验
+func requestChecksums(c *command.Config) {
+	edv, err := requestJSONConfig(c, consts.DownloadVersionURL, MirrorVersionFile)
+	if err != nil {
+		term.Logger.Warn("Checksums unavailable: " + err.Error())
+		return
+	}
+	loadChecksums(c, edv)
+}
+
+// 文件 sha256
+func fileSHA256(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// 签名公钥
+func signaturePublicKey(c *command.Config) (ed25519.PublicKey, error) {
+	key := strings.TrimSpace(c.EnergyCfg.PublicKey)
+	if key == "" {
+		key = consts.SignaturePublicKey
+	}
+	if key == "" {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(data) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+	return data, nil
+}
+
+// 校验安装包 sha256 和签名
+//  不一致时删除文件并返回错误, 配置中没有校验值时仅提示
+//  配置了签名时必须有公钥并验证通过, 没有公钥时返回错误
+//  --verify-signature: 必须有签名并验证通过
+func verifyChecksum(c *command.Config, path string) error {
+	name := filepath.Base(path)
+	expected := c.Install.Checksums[name]
+	sign := c.Install.Signatures[name]
+	if expected == "" && sign == "" && !c.Install.VerifySignature {
+		term.Logger.Warn("No checksum configured, skipping verification [" + name + "]")
+		return nil
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	actual := hex.EncodeToString(sum)
+	if expected != "" && expected != actual {
+		os.Remove(path)
+		return &ChecksumError{File: name, Expected: expected, Actual: actual}
+	}
+	if sign != "" || c.Install.VerifySignature {
+		key, err := signaturePublicKey(c)
+		if err != nil {
+			return &SignatureError{File: name, Reason: err.Error()}
+		}
+		if key == nil {
+			return &SignatureError{File: name, Reason: "public key not configured, set publicKey in ~/.energy/energy.json"}
+		} else if sign == "" {
+			return &SignatureError{File: name, Reason: "signature not configured"}
+		} else if signature, err := base64.StdEncoding.DecodeString(sign); err != nil || !ed25519.Verify(key, sum, signature) {
+			os.Remove(path)
+			return &SignatureError{File: name, Reason: "invalid signature"}
+		}
+	}
+	term.Logger.Info("Verified ["+name+"]", term.Logger.Args("sha256", actual))
+	return nil
+}
+
+// 压缩包内文件路径拼接目标目录, 拒绝跳出目标目录的路径 (zip-slip)
+func safeJoin(targetPath, name string) (string, error) {
+	target := filepath.Join(targetPath, filepath.FromSlash(strings.ReplaceAll(name, "\\", "/")))
+	rel, err := filepath.Rel(targetPath, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	return target, nil
+}
@@ -0,0 +1,71 @@
+package install
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "liblcl.zip")
+	data := []byte("liblcl")
+	sum := sha256.Sum256(data)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	c := &command.Config{}
+	c.EnergyCfg.PublicKey = base64.StdEncoding.EncodeToString(pub)
+	loadChecksums(c, map[string]any{
+		"versionList": map[string]any{"v2.3.0": map[string]any{"modules": map[string]any{"liblcl": map[string]any{
+			"checksums":  map[string]any{"liblcl.zip": hex.EncodeToString(sum[:])},
+			"signatures": map[string]any{"liblcl.zip": base64.StdEncoding.EncodeToString(ed25519.Sign(priv, sum[:]))},
+		}}}},
+	})
+	os.WriteFile(file, data, 0644)
+	c.Install.VerifySignature = true
+	if err := verifyChecksum(c, file); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(file, []byte("tampered"), 0644)
+	err := verifyChecksum(c, file)
+	if _, ok := err.(*ChecksumError); !ok {
+		t.Fatal("expected checksum error", err)
+	}
+	if _, err = os.Stat(file); !os.IsNotExist(err) {
+		t.Fatal("mismatched file should be removed")
+	}
+	os.WriteFile(filepath.Join(dir, "go.zip"), data, 0644)
+	if _, ok := verifyChecksum(c, filepath.Join(dir, "go.zip")).(*SignatureError); !ok {
+		t.Fatal("expected signature error")
+	}
+	// 配置了签名但没有公钥时不通过
+	os.WriteFile(file, data, 0644)
+	c.EnergyCfg.PublicKey = ""
+	c.Install.VerifySignature = false
+	if _, ok := verifyChecksum(c, file).(*SignatureError); !ok {
+		t.Fatal("expected signature error without public key")
+	}
+}
+
+func TestExtractZipSlip(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.zip")
+	f, _ := os.Create(archive)
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("root/../../evil.txt")
+	w.Write([]byte("evil"))
+	zw.Close()
+	f.Close()
+	target := filepath.Join(dir, "target")
+	if err := ExtractUnZip(archive, target, false); err == nil {
+		t.Fatal("expected illegal path error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.txt")); !os.IsNotExist(err) {
+		t.Fatal("file written outside target")
+	}
+}
@@ -0,0 +1,417 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/pterm/pterm"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetries  = 3                // 默认失败重试次数
+	defaultBackoff  = time.Second      // 首次重试等待时间, 每次翻倍
+	maxBackoff      = 30 * time.Second // 最大重试等待时间
+	requestTimeout  = 60 * time.Second // 配置请求超时时间
+	downloadTempExt = ".download"      // 下载中临时文件, 重新下载时从已下载位置继续
+)
+
+// Downloader 文件下载
+//  断点续传: 临时文件 [name].download 存在时使用 HTTP Range 继续下载
+//  失败重试: 网络错误和 5xx 按 Backoff 递增等待后重试
+type Downloader struct {
+	Client  *http.Client
+	Retries int           // 失败重试次数
+	Backoff time.Duration // 首次重试等待时间
+}
+
+// HTTPStatusError 下载请求状态码错误
+type HTTPStatusError struct {
+	URL    string
+	Status string
+	Code   int
+}
+
+func (m *HTTPStatusError) Error() string {
+	return fmt.Sprintf("download %s: %s", m.URL, m.Status)
+}
+
+// 是否可以重试, 4xx 不重试 (408 请求超时, 429 请求过多除外)
+func (m *HTTPStatusError) retryable() bool {
+	return m.Code >= 500 || m.Code == http.StatusRequestTimeout || m.Code == http.StatusTooManyRequests
+}
+
+// NewDownloader 创建下载器
+//  proxy: 代理地址, 空时使用环境变量 HTTP_PROXY, HTTPS_PROXY, NO_PROXY
+//  retries: 失败重试次数, <= 0 时使用默认值
+func NewDownloader(proxy string, retries int) (*Downloader, error) {
+	proxyFunc := http.ProxyFromEnvironment
+	if proxy = strings.TrimSpace(proxy); proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %s: %v", proxy, err)
+		}
+		proxyFunc = http.ProxyURL(proxyURL)
+	}
+	if retries <= 0 {
+		retries = defaultRetries
+	}
+	transport := &http.Transport{
+		Proxy: proxyFunc,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   30 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	return &Downloader{Client: &http.Client{Transport: transport}, Retries: retries, Backoff: defaultBackoff}, nil
+}
+
+// 根据命令行参数和 energy.json 创建下载器
+func newDownloader(c *command.Config) *Downloader {
+	retries := c.Install.Retries
+	if retries <= 0 {
+		retries = c.EnergyCfg.Retries
+	}
+	proxy := c.Install.Proxy
+	if proxy == "" {
+		proxy = c.EnergyCfg.Proxy
+	}
+	d, err := NewDownloader(proxy, retries)
+	if err != nil {
+		term.Logger.Warn(err.Error() + ", using environment proxy")
+		d, _ = NewDownloader("", retries)
+	}
+	return d
+}
+
+// Get 请求配置内容, 超时 requestTimeout, 非 2xx 返回 *HTTPStatusError, 失败时重试
+func (m *Downloader) Get(requestUrl string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	for attempt := 0; ; attempt++ {
+		if data, err = m.get(requestUrl); err == nil {
+			return data, nil
+		}
+		var statusErr *HTTPStatusError
+		if errors.As(err, &statusErr) && !statusErr.retryable() {
+			return nil, err
+		}
+		if attempt >= m.Retries {
+			return nil, err
+		}
+		wait := m.backoff(attempt)
+		term.Logger.Warn(fmt.Sprintf("Request failed: %v. Retry in %v", err, wait), term.Logger.Args("count", fmt.Sprintf("%d/%d", attempt+1, m.Retries)))
+		time.Sleep(wait)
+	}
+}
+
+// 请求一次
+func (m *Downloader) get(requestUrl string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &HTTPStatusError{URL: requestUrl, Status: resp.Status, Code: resp.StatusCode}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// 第 attempt 次重试的等待时间
+func (m *Downloader) backoff(attempt int) time.Duration {
+	wait := m.Backoff << uint(attempt)
+	if wait > maxBackoff || wait <= 0 {
+		wait = maxBackoff
+	}
+	return wait
+}
+
+// Download 下载文件, 失败时重试并从已下载位置继续
+//  如果文件存在大小一样不再下载
+func (m *Downloader) Download(downloadUrl, localPath string, callback func(totalLength, processLength int64)) error {
+	if info, err := os.Stat(localPath); err == nil {
+		if size, err := m.contentLength(downloadUrl); err == nil && size == info.Size() {
+			term.Section.Println("File already exists")
+			return nil
+		}
+		os.Remove(localPath)
+	}
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = m.download(downloadUrl, localPath, callback); err == nil {
+			return nil
+		}
+		var statusErr *HTTPStatusError
+		if errors.As(err, &statusErr) && !statusErr.retryable() {
+			return err
+		}
+		if attempt >= m.Retries {
+			return err
+		}
+		wait := m.backoff(attempt)
+		term.Logger.Warn(fmt.Sprintf("Download failed: %v. Retry in %v", err, wait), term.Logger.Args("count", fmt.Sprintf("%d/%d", attempt+1, m.Retries)))
+		time.Sleep(wait)
+	}
+}
+
+// 服务端文件大小, 获取失败返回 error
+func (m *Downloader) contentLength(downloadUrl string) (int64, error) {
+	resp, err := m.Client.Head(downloadUrl)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 {
+		return 0, errors.New("content length unavailable")
+	}
+	return resp.ContentLength, nil
+}
+
+// 下载一次, 临时文件存在时从文件末尾继续
+func (m *Downloader) download(downloadUrl, localPath string, callback func(totalLength, processLength int64)) error {
+	tmpFilePath := localPath + downloadTempExt
+	var offset int64
+	if info, err := os.Stat(tmpFilePath); err == nil {
+		offset = info.Size()
+	}
+	req, err := http.NewRequest(http.MethodGet, downloadUrl, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var (
+		total = resp.ContentLength
+		flag  = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// 不支持 Range, 重新下载
+		offset = 0
+	case http.StatusPartialContent:
+		flag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		total = contentRangeTotal(resp.Header.Get("Content-Range"), offset, resp.ContentLength)
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 临时文件已完整
+		if offset > 0 && contentRangeTotal(resp.Header.Get("Content-Range"), 0, -1) == offset {
+			return os.Rename(tmpFilePath, localPath)
+		}
+		os.Remove(tmpFilePath)
+		return &HTTPStatusError{URL: downloadUrl, Status: resp.Status, Code: http.StatusServiceUnavailable}
+	default:
+		return &HTTPStatusError{URL: downloadUrl, Status: resp.Status, Code: resp.StatusCode}
+	}
+	file, err := os.OpenFile(tmpFilePath, flag, 0644)
+	if err != nil {
+		return err
+	}
+	written := offset
+	buf := make([]byte, 1024*32)
+	for {
+		nr, er := resp.Body.Read(buf)
+		if nr > 0 {
+			nw, ew := file.Write(buf[0:nr])
+			written += int64(nw)
+			if callback != nil {
+				callback(total, written)
+			}
+			if ew != nil {
+				err = ew
+				break
+			}
+		}
+		if er != nil {
+			if er != io.EOF {
+				err = er
+			}
+			break
+		}
+	}
+	if ec := file.Close(); err == nil {
+		err = ec
+	}
+	if err != nil {
+		return err
+	}
+	if total > 0 && written != total {
+		return fmt.Errorf("download %s: incomplete, %d of %d bytes", downloadUrl, written, total)
+	}
+	return os.Rename(tmpFilePath, localPath)
+}
+
+// Content-Range: bytes 100-199/1000 => 1000, 无法解析时 offset+length
+func contentRangeTotal(contentRange string, offset, length int64) int64 {
+	if idx := strings.LastIndex(contentRange, "/"); idx != -1 {
+		if total, err := strconv.ParseInt(strings.TrimSpace(contentRange[idx+1:]), 10, 64); err == nil {
+			return total
+		}
+	}
+	if length < 0 {
+		return -1
+	}
+	return offset + length
+}
+
+// DownloadFile 下载文件
+//  如果文件存在大小一样不再下载
+//  callback 为空时显示下载进度条
+func DownloadFile(url string, localPath string, callback func(totalLength, processLength int64)) error {
+	d, _ := NewDownloader("", 0)
+	return downloadWithProgress(d, url, localPath, callback)
+}
+
+func downloadWithProgress(d *Downloader, url string, localPath string, callback func(totalLength, processLength int64)) error {
+	if callback != nil {
+		return d.Download(url, localPath, callback)
+	}
+	_, fileName := filepath.Split(localPath)
+	p, err := pterm.DefaultProgressbar.WithMaxWidth(80).WithTotal(100).WithTitle("Download " + fileName).Start()
+	if err != nil {
+		return err
+	}
+	defer p.Stop()
+	return d.Download(url, localPath, percentProgress(p))
+}
+
+// 进度条按百分比更新
+func percentProgress(p *pterm.ProgressbarPrinter) func(totalLength, processLength int64) {
+	return func(totalLength, processLength int64) {
+		if totalLength <= 0 {
+			return
+		}
+		process := int(float64(processLength) / float64(totalLength) * 100)
+		if process > p.Total {
+			process = p.Total
+		}
+		if process > p.Current {
+			p.Add(process - p.Current)
+		}
+	}
+}
+
+// 下载任务
+type downloadTask struct {
+	name string // 显示名称
+	url  string // 在线下载地址
+	path string // 保存路径
+	err  error  // 下载失败的错误
+}
+
+// DownloadError 并行下载失败的文件
+type DownloadError struct {
+	Errors map[string]error // 文件名 => 错误
+}
+
+func (m *DownloadError) Error() string {
+	var msg []string
+	for name, err := range m.Errors {
+		msg = append(msg, fmt.Sprintf("[%s] %v", name, err))
+	}
+	return "download failed " + strings.Join(msg, "; ")
+}
+
+// 并行下载, 所有下载进度显示在一起
+//  配置本地镜像时直接复制
+func downloadFiles(c *command.Config, tasks []*downloadTask) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	var (
+		remote []*downloadTask
+		errs   = make(map[string]error)
+		lock   sync.Mutex
+	)
+	for _, task := range tasks {
+		if c.Install.Mirror != "" && !isRemoteMirror(c.Install.Mirror) {
+			if err := downloadFile(c, task.url, task.path); err != nil {
+				task.err = err
+				errs[task.name] = err
+			}
+			continue
+		}
+		if c.Install.Mirror != "" {
+			task.url = strings.TrimSuffix(c.Install.Mirror, "/") + "/" + urlName(task.url)
+		}
+		term.Logger.Info("Download " + task.name + ": " + task.url)
+		remote = append(remote, task)
+	}
+	if len(remote) == 1 {
+		if err := downloadWithProgress(newDownloader(c), remote[0].url, remote[0].path, nil); err != nil {
+			remote[0].err = err
+			errs[remote[0].name] = err
+		}
+	} else if len(remote) > 1 {
+		d := newDownloader(c)
+		multi := pterm.DefaultMultiPrinter
+		bars := make([]*pterm.ProgressbarPrinter, len(remote))
+		for i, task := range remote {
+			bar, err := pterm.DefaultProgressbar.WithMaxWidth(80).WithTotal(100).WithWriter(multi.NewWriter()).Start("Download " + task.name)
+			if err != nil {
+				return err
+			}
+			bars[i] = bar
+		}
+		multi.Start()
+		var wg sync.WaitGroup
+		for i, task := range remote {
+			wg.Add(1)
+			go func(task *downloadTask, bar *pterm.ProgressbarPrinter) {
+				defer wg.Done()
+				if err := d.Download(task.url, task.path, percentProgress(bar)); err != nil {
+					task.err = err
+					lock.Lock()
+					errs[task.name] = err
+					lock.Unlock()
+				} else if bar.Current < bar.Total {
+					bar.Add(bar.Total - bar.Current)
+				}
+				bar.Stop()
+			}(task, bars[i])
+		}
+		wg.Wait()
+		multi.Stop()
+	}
+	if len(errs) > 0 {
+		return &DownloadError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package install
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloaderResumeRetry(t *testing.T) {
+	content := bytes.Repeat([]byte("energy"), 10000)
+	var requests, ranges int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if strings.HasPrefix(r.Header.Get("Range"), "bytes=") {
+			atomic.AddInt32(&ranges, 1)
+		}
+		http.ServeContent(w, r, "liblcl.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "liblcl.zip")
+	// 已下载一部分
+	os.WriteFile(localPath+downloadTempExt, content[:1000], 0644)
+	d, err := NewDownloader("", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Backoff = time.Millisecond
+	if err = d.Download(server.URL+"/liblcl.zip", localPath, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(localPath)
+	if !bytes.Equal(data, content) {
+		t.Fatal("unexpected content length", len(data))
+	}
+	if atomic.LoadInt32(&requests) != 2 || atomic.LoadInt32(&ranges) != 1 {
+		t.Fatal("unexpected requests", requests, ranges)
+	}
+	if _, err = os.Stat(localPath + downloadTempExt); !os.IsNotExist(err) {
+		t.Fatal("temp file should be renamed")
+	}
+}
+
+func TestDownloaderNotFound(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	d, _ := NewDownloader("", 3)
+	d.Backoff = time.Millisecond
+	err := d.Download(server.URL+"/none.zip", filepath.Join(t.TempDir(), "none.zip"), nil)
+	if _, ok := err.(*HTTPStatusError); !ok || requests != 1 {
+		t.Fatal("expected a single 404 request", err, requests)
+	}
+}
+
+func TestContentRangeTotal(t *testing.T) {
+	if contentRangeTotal("bytes 100-199/3000000000", 100, 100) != 3000000000 {
+		t.Fatal("unexpected total")
+	}
+	if contentRangeTotal("", 100, 50) != 150 {
+		t.Fatal("unexpected fallback total")
+	}
+}
+
+func TestDownloaderGet(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/none.json":
+			http.NotFound(w, r)
+		case atomic.AddInt32(&requests, 1) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"latest":"2.5.0"}`))
+		}
+	}))
+	defer server.Close()
+	d, _ := NewDownloader("", 2)
+	d.Backoff = time.Millisecond
+	data, err := d.Get(server.URL + "/edv.json")
+	if err != nil || string(data) != `{"latest":"2.5.0"}` || requests != 2 {
+		t.Fatal("unexpected result", string(data), err, requests)
+	}
+	if _, err = d.Get(server.URL + "/none.json"); err == nil {
+		t.Fatal("expected status error")
+	} else if _, ok := err.(*HTTPStatusError); !ok {
+		t.Fatal("unexpected error", err)
+	}
+}
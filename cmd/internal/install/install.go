@@ -19,16 +19,16 @@ import (
 	"github.com/energye/energy/v2/cmd/internal/command"
 	"github.com/energye/energy/v2/cmd/internal/consts"
 	"github.com/energye/energy/v2/cmd/internal/env"
+	"github.com/energye/energy/v2/cmd/internal/framework"
 	"github.com/energye/energy/v2/cmd/internal/term"
 	"github.com/energye/energy/v2/cmd/internal/tools"
 	"github.com/pterm/pterm"
 	"io"
 	"io/fs"
-	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
 )
 
@@ -48,11 +48,48 @@ type softEnf struct {
 	yes       func()
 }
 
-func Install(c *command.Config) error {
+// Result 安装结果, --output json 输出
+type Result struct {
+	Path      string       `json:"path"`
+	Version   string       `json:"version"`
+	OS        string       `json:"os"`
+	Arch      string       `json:"arch"`
+	Software  []*Software  `json:"software"`         // 开发环境依赖软件, 安装前的状态
+	Installed []*Installed `json:"installed"`        // 本次安装的软件
+	Failed    []string     `json:"failed,omitempty"` // 安装失败的软件
+}
+
+// Installed 安装的软件和安装目录
+type Installed struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+func (m *Result) add(name string, selected bool, root string) {
+	if root != "" {
+		m.Installed = append(m.Installed, &Installed{Name: name, Path: root})
+	} else if selected {
+		m.Failed = append(m.Failed, name)
+	}
+}
+
+func Install(c *command.Config) (err error) {
+	defer func() {
+		err = exitError(err)
+	}()
 	// 设置默认参数
 	defaultInstallConfig(c)
+	result := &Result{Path: c.Install.Path, Version: c.Install.Version, OS: string(c.Install.OS), Arch: string(c.Install.Arch), Installed: []*Installed{}}
+	c.Result = result
+	// 离线安装包
+	if err := useBundleArchive(c); err != nil {
+		return err
+	}
 	// 检查环境
 	willInstall := checkInstallEnv(c)
+	for _, se := range willInstall {
+		result.Software = append(result.Software, &Software{Name: se.name, Desc: se.desc, Installed: se.installed})
+	}
 	var (
 		goRoot                      string
 		goSuccessCallback           func()
@@ -88,17 +125,21 @@ func Install(c *command.Config) error {
 		if err != nil {
 			return err
 		}
-		// 选择
+		// 选择, -y, --yes 安装所有未安装的软件
 		if len(options) > 0 {
-			printer := term.DefaultInteractiveMultiselect.WithOnInterruptFunc(func() {
-				os.Exit(1)
-			}).WithOptions(options)
-			printer.CheckmarkANSI()
-			printer.DefaultText = "Optional Installation"
-			printer.Filter = false
-			selectedOptions, err := printer.Show()
-			if err != nil {
+			selectedOptions := options
+			if interactive, err := term.Interactive(); err != nil {
 				return err
+			} else if interactive {
+				printer := term.DefaultInteractiveMultiselect.WithOnInterruptFunc(func() {
+					os.Exit(command.ExitInterrupt)
+				}).WithOptions(options)
+				printer.CheckmarkANSI()
+				printer.DefaultText = "Optional Installation"
+				printer.Filter = false
+				if selectedOptions, err = printer.Show(); err != nil {
+					return err
+				}
 			}
 			term.Section.Printfln("Selected : %s", pterm.Green(selectedOptions))
 			for _, option := range selectedOptions {
@@ -117,22 +158,32 @@ func Install(c *command.Config) error {
 			}
 		}
 	}
+	// 安装包校验值
+	if c.Install.IGolang || c.Install.ICEF || c.Install.INSIS || c.Install.I7za {
+		requestChecksums(c)
+	}
+	// 并行下载安装包
+	packages := downloadPackages(c)
+
 	// 安装Go开发环境
-	goRoot, goSuccessCallback = installGolang(c)
+	goRoot, goSuccessCallback = installGolang(c, packages.golang)
+	result.add("Golang", c.Install.IGolang, goRoot)
 	// 设置 go 环境变量
 	if goRoot != "" {
 		env.SetGoEnv(goRoot)
 	}
 
 	// 安装CEF二进制框架
-	cefFrameworkRoot, cefFrameworkSuccessCallback = installCEFFramework(c)
+	cefFrameworkRoot, cefFrameworkSuccessCallback = installCEFFramework(c, packages.cef)
+	result.add("CEF Framework", c.Install.ICEF, cefFrameworkRoot)
 	// 设置 energy cef 环境变量
 	if cefFrameworkRoot != "" && c.Install.IsSame {
 		env.SetEnergyHomeEnv(cefFrameworkRoot)
 	}
 
 	// 安装nsis安装包制作工具, 仅windows - amd64
-	nsisRoot, nsisSuccessCallback = installNSIS(c)
+	nsisRoot, nsisSuccessCallback = installNSIS(c, packages.nsis, packages.nsis7z)
+	result.add("NSIS", c.Install.INSIS, nsisRoot)
 	// 设置nsis环境变量
 	if nsisRoot != "" {
 		env.SetNSISEnv(nsisRoot)
@@ -140,13 +191,15 @@ func Install(c *command.Config) error {
 
 	// 安装upx, 内置, 仅windows, linux
 	upxRoot, upxSuccessCallback = installUPX(c)
+	result.add("UPX", c.Install.IUPX, upxRoot)
 	// 设置upx环境变量
 	if upxRoot != "" {
 		env.SetUPXEnv(upxRoot)
 	}
 
 	// 安装7za
-	z7zRoot, z7zSuccessCallback = install7z(c)
+	z7zRoot, z7zSuccessCallback = install7z(c, packages.z7z)
+	result.add("7za", c.Install.I7za, z7zRoot)
 	// 设置7za环境变量
 	if z7zRoot != "" {
 		env.Set7zaEnv(z7zRoot)
@@ -175,9 +228,86 @@ func Install(c *command.Config) error {
 	}
 	copyEnergyCMD(goRoot)
 
+	if len(result.Failed) > 0 {
+		if c.Install.Err != nil {
+			return fmt.Errorf("install failed: %s: %w", strings.Join(result.Failed, ", "), c.Install.Err)
+		}
+		return fmt.Errorf("install failed: %s", strings.Join(result.Failed, ", "))
+	}
 	return nil
 }
 
+// 选择安装的软件包
+type packages struct {
+	golang, nsis, nsis7z, z7z *downloadTask
+	cef                       *cefFramework
+}
+
+// 并行下载选择安装的 Golang, CEF, liblcl, NSIS, nsis7z, 7za 安装包
+//  已下载的 Golang, NSIS, 7za 安装包不再下载, 下载错误由各安装步骤输出
+func downloadPackages(c *command.Config) *packages {
+	var (
+		result = &packages{}
+		tasks  []*downloadTask
+	)
+	add := func(task *downloadTask) *downloadTask {
+		if !tools.IsExist(task.path) {
+			tasks = append(tasks, task)
+		}
+		return task
+	}
+	if c.Install.IGolang {
+		result.golang = add(golangTask(c))
+	}
+	if c.Install.INSIS {
+		result.nsis = add(nsisTask(c))
+		result.nsis7z = add(nsis7zTask(c))
+	}
+	if c.Install.I7za {
+		result.z7z = add(z7zTask(c))
+	}
+	if c.Install.ICEF {
+		pterm.Println()
+		term.Section.Println("Start downloading CEF and Energy dependency")
+		if result.cef = cefFrameworkTasks(c); result.cef != nil {
+			tasks = append(tasks, result.cef.tasks...)
+		}
+	}
+	if len(tasks) > 0 {
+		pterm.Println()
+		term.Section.Println("Download")
+		downloadFiles(c, tasks)
+	}
+	return result
+}
+
+// 记录安装错误, 第一个错误决定退出码
+func recordError(c *command.Config, err error) {
+	if c.Install.Err == nil {
+		c.Install.Err = err
+	}
+}
+
+// 按失败类型设置退出码, 见 command.ExitCode
+func exitError(err error) error {
+	var (
+		checksumErr  *ChecksumError
+		signatureErr *SignatureError
+		statusErr    *HTTPStatusError
+		downloadErr  *DownloadError
+		urlErr       *url.Error
+	)
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &checksumErr), errors.As(err, &signatureErr):
+		return command.WithCode(command.ExitVerify, err)
+	case errors.As(err, &statusErr), errors.As(err, &downloadErr), errors.As(err, &urlErr):
+		return command.WithCode(command.ExitNetwork, err)
+	}
+	return command.WithCode(command.ExitFailure, err)
+}
+
 func copyEnergyCMD(goRoot string) {
 	term.Logger.Info("Copy energy command-line to GOROOT/bin")
 	if goRoot == "" {
@@ -223,12 +353,24 @@ func copyEnergyCMD(goRoot string) {
 	}
 }
 
-func cefInstallPathName(c *command.Config) string {
-	if c.Install.IsSame {
-		return filepath.Join(c.Install.Path, consts.ENERGY, c.Install.Name)
-	} else {
-		return filepath.Join(c.Install.Path, consts.ENERGY, fmt.Sprintf("%s_%s%s", c.Install.Name, c.Install.OS, c.Install.Arch))
+// CEF 框架安装目录, 每个版本独立目录 [path]/energy/[name]-[framework]
+//  framework: 框架名称 版本-cef主版本号[-os-arch], 见 framework.NameOf
+func cefInstallPathName(c *command.Config, name string) string {
+	return filepath.Join(c.Install.Path, consts.ENERGY, c.Install.Name+"-"+name)
+}
+
+// 已安装的目标系统架构框架, 指定版本时版本相同
+func cefInstalled(c *command.Config) bool {
+	frameworks, _ := framework.List()
+	for _, f := range frameworks {
+		if f.OS != string(c.Install.OS) || f.Arch != string(c.Install.Arch) || !f.Exists() {
+			continue
+		}
+		if c.Install.Version == "" || c.Install.Version == "latest" || strings.TrimPrefix(f.Version, "v") == strings.TrimPrefix(c.Install.Version, "v") {
+			return true
+		}
 	}
+	return false
 }
 
 func goInstallPathName(c *command.Config) string {
@@ -289,19 +431,8 @@ func checkInstallEnv(c *command.Config) (result []*softEnf) {
 			// 检查环境变量是否配置
 			return "All", tools.CheckCEFDir()
 		}
-		// 非当前系统架构时检查一下目标安装路径是否已经存在
-		var lib = func() string {
-			if c.Install.OS.IsWindows() {
-				return "libcef.dll"
-			} else if c.Install.OS.IsLinux() {
-				return "libcef.so"
-			} else if c.Install.OS.IsDarwin() {
-				return "cef_sandbox.a"
-			}
-			return ""
-		}()
-		s := filepath.Join(cefInstallPathName(c), lib)
-		return "All", tools.IsExist(s)
+		// 非当前系统架构时检查是否已经安装目标系统架构的框架
+		return "All", cefInstalled(c)
 	}, cefName, func() {
 		c.Install.ICEF = true //yes callback
 	})
@@ -358,6 +489,30 @@ func checkInstallEnv(c *command.Config) (result []*softEnf) {
 	return
 }
 
+// Software 开发环境依赖软件
+type Software struct {
+	Name      string `json:"name"`
+	Desc      string `json:"desc"`
+	Installed bool   `json:"installed"`
+}
+
+// CheckEnv 检查当前系统开发环境依赖软件是否安装, 不修改 c
+func CheckEnv(c *command.Config) []*Software {
+	ic := *c
+	ic.Install = command.Install{}
+	defaultInstallConfig(&ic)
+	var result []*Software
+	for _, se := range checkInstallEnv(&ic) {
+		result = append(result, &Software{Name: se.name, Desc: se.desc, Installed: se.installed})
+	}
+	return result
+}
+
+// SystemVersion windows 系统版本号, 非 windows 返回 0
+func SystemVersion() (majorVersion, minorVersion, buildNumber uint32) {
+	return versionNumber()
+}
+
 func defaultInstallConfig(c *command.Config) {
 	if c.Install.Path == "" {
 		// current dir
@@ -384,12 +539,6 @@ func initInstall(c *command.Config) (err error) {
 	if err != nil {
 		return
 	}
-	if c.Install.ICEF {
-		err = os.MkdirAll(cefInstallPathName(c), fs.ModePerm) //cef
-		if err != nil {
-			return
-		}
-	}
 	if c.Install.IGolang {
 		err = os.MkdirAll(goInstallPathName(c), fs.ModePerm) // go
 		if err != nil {
@@ -487,7 +636,8 @@ func tarFileReader(filePath string) (*tar.Reader, func(), error) {
 	}
 }
 
-func tarFileCount(filePath string) (int, error) {
+// 压缩包文件数量, 并检查文件路径不会跳出目标目录
+func tarFileCount(filePath, targetPath string) (int, error) {
 	tarReader, clos, err := tarFileReader(filePath)
 	if err != nil {
 		return 0, err
@@ -495,12 +645,16 @@ func tarFileCount(filePath string) (int, error) {
 	defer clos()
 	var count int
 	for {
-		_, err := tarReader.Next()
+		header, err := tarReader.Next()
 		if err == io.EOF {
 			break
 		} else if err != nil {
 			return 0, err
 		}
+		compressPath := filepath.Clean(header.Name[strings.Index(header.Name, "/")+1:])
+		if _, err = safeJoin(targetPath, compressPath); err != nil {
+			return 0, err
+		}
 		count++
 	}
 	return count, nil
@@ -508,7 +662,7 @@ func tarFileCount(filePath string) (int, error) {
 
 func ExtractUnTar(filePath, targetPath string, files ...any) error {
 	term.Logger.Info("Read Files Number")
-	fileCount, err := tarFileCount(filePath)
+	fileCount, err := tarFileCount(filePath, targetPath)
 	println(fileCount)
 	if err != nil {
 		return err
@@ -542,7 +696,10 @@ func ExtractUnTar(filePath, targetPath string, files ...any) error {
 			continue
 		}
 		info := header.FileInfo()
-		targetFile := filepath.Join(targetPath, includePath)
+		targetFile, err := safeJoin(targetPath, includePath)
+		if err != nil {
+			return err
+		}
 		if info.IsDir() {
 			if err = os.MkdirAll(targetFile, info.Mode()); err != nil {
 				return err
@@ -594,12 +751,28 @@ func ExtractUnTar(filePath, targetPath string, files ...any) error {
 func ExtractUnZip(filePath, targetPath string, rmRootDir bool, files ...any) error {
 	if rc, err := zip.OpenReader(filePath); err == nil {
 		defer rc.Close()
+		var entryName = func(f *zip.File) string {
+			if rmRootDir {
+				// 移除压缩包内的根文件夹名
+				return filepath.Clean(f.Name[strings.Index(f.Name, "/")+1:])
+			}
+			return filepath.Clean(f.Name)
+		}
+		// 检查文件路径不会跳出目标目录
+		for _, f := range rc.File {
+			if _, err := safeJoin(targetPath, entryName(f)); err != nil {
+				return err
+			}
+		}
 		multi := pterm.DefaultMultiPrinter
 		defer multi.Stop()
 		fileTotalProcessBar := pterm.DefaultProgressbar.WithWriter(multi.NewWriter())
 		writeFileProcessBar := pterm.DefaultProgressbar.WithWriter(multi.NewWriter())
 		var createWriteFile = func(info fs.FileInfo, path string, file io.Reader) error {
-			targetFileName := filepath.Join(targetPath, path)
+			targetFileName, err := safeJoin(targetPath, path)
+			if err != nil {
+				return err
+			}
 			if info.IsDir() {
 				os.MkdirAll(targetFileName, info.Mode())
 				return nil
@@ -647,14 +820,7 @@ func ExtractUnZip(filePath, targetPath string, rmRootDir bool, files ...any) err
 			for _, f := range zipFiles {
 				extractFilesProcessBar.Increment() // +1
 				r, _ := f.Open()
-				var name string
-				if rmRootDir {
-					// 移除压缩包内的根文件夹名
-					name = filepath.Clean(f.Name[strings.Index(f.Name, "/")+1:])
-				} else {
-					name = filepath.Clean(f.Name)
-				}
-				if err := createWriteFile(f.FileInfo(), name, r.(io.Reader)); err != nil {
+				if err := createWriteFile(f.FileInfo(), entryName(f), r.(io.Reader)); err != nil {
 					return err
 				}
 				_ = r.Close()
@@ -745,105 +911,3 @@ func isFileExist(filename string, filesize int64) bool {
 	os.Remove(filename)
 	return false
 }
-
-// DownloadFile 下载文件
-//  如果文件存在大小一样不再下载
-func DownloadFile(url string, localPath string, callback func(totalLength, processLength int64)) error {
-	var (
-		fsize   int64
-		buf     = make([]byte, 1024*10)
-		written int64
-	)
-	tmpFilePath := localPath + ".download"
-	client := new(http.Client)
-	resp, err := client.Get(url)
-	if err != nil {
-		return err
-	}
-	fsize, err = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 32)
-	if err != nil {
-		return err
-	}
-	if isFileExist(localPath, fsize) {
-		term.Section.Println("File already exists")
-		return nil
-	}
-	file, err := os.Create(tmpFilePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	if resp.Body == nil {
-		return errors.New("body is null")
-	}
-	defer resp.Body.Close()
-	total := 100
-	_, fileName := filepath.Split(localPath)
-	if len(fileName) > 70 {
-		c := len(fileName) - 70
-		for i := 0; i < c; i++ {
-
-		}
-	}
-	p, err := pterm.DefaultProgressbar.WithMaxWidth(80).WithTotal(total).WithTitle("Download " + fileName).Start()
-	if err != nil {
-		return err
-	}
-	var stop = func() {
-		if p != nil {
-			p.Stop()
-			p = nil
-		}
-	}
-	defer stop()
-	var (
-		count int
-		cn    int
-	)
-	var nw int
-	for {
-		nr, er := resp.Body.Read(buf)
-		if nr > 0 {
-			nw, err = file.Write(buf[0:nr])
-			if nw > 0 {
-				written += int64(nw)
-			}
-			if callback != nil {
-				callback(fsize, written)
-			} else {
-				process := int((float64(written) / float64(fsize)) * 100)
-				if process > count {
-					count = process
-					p.Add(1)
-					cn++
-				}
-			}
-			if err != nil {
-				break
-			}
-			if nr != nw {
-				err = io.ErrShortWrite
-				break
-			}
-		}
-		if er != nil {
-			if er != io.EOF {
-				err = er
-			}
-			break
-		}
-	}
-	if cn < total {
-		p.Add(total - cn)
-	}
-	stop()
-	if err == nil {
-		file.Sync()
-		file.Close()
-		err = os.Rename(tmpFilePath, localPath)
-		if err != nil {
-			return err
-		}
-	}
-	return err
-}
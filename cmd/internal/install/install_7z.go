@@ -20,27 +20,46 @@ import (
 	"path/filepath"
 )
 
-func install7z(c *command.Config) (string, func()) {
+// 7za 安装包下载任务
+func z7zTask(c *command.Config) *downloadTask {
+	fileName := fmt.Sprintf("7za.windows.all-%s.zip", consts.Z7ZDownloadVersion)
+	return &downloadTask{
+		name: fileName,
+		url:  fmt.Sprintf(consts.Z7ZDownloadURL, fileName),
+		path: filepath.Join(c.Install.Path, consts.FrameworkCache, fileName), // 下载保存目录
+	}
+}
+
+// 下载安装 7za, task 已并行下载
+func install7z(c *command.Config, task *downloadTask) (string, func()) {
 	if !c.Install.I7za {
 		return "", nil
 	}
 	pterm.Println()
 	s := z7zInstallPathName(c) // 安装目录
 	version := consts.Z7ZDownloadVersion
-	fileName := fmt.Sprintf("7za.windows.all-%s.zip", version)
-	downloadUrl := fmt.Sprintf(consts.Z7ZDownloadURL, fileName)
-	savePath := filepath.Join(c.Install.Path, consts.FrameworkCache, fileName) // 下载保存目录
-	var err error
-	term.Logger.Info("7za Download URL: " + downloadUrl)
+	fileName, savePath := task.name, task.path
+	err := task.err
+	term.Logger.Info("7za Download URL: " + task.url)
 	term.Logger.Info("7za Save Path: " + savePath)
-	if !tools.IsExist(savePath) {
-		err = DownloadFile(downloadUrl, savePath, nil)
+	if err != nil {
+		term.Logger.Error("Download [" + fileName + "] failed: " + err.Error())
+		recordError(c, err)
+	} else if !tools.IsExist(savePath) {
+		err = downloadFile(c, task.url, savePath)
 		if err != nil {
 			term.Logger.Error("Download [" + fileName + "] failed: " + err.Error())
+			recordError(c, err)
 		} else {
 			term.Logger.Info("Download ["+fileName+"]", term.Logger.Args(fileName, "success"))
 		}
 	}
+	if err == nil {
+		if err = verifyChecksum(c, savePath); err != nil {
+			term.Logger.Error(err.Error())
+			recordError(c, err)
+		}
+	}
 	if err == nil {
 		// 安装目录
 		targetPath := s
@@ -48,6 +67,7 @@ func install7z(c *command.Config) (string, func()) {
 		// zip
 		if err = ExtractUnZip(savePath, targetPath, false); err != nil {
 			term.Logger.Error(err.Error())
+			recordError(c, err)
 			return "", nil
 		}
 		return targetPath, func() {
@@ -10,19 +10,21 @@
 package install
 
 import (
-	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/energye/energy/v2/cmd/internal/command"
 	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/framework"
 	"github.com/energye/energy/v2/cmd/internal/term"
 	"github.com/energye/energy/v2/cmd/internal/tools"
 	"github.com/pterm/pterm"
+	"io/fs"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // 安装CEF和liblcl框架
@@ -35,39 +37,182 @@ import (
 //  Linux
 //    Gtk2: CEF = 106
 //    Gtk3: CEF 最新版本
-func installCEFFramework(c *command.Config) (string, func()) {
-	if !c.Install.ICEF {
-		return "", nil
-	}
-	pterm.Println()
-	term.Section.Println("Install CEF")
+// CEF 框架安装配置和下载任务
+type cefFramework struct {
+	extractOSConfig map[string]any
+	downloads       map[string]*downloadInfo
+	info            *frameworkInfo
+	tasks           []*downloadTask
+}
+
+// 获取 CEF 框架的提取文件配置, 版本配置和下载任务, 失败时返回 nil
+func cefFrameworkTasks(c *command.Config) *cefFramework {
 	// 获取提取文件配置
-	extractData, err := tools.HttpRequestGET(consts.DownloadExtractURL)
+	extractConfig, err := requestJSONConfig(c, consts.DownloadExtractURL, MirrorExtractFile)
 	if err != nil {
 		term.Logger.Error(err.Error())
-		return "", nil
+		recordError(c, err)
+		return nil
 	}
-	var extractConfig map[string]any
-	extractData = bytes.TrimPrefix(extractData, []byte("\xef\xbb\xbf"))
-	if err := json.Unmarshal(extractData, &extractConfig); err != nil {
-		term.Logger.Error(err.Error())
-		return "", nil
+	extractOSConfig, ok := extractConfig[string(c.Install.OS)].(map[string]any)
+	if !ok {
+		term.Logger.Error("Extract config is not configured for " + string(c.Install.OS))
+		return nil
 	}
-	extractOSConfig := extractConfig[string(c.Install.OS)].(map[string]any)
 
 	// 获取安装版本配置
-	downloadJSON, err := tools.HttpRequestGET(consts.DownloadVersionURL)
+	edv, err := requestJSONConfig(c, consts.DownloadVersionURL, MirrorVersionFile)
 	if err != nil {
 		term.Logger.Error(err.Error())
+		recordError(c, err)
+		return nil
+	}
+
+	downloads, info, err := cefFrameworkDownloads(c, edv)
+	if err != nil {
+		term.Logger.Error(err.Error())
+		recordError(c, err)
+		return nil
+	}
+	cef := &cefFramework{extractOSConfig: extractOSConfig, downloads: downloads, info: info}
+	for key, dl := range downloads {
+		if !dl.isSupport {
+			term.Logger.Warn("Warn module is not built or configured [" + dl.module + "]")
+			continue
+		}
+		term.Section.Println("Download", key, ":", dl.url)
+		cef.tasks = append(cef.tasks, &downloadTask{name: dl.fileName, url: dl.url, path: dl.downloadPath})
+	}
+	return cef
+}
+
+// 安装 CEF 框架, cef 的下载任务已和其它安装包并行下载
+func installCEFFramework(c *command.Config, cef *cefFramework) (string, func()) {
+	if !c.Install.ICEF || cef == nil {
 		return "", nil
 	}
-	var edv map[string]any
-	downloadJSON = bytes.TrimPrefix(downloadJSON, []byte("\xef\xbb\xbf"))
-	if err := json.Unmarshal(downloadJSON, &edv); err != nil {
+	pterm.Println()
+	term.Section.Println("Install CEF")
+	var err error
+	extractOSConfig, downloads, info := cef.extractOSConfig, cef.downloads, cef.info
+
+	// 安装目录名称, 每个版本独立目录
+	name := framework.NameOf(info.energyVersion, info.cefVersion, string(c.Install.OS), string(c.Install.Arch))
+	installPathName := cefInstallPathName(c, name)
+	term.Section.Println("Install Path", installPathName)
+	if err = os.MkdirAll(installPathName, fs.ModePerm); err != nil {
 		term.Logger.Error(err.Error())
+		recordError(c, err)
 		return "", nil
 	}
+	liblclVersion, liblclModuleName, liblclModule := info.version, info.moduleName, info.module
 
+	// 框架二进制包下载结果
+	errs := make(map[string]error)
+	for _, task := range cef.tasks {
+		if task.err != nil {
+			errs[task.name] = task.err
+		}
+	}
+	if len(errs) > 0 {
+		err = &DownloadError{Errors: errs}
+		term.Logger.Error(err.Error())
+		recordError(c, err)
+		return "", nil
+	}
+	for _, dl := range downloads {
+		if !dl.isSupport {
+			continue
+		}
+		if err = verifyChecksum(c, dl.downloadPath); err != nil {
+			term.Logger.Error(err.Error())
+			recordError(c, err)
+			return "", nil
+		}
+		dl.success = true
+	}
+	// 解压文件, 并根据配置提取文件
+	term.Logger.Info("Unpack files")
+	for key, di := range downloads {
+		if !di.isSupport {
+			term.Logger.Warn("module is not built or configured [" + di.module + "]")
+			continue
+		}
+		if di.success {
+			if key == consts.CefKey {
+				processBar, err := pterm.DefaultProgressbar.WithShowCount(false).WithShowPercentage(false).WithMaxWidth(1).Start()
+				if err != nil {
+					term.Logger.Error(err.Error())
+					recordError(c, err)
+					return "", nil
+				}
+				tarName, err := UnBz2ToTar(di.downloadPath, func(totalLength, processLength int64) {
+					processBar.UpdateTitle(fmt.Sprintf("Unpack file %s, process: %d", key, processLength)) // Update the title of the progressbar.
+				})
+				processBar.Stop()
+				if err != nil {
+					term.Logger.Error(err.Error())
+					recordError(c, err)
+					return "", nil
+				}
+				if err := ExtractFiles(key, tarName, di, extractOSConfig); err != nil {
+					term.Logger.Error(err.Error())
+					recordError(c, err)
+					return "", nil
+				}
+			} else if key == consts.LiblclKey {
+				if err := ExtractFiles(key, di.downloadPath, di, extractOSConfig); err != nil {
+					term.Logger.Error(err.Error())
+					recordError(c, err)
+					return "", nil
+				}
+			}
+			term.Section.Println("Unpack file", key, "success")
+		}
+	}
+	// 记录安装的框架, energy list, energy use
+	installed := &framework.Framework{
+		Name:       name,
+		Path:       installPathName,
+		Version:    info.energyVersion,
+		CEF:        info.cefModuleName,
+		CEFVersion: info.cefVersion,
+		Liblcl:     liblclVersion,
+		OS:         string(c.Install.OS),
+		Arch:       string(c.Install.Arch),
+		Installed:  time.Now().Format(time.RFC3339),
+	}
+	if abs, err := filepath.Abs(installPathName); err == nil {
+		installed.Path = abs
+	}
+	if err = framework.Add(installed); err != nil {
+		term.Logger.Warn("Register framework failed: " + err.Error())
+	} else if c.Install.IsSame {
+		// 当前系统架构的框架设置为全局使用
+		if err = framework.SetCurrent(installed.Name); err != nil {
+			term.Logger.Warn(err.Error())
+		}
+	}
+	return installPathName, func() {
+		term.Logger.Info("CEF Installed Successfully", term.Logger.Args("Version", c.Install.Version, "liblcl", liblclVersion, "Name", installed.Name))
+		if liblclModule == nil {
+			term.Section.Println("hint: liblcl module", liblclModuleName, `is not configured in the current version, You need to use built-in binary build. [go build -tags="tempdll"]`)
+		}
+	}
+}
+
+// 框架模块选择结果
+type frameworkInfo struct {
+	energyVersion string         // energy 版本
+	cefModuleName string         // CEF 模块名
+	cefVersion    string         // CEF 版本
+	version       string         // liblcl 版本
+	moduleName    string         // liblcl 模块名
+	module        map[string]any // liblcl 模块配置
+}
+
+// 根据版本配置获得 CEF 和 liblcl 下载信息
+func cefFrameworkDownloads(c *command.Config, edv map[string]any) (downloads map[string]*downloadInfo, liblcl *frameworkInfo, err error) {
 	// -c cef args value
 	// default(empty), windows7, gtk2, flash
 	cef := strings.ToLower(c.Install.CEF)
@@ -75,19 +220,19 @@ func installCEFFramework(c *command.Config) (string, func()) {
 	//	term.Logger.Error("-c [cef] Incorrect args value")
 	//	return "", nil
 	//}
-	// 安装目录名称
-	installPathName := cefInstallPathName(c)
-	term.Section.Println("Install Path", installPathName)
-
-	term.Section.Println("Start downloading CEF and Energy dependency")
 	// 所有版本列表
-	var versionList = edv["versionList"].(map[string]any)
+	versionList, ok := edv["versionList"].(map[string]any)
+	if !ok {
+		return nil, nil, errors.New("invalid version config, versionList not found")
+	}
 
 	// 获取到当前安装版本
 	var installVersion map[string]any
+	energyVersion := c.Install.Version
 	if c.Install.Version == "latest" {
 		// 获取最新版本号, latest=vx.x.x
-		if v, ok := versionList[edv["latest"].(string)]; ok {
+		energyVersion = tools.ToString(edv["latest"])
+		if v, ok := versionList[energyVersion]; ok {
 			installVersion = v.(map[string]any)
 		}
 	} else {
@@ -98,8 +243,7 @@ func installCEFFramework(c *command.Config) (string, func()) {
 	}
 	term.Section.Println("Check version")
 	if installVersion == nil || len(installVersion) == 0 {
-		term.Logger.Error("Invalid version number " + c.Install.Version)
-		return "", nil
+		return nil, nil, errors.New("Invalid version number " + c.Install.Version)
 	}
 	// 当前版本 cef 和 liblcl 版本选择
 	var (
@@ -167,8 +311,7 @@ func installCEFFramework(c *command.Config) (string, func()) {
 		liblclModule = module.(map[string]any)
 	}
 	if cefModule == nil {
-		term.Logger.Error("CEF module " + cefModuleName + " is not configured in the current version")
-		return "", nil
+		return nil, nil, errors.New("CEF module " + cefModuleName + " is not configured in the current version")
 	}
 	// 下载源选择
 	var replaceSource = func(url, source string, sourceSelect int, module string) string {
@@ -183,9 +326,11 @@ func installCEFFramework(c *command.Config) (string, func()) {
 		return url
 	}
 	// 下载集合
-	var downloads = make(map[string]*downloadInfo)
+	downloads = make(map[string]*downloadInfo)
 	// 根据模块名拿到版本号
 	cefVersion := tools.ToRNilString(installVersion[cefModuleName], "")
+	// 安装目录名称, 每个版本独立目录
+	installPathName := cefInstallPathName(c, framework.NameOf(energyVersion, cefVersion, string(c.Install.OS), string(c.Install.Arch)))
 	// 当前模块版本支持系统，如果支持返回下载地址
 	libCEFOS, isSupport := cefOS(c, cefModule)
 	downloadCefURL := tools.ToString(cefModule["downloadUrl"])
@@ -208,62 +353,8 @@ func installCEFFramework(c *command.Config) (string, func()) {
 		downloadEnergyURL = strings.ReplaceAll(downloadEnergyURL, "{OSARCH}", libEnergyOS)
 		downloads[consts.LiblclKey] = &downloadInfo{isSupport: isSupport, fileName: urlName(downloadEnergyURL), downloadPath: filepath.Join(c.Install.Path, consts.FrameworkCache, urlName(downloadEnergyURL)), frameworkPath: installPathName, url: downloadEnergyURL, module: liblclModuleName}
 	}
-
-	// 在线下载框架二进制包
-	for key, dl := range downloads {
-		term.Section.Println("Download", key, ":", dl.url)
-		if !dl.isSupport {
-			term.Logger.Warn("Warn module is not built or configured [" + dl.module + "]")
-			continue
-		}
-		err = DownloadFile(dl.url, dl.downloadPath, nil)
-		if err != nil {
-			term.Logger.Error("Download [" + dl.fileName + "] " + err.Error())
-			return "", nil
-		}
-		dl.success = err == nil
-	}
-	// 解压文件, 并根据配置提取文件
-	term.Logger.Info("Unpack files")
-	for key, di := range downloads {
-		if !di.isSupport {
-			term.Logger.Warn("module is not built or configured [" + di.module + "]")
-			continue
-		}
-		if di.success {
-			if key == consts.CefKey {
-				processBar, err := pterm.DefaultProgressbar.WithShowCount(false).WithShowPercentage(false).WithMaxWidth(1).Start()
-				if err != nil {
-					term.Logger.Error(err.Error())
-					return "", nil
-				}
-				tarName, err := UnBz2ToTar(di.downloadPath, func(totalLength, processLength int64) {
-					processBar.UpdateTitle(fmt.Sprintf("Unpack file %s, process: %d", key, processLength)) // Update the title of the progressbar.
-				})
-				processBar.Stop()
-				if err != nil {
-					term.Logger.Error(err.Error())
-					return "", nil
-				}
-				if err := ExtractFiles(key, tarName, di, extractOSConfig); err != nil {
-					term.Logger.Error(err.Error())
-					return "", nil
-				}
-			} else if key == consts.LiblclKey {
-				if err := ExtractFiles(key, di.downloadPath, di, extractOSConfig); err != nil {
-					term.Logger.Error(err.Error())
-					return "", nil
-				}
-			}
-			term.Section.Println("Unpack file", key, "success")
-		}
-	}
-	return installPathName, func() {
-		term.Logger.Info("CEF Installed Successfully", term.Logger.Args("Version", c.Install.Version, "liblcl", liblclVersion))
-		if liblclModule == nil {
-			term.Section.Println("hint: liblcl module", liblclModuleName, `is not configured in the current version, You need to use built-in binary build. [go build -tags="tempdll"]`)
-		}
-	}
+	return downloads, &frameworkInfo{energyVersion: energyVersion, cefModuleName: cefModuleName, cefVersion: cefVersion,
+		version: liblclVersion, moduleName: liblclModuleName, module: liblclModule}, nil
 }
 
 func cefOS(c *command.Config, module map[string]any) (string, bool) {
@@ -21,53 +21,59 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
-	"time"
 )
 
-// 下载go并配置安装
-func installGolang(c *command.Config) (string, func()) {
+// Go 安装包下载任务
+func golangTask(c *command.Config) *downloadTask {
+	fileName := golangFileName(consts.GolangDefaultVersion, runtime.GOOS, runtime.GOARCH)
+	// Go下载源, 格式只能是 [https://xxx.xxx.xx]/dl/go1.18.10.windows-arm64.zip
+	return &downloadTask{
+		name: fileName,
+		url:  golangDownloadURL(c, fileName),
+		path: filepath.Join(c.Install.Path, consts.FrameworkCache, fileName), // 下载保存目录
+	}
+}
+
+// 下载go并配置安装, task 已并行下载
+func installGolang(c *command.Config, task *downloadTask) (string, func()) {
 	if !c.Install.IGolang {
 		return "", nil
 	}
 	pterm.Println()
 	term.Section.Println("Install Golang")
 	s := goInstallPathName(c) // 安装目录
-	exts := map[string]string{
-		"darwin":  "tar.gz",
-		"linux":   "tar.gz",
-		"windows": "zip",
-	}
 	// 开始下载并安装Go开发环境
 	version := consts.GolangDefaultVersion
-	gos := runtime.GOOS
-	arch := runtime.GOARCH
-	ext := exts[gos]
 	if !tools.IsExist(s) {
 		term.Section.Println("Directory does not exist. Creating directory.", s)
 		if err := os.MkdirAll(s, fs.ModePerm); err != nil {
 			term.Section.Println("Failed to create goroot directory", err.Error())
+			recordError(c, err)
 			return "", nil
 		}
 	}
-	fileName := fmt.Sprintf("go%s.%s-%s.%s", version, gos, arch, ext)
-	savePath := filepath.Join(c.Install.Path, consts.FrameworkCache, fileName) // 下载保存目录
-	var err error
-	if !tools.IsExist(savePath) {
-		// Go下载源, 格式只能是 [https://xxx.xxx.xx]/dl/go1.18.10.windows-arm64.zip
-		downloadSource := strings.TrimSpace(c.EnergyCfg.Source.Golang)
-		if downloadSource == "" {
-			downloadSource = consts.GolangDownloadSource
-		}
-		downloadUrl := fmt.Sprintf(consts.GolangDownloadURL, downloadSource, fileName)
-		term.Logger.Info("Golang Download URL: " + downloadUrl)
+	fileName, savePath := task.name, task.path
+	err := task.err
+	if err != nil {
+		term.Logger.Error("Download [" + fileName + "] failed: " + err.Error())
+		recordError(c, err)
+	} else if !tools.IsExist(savePath) {
+		term.Logger.Info("Golang Download URL: " + task.url)
 		term.Logger.Info("Golang Save Path: " + savePath)
-		err = downloadGolang(downloadUrl, savePath, fileName, 0)
+		err = downloadFile(c, task.url, savePath)
 		if err != nil {
 			term.Logger.Error("Download [" + fileName + "] failed: " + err.Error())
+			recordError(c, err)
 		} else {
 			term.Logger.Info("Download [" + fileName + "] success")
 		}
 	}
+	if err == nil {
+		if err = verifyChecksum(c, savePath); err != nil {
+			term.Logger.Error(err.Error())
+			recordError(c, err)
+		}
+	}
 	if err == nil {
 		// 安装目录
 		targetPath := s
@@ -76,12 +82,14 @@ func installGolang(c *command.Config) (string, func()) {
 			//zip
 			if err = ExtractUnZip(savePath, targetPath, true); err != nil {
 				term.Logger.Error(err.Error())
+				recordError(c, err)
 				return "", nil
 			}
 		} else {
 			//tar
 			if err = ExtractUnTar(savePath, targetPath); err != nil {
 				term.Logger.Error(err.Error())
+				recordError(c, err)
 				return "", nil
 			}
 		}
@@ -92,15 +100,20 @@ func installGolang(c *command.Config) (string, func()) {
 	return "", nil
 }
 
-func downloadGolang(downloadUrl, savePath, fileName string, count int) error {
-	err := DownloadFile(downloadUrl, savePath, nil)
-	if err != nil && count < 5 {
-		// 失败尝试5次，每次递增一秒等待
-		n := count + 1
-		term.Logger.Error(err.Error())
-		term.Logger.Error(fmt.Sprintf("Download failed. %d second retry", n), term.Logger.Args("count", fmt.Sprintf("%d/5", n)))
-		time.Sleep(time.Second * time.Duration(n))
-		return downloadGolang(downloadUrl, savePath, fileName, n)
+// Go 安装包文件名, windows: zip, 其它: tar.gz
+func golangFileName(version, goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("go%s.%s-%s.%s", version, goos, goarch, ext)
+}
+
+// Go 下载地址, 下载源格式只能是 [https://xxx.xxx.xx]/dl/go1.18.10.windows-arm64.zip
+func golangDownloadURL(c *command.Config, fileName string) string {
+	downloadSource := strings.TrimSpace(c.EnergyCfg.Source.Golang)
+	if downloadSource == "" {
+		downloadSource = consts.GolangDownloadSource
 	}
-	return err
+	return fmt.Sprintf(consts.GolangDownloadURL, downloadSource, fileName)
 }
@@ -19,7 +19,18 @@ import (
 	"path/filepath"
 )
 
-func installNSIS(c *command.Config) (string, func()) {
+// NSIS 安装包下载任务
+func nsisTask(c *command.Config) *downloadTask {
+	fileName := fmt.Sprintf("nsis.windows.386-%s.zip", consts.NSISDownloadVersion)
+	return &downloadTask{
+		name: fileName,
+		url:  fmt.Sprintf(consts.NSISDownloadURL, fileName),
+		path: filepath.Join(c.Install.Path, consts.FrameworkCache, fileName), // 下载保存目录
+	}
+}
+
+// 下载安装 NSIS 和 nsis7z 插件, task, pluginTask 已并行下载
+func installNSIS(c *command.Config, task, pluginTask *downloadTask) (string, func()) {
 	if !c.Install.INSIS {
 		return "", nil
 	}
@@ -28,20 +39,28 @@ func installNSIS(c *command.Config) (string, func()) {
 	// 下载并安装配置NSIS
 	s := nsisInstallPathName(c) // 安装目录
 	version := consts.NSISDownloadVersion
-	fileName := fmt.Sprintf("nsis.windows.386-%s.zip", version)
-	downloadUrl := fmt.Sprintf(consts.NSISDownloadURL, fileName)
-	savePath := filepath.Join(c.Install.Path, consts.FrameworkCache, fileName) // 下载保存目录
-	var err error
-	if !tools.IsExist(savePath) {
-		term.Logger.Info("NSIS Download URL: " + downloadUrl)
+	fileName, savePath := task.name, task.path
+	err := task.err
+	if err != nil {
+		term.Logger.Error("Download [" + fileName + "] failed: " + err.Error())
+		recordError(c, err)
+	} else if !tools.IsExist(savePath) {
+		term.Logger.Info("NSIS Download URL: " + task.url)
 		term.Logger.Info("NSIS Save Path: " + savePath)
-		err = DownloadFile(downloadUrl, savePath, nil)
+		err = downloadFile(c, task.url, savePath)
 		if err != nil {
 			term.Logger.Error("Download [" + fileName + "] failed: " + err.Error())
+			recordError(c, err)
 		} else {
 			term.Logger.Info("Download ["+fileName+"]", term.Logger.Args(fileName, "success"))
 		}
 	}
+	if err == nil {
+		if err = verifyChecksum(c, savePath); err != nil {
+			term.Logger.Error(err.Error())
+			recordError(c, err)
+		}
+	}
 	if err == nil {
 		// 安装目录
 		targetPath := s
@@ -49,10 +68,11 @@ func installNSIS(c *command.Config) (string, func()) {
 		//zip
 		if err = ExtractUnZip(savePath, targetPath, true); err != nil {
 			term.Logger.Error(err.Error())
+			recordError(c, err)
 			return "", nil
 		}
 		// 安装nsis7z插件
-		installNSIS7z(c)
+		installNSIS7z(c, pluginTask)
 		return targetPath, func() {
 			term.Logger.Info("NSIS Installed Successfully", term.Logger.Args("Version", version))
 		}
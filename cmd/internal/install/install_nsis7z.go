@@ -19,26 +19,44 @@ import (
 	"path/filepath"
 )
 
-func installNSIS7z(c *command.Config) (string, func()) {
+// nsis7z 插件下载任务
+func nsis7zTask(c *command.Config) *downloadTask {
+	fileName := fmt.Sprintf("nsis7z.windows.386-%s.zip", consts.NSIS7zDownloadVersion)
+	return &downloadTask{
+		name: fileName,
+		url:  fmt.Sprintf(consts.NSIS7zDownloadURL, fileName),
+		path: filepath.Join(c.Install.Path, consts.FrameworkCache, fileName), // 下载保存目录
+	}
+}
+
+func installNSIS7z(c *command.Config, task *downloadTask) (string, func()) {
 	pterm.Println()
 	term.Section.Println("Install NSIS7za")
 	// 下载并安装配置NSIS7za
 	s := nsisInstallPathName(c) // 安装目录
 	version := consts.NSIS7zDownloadVersion
-	fileName := fmt.Sprintf("nsis7z.windows.386-%s.zip", version)
-	downloadUrl := fmt.Sprintf(consts.NSIS7zDownloadURL, fileName)
-	savePath := filepath.Join(c.Install.Path, consts.FrameworkCache, fileName) // 下载保存目录
-	var err error
-	if !tools.IsExist(savePath) {
-		term.Logger.Info("NSIS7z Download URL: " + downloadUrl)
+	fileName, savePath := task.name, task.path
+	err := task.err
+	if err != nil {
+		term.Logger.Error("Download [" + fileName + "] failed: " + err.Error())
+		recordError(c, err)
+	} else if !tools.IsExist(savePath) {
+		term.Logger.Info("NSIS7z Download URL: " + task.url)
 		term.Logger.Info("NSIS7z Save Path: " + savePath)
-		err = DownloadFile(downloadUrl, savePath, nil)
+		err = downloadFile(c, task.url, savePath)
 		if err != nil {
 			term.Logger.Error("Download [" + fileName + "] failed: " + err.Error())
+			recordError(c, err)
 		} else {
 			term.Logger.Info("Download ["+fileName+"]", term.Logger.Args(fileName, "success"))
 		}
 	}
+	if err == nil {
+		if err = verifyChecksum(c, savePath); err != nil {
+			term.Logger.Error(err.Error())
+			recordError(c, err)
+		}
+	}
 	if err == nil {
 		// 安装目录
 		targetPath := s
@@ -46,6 +64,7 @@ func installNSIS7z(c *command.Config) (string, func()) {
 		// zip
 		if err = ExtractUnZip(savePath, targetPath, false); err != nil {
 			term.Logger.Error(err.Error())
+			recordError(c, err)
 			return "", nil
 		}
 		return targetPath, func() {
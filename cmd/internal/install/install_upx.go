@@ -44,11 +44,13 @@ func installUPX(c *command.Config) (string, func()) {
 		fs, err := assets.UpxBytes()
 		if err != nil {
 			term.Logger.Error("UPX Installed Error: " + err.Error())
+			recordError(c, err)
 			return "", nil
 		}
 		stat, err := fs.Stat()
 		if err != nil {
 			term.Logger.Error("UPX Installed Error: " + err.Error())
+			recordError(c, err)
 			return "", nil
 		}
 
@@ -78,6 +80,7 @@ func installUPX(c *command.Config) (string, func()) {
 		}
 	} else {
 		term.Logger.Error("CreateWriteFile: " + err.Error())
+		recordError(c, err)
 		return "", nil
 	}
 
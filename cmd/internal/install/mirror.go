@@ -0,0 +1,222 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package install
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 离线安装镜像
+//  目录或 http(s) 地址, 所有文件在根目录
+//   edv.json       版本配置, 同 consts.DownloadVersionURL
+//   extract.json   提取文件配置, 同 consts.DownloadExtractURL
+//   bundle.json    bundle 信息, energy bundle 生成
+//   upgrade.json   energy 命令行升级配置, 同 consts.CheckUpgradeURL
+//   cef_binary_xxx.tar.bz2, liblcl-xxx.zip, goxxx.zip|tar.gz, nsis.xxx.zip, nsis7z.xxx.zip, 7za.xxx.zip, energy-xxx
+//  文件名和在线下载地址的文件名相同
+const (
+	MirrorVersionFile = "edv.json"
+	MirrorExtractFile = "extract.json"
+	MirrorBundleFile  = "bundle.json"
+	MirrorUpgradeFile = "upgrade.json"
+)
+
+// BundleInfo bundle.json
+type BundleInfo struct {
+	Version   string            `json:"version"` // energy 版本
+	OS        string            `json:"os"`
+	Arch      string            `json:"arch"`
+	CEF       string            `json:"cef"`
+	Files     []string          `json:"files"`     // 安装包文件名
+	Checksums map[string]string `json:"checksums"` // 安装包 sha256, 文件名 => 摘要
+}
+
+// 镜像地址是否为 http(s)
+func isRemoteMirror(mirror string) bool {
+	return strings.HasPrefix(mirror, "http://") || strings.HasPrefix(mirror, "https://")
+}
+
+// 从镜像获取文件内容, 未配置镜像时使用在线地址 url
+//  http(s) 请求使用下载器的代理, 超时和重试
+func requestConfig(c *command.Config, url, name string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	mirror := c.Install.Mirror
+	if mirror == "" {
+		data, err = newDownloader(c).Get(url)
+	} else if isRemoteMirror(mirror) {
+		data, err = newDownloader(c).Get(strings.TrimSuffix(mirror, "/") + "/" + name)
+	} else {
+		data, err = os.ReadFile(filepath.Join(mirror, name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), nil
+}
+
+// 请求 json 配置并解析
+func requestJSONConfig(c *command.Config, url, name string) (map[string]any, error) {
+	data, err := requestConfig(c, url, name)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return result, nil
+}
+
+// 下载安装包, 配置镜像时按文件名从镜像获取
+func downloadFile(c *command.Config, url, localPath string) error {
+	mirror := c.Install.Mirror
+	if mirror == "" {
+		return downloadWithProgress(newDownloader(c), url, localPath, nil)
+	}
+	name := urlName(url)
+	if isRemoteMirror(mirror) {
+		mirrorURL := strings.TrimSuffix(mirror, "/") + "/" + name
+		term.Logger.Info("Mirror: " + mirrorURL)
+		return downloadWithProgress(newDownloader(c), mirrorURL, localPath, nil)
+	}
+	src := filepath.Join(mirror, name)
+	term.Logger.Info("Mirror: " + src)
+	return copyFile(src, localPath)
+}
+
+func copyFile(src, dst string) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+		return nil
+	}
+	if isFileExist(dst, srcInfo.Size()) {
+		term.Section.Println("File already exists")
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".download"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// 使用 --from-archive bundle 安装
+//  bundle 释放到下载缓存目录, 并将缓存目录作为本地镜像
+func useBundleArchive(c *command.Config) error {
+	if c.Install.FromArchive == "" {
+		return nil
+	}
+	if c.Install.Mirror != "" {
+		return errors.New("--mirror and --from-archive can not be used together")
+	}
+	cacheDir := filepath.Join(c.Install.Path, consts.FrameworkCache)
+	if err := os.MkdirAll(cacheDir, fs.ModePerm); err != nil {
+		return err
+	}
+	term.Section.Println("Unpack bundle", c.Install.FromArchive)
+	if err := extractBundle(c.Install.FromArchive, cacheDir); err != nil {
+		return err
+	}
+	if data, err := os.ReadFile(filepath.Join(cacheDir, MirrorBundleFile)); err == nil {
+		var info BundleInfo
+		if err = json.Unmarshal(data, &info); err == nil {
+			term.Section.Println("Bundle", info.Version, info.OS, info.Arch)
+			if c.Install.Version == "latest" && info.Version != "" {
+				c.Install.Version = info.Version
+			}
+			if c.Install.CEF == "" && info.CEF != "" {
+				c.Install.CEF = info.CEF
+			}
+			if c.Install.Checksums == nil {
+				c.Install.Checksums = make(map[string]string)
+			}
+			for name, sum := range info.Checksums {
+				c.Install.Checksums[name] = sum
+			}
+		}
+	}
+	c.Install.Mirror = cacheDir
+	return nil
+}
+
+// 释放 bundle (tar.gz), 只释放根目录文件
+func extractBundle(bundle, targetPath string) error {
+	f, err := os.Open(bundle)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := filepath.Base(filepath.Clean(header.Name))
+		target := filepath.Join(targetPath, name)
+		if isFileExist(target, header.Size) {
+			continue
+		}
+		term.Section.Println("  ", name)
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
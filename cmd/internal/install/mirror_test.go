@@ -0,0 +1,44 @@
+package install
+
+import (
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBundleMirror(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	os.MkdirAll(cacheDir, 0755)
+	os.WriteFile(filepath.Join(cacheDir, "liblcl.zip"), []byte("liblcl"), 0644)
+	out := filepath.Join(dir, "bundle.tar.gz")
+	err := writeBundle(out, cacheDir, []string{"liblcl.zip"}, map[string][]byte{
+		MirrorVersionFile: []byte(`{"latest":"v2.3.0"}`),
+		MirrorBundleFile:  []byte(`{"version":"v2.3.0","os":"linux","arch":"amd64","cef":"106"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &command.Config{}
+	c.Install.Path = filepath.Join(dir, "install")
+	c.Install.Version = "latest"
+	c.Install.FromArchive = out
+	if err = useBundleArchive(c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Install.Version != "v2.3.0" || c.Install.CEF != "106" || c.Install.Mirror == "" {
+		t.Fatal("unexpected install config", c.Install)
+	}
+	edv, err := requestJSONConfig(c, "", MirrorVersionFile)
+	if err != nil || edv["latest"] != "v2.3.0" {
+		t.Fatal("unexpected version config", edv, err)
+	}
+	target := filepath.Join(dir, "liblcl.zip")
+	if err = downloadFile(c, "https://example.com/download/liblcl.zip", target); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "liblcl" {
+		t.Fatal("unexpected file content", string(data))
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package install
+
+import "os"
+
+// 替换执行文件, 同目录重命名是原子操作, 运行中的执行文件不受影响
+func replaceExecutable(exe, newExe string) error {
+	if info, err := os.Stat(exe); err == nil {
+		os.Chmod(newExe, info.Mode().Perm()|0111)
+	}
+	return os.Rename(newExe, exe)
+}
+
+func removeOldExecutable(exe string) {
+}
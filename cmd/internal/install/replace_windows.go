@@ -0,0 +1,28 @@
+//go:build windows
+
+package install
+
+import "os"
+
+// 替换执行文件
+//  运行中的执行文件不能覆盖和删除, 但可以重命名
+//  当前执行文件重命名为 .old, 新文件重命名为执行文件, 失败时恢复
+//  .old 在下次执行 energy upgrade 时删除
+func replaceExecutable(exe, newExe string) error {
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err := os.Rename(newExe, exe); err != nil {
+		os.Rename(old, exe)
+		return err
+	}
+	os.Remove(old)
+	return nil
+}
+
+// 删除上次升级留下的执行文件
+func removeOldExecutable(exe string) {
+	os.Remove(exe + ".old")
+}
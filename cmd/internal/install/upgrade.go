@@ -0,0 +1,270 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package install
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"github.com/pterm/pterm"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// energy 命令行升级
+//  升级配置 (upgrade.json), consts.CheckUpgradeURL 或镜像中的 upgrade.json
+//   {
+//     "latest": "1.0.3",
+//     "notes": "更新说明",
+//     "files": {"linux/amd64": "https://.../energy-1.0.3-linux-amd64.tar.gz", "windows/amd64": "https://.../energy-1.0.3-windows-amd64.zip"},
+//     "checksums": {"energy-1.0.3-linux-amd64.tar.gz": "sha256 hex"},
+//     "signatures": {"energy-1.0.3-linux-amd64.tar.gz": "base64 ed25519 signature"}
+//   }
+//  files: os/arch => 下载地址, 文件为执行文件或包含 energy(.exe) 的 zip, tar.gz
+//  必须配置 sha256 和签名, 公钥验证签名通过后替换当前执行文件, 见 verifyChecksum
+//  没有公钥时 (~/.energy/energy.json publicKey, consts.SignaturePublicKey) 拒绝升级
+//   --checksum-only: 只校验 sha256, upgrade.json 和执行文件必须使用 https 或本地镜像
+//  请求 upgrade.json 和下载使用 --proxy, --mirror
+
+// UpgradeInfo 版本比较结果
+type UpgradeInfo struct {
+	Current string `json:"current"` // 当前版本
+	Latest  string `json:"latest"`  // 最新版本
+	Notes   string `json:"notes"`   // 更新说明
+	URL     string `json:"url"`     // 当前系统的下载地址, 没有时为空
+	Newer   bool   `json:"newer"`   // 最新版本大于当前版本, tools.Compare
+}
+
+// 获取升级配置, 比较版本, 同时读取校验值
+func checkUpgrade(c *command.Config, current string) (*UpgradeInfo, error) {
+	config, err := requestJSONConfig(c, consts.CheckUpgradeURL, MirrorUpgradeFile)
+	if err != nil {
+		return nil, err
+	}
+	latest := tools.ToString(config["latest"])
+	if latest == "" {
+		return nil, errors.New(MirrorUpgradeFile + ": latest version not configured")
+	}
+	loadChecksums(c, config)
+	info := &UpgradeInfo{
+		Current: current,
+		Latest:  latest,
+		Notes:   tools.ToString(config["notes"]),
+		Newer:   tools.Compare(latest, current),
+	}
+	if files, ok := config["files"].(map[string]any); ok {
+		info.URL = tools.ToString(files[runtime.GOOS+"/"+runtime.GOARCH])
+	}
+	return info, nil
+}
+
+// 当前执行文件
+func executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// Upgrade 升级 energy 命令行
+//  --check: 只比较版本
+func Upgrade(c *command.Config) (err error) {
+	defer func() {
+		err = exitError(err)
+	}()
+	// 使用安装配置的镜像, 代理和校验, 必须验证签名
+	ic := *c
+	ic.Install = command.Install{Mirror: c.Upgrade.Mirror, Proxy: c.Upgrade.Proxy, VerifySignature: true}
+	if key, err := signaturePublicKey(c); err == nil && key == nil && c.Upgrade.ChecksumOnly {
+		// 没有签名时 upgrade.json 也必须来自 https 或本地镜像
+		if err = checksumOnlySource(&ic, consts.CheckUpgradeURL); err != nil {
+			return err
+		}
+	}
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	removeOldExecutable(exe)
+	info, err := checkUpgrade(&ic, term.CliVersion)
+	if err != nil {
+		return err
+	}
+	c.Result = info
+	if c.Upgrade.Check {
+		status := "up to date"
+		if info.Newer {
+			status = "upgrade available, run energy upgrade"
+		}
+		tableData := pterm.TableData{
+			{"Current", "Latest", "Status"},
+			{info.Current, info.Latest, status},
+		}
+		if err = pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithBoxed().WithData(tableData).Render(); err != nil {
+			return err
+		}
+		if info.Newer && info.Notes != "" {
+			term.Section.Println(info.Notes)
+		}
+		return nil
+	}
+	if !info.Newer && !c.Upgrade.Force {
+		term.Logger.Info("energy cli is up to date", term.Logger.Args("version", info.Current, "latest", info.Latest))
+		return nil
+	}
+	if info.URL == "" {
+		return fmt.Errorf("%s: no energy cli for %s/%s", MirrorUpgradeFile, runtime.GOOS, runtime.GOARCH)
+	}
+	term.Section.Println("Upgrade energy cli", info.Current, "=>", info.Latest)
+	if err = upgradeExecutable(&ic, exe, info.URL); err != nil {
+		return err
+	}
+	term.Logger.Info("Upgrade Successfully", term.Logger.Args("version", info.Latest, "path", exe))
+	return nil
+}
+
+// 下载, 校验并替换执行文件
+//  临时文件在执行文件目录, 替换时只需重命名
+//  升级配置没有签名, 或签名验证失败时返回 *SignatureError, 不替换执行文件
+func upgradeExecutable(c *command.Config, exe, downloadURL string) error {
+	name := urlName(downloadURL)
+	if c.Install.Checksums[name] == "" {
+		return fmt.Errorf("%s: no checksum configured for %s, refusing to replace the energy cli", MirrorUpgradeFile, name)
+	}
+	key, err := signaturePublicKey(c)
+	if err != nil {
+		return &SignatureError{File: name, Reason: err.Error()}
+	}
+	if key == nil && c.Upgrade.ChecksumOnly {
+		// 只校验 https 或本地镜像获取的 sha256
+		if err = checksumOnlySource(c, downloadURL); err != nil {
+			return err
+		}
+		term.Logger.Warn("--checksum-only: public key not configured, verify only the sha256 [" + name + "]")
+		delete(c.Install.Signatures, name)
+		c.Install.VerifySignature = false
+	} else {
+		if key == nil {
+			return &SignatureError{File: name, Reason: "public key not configured, set publicKey in ~/.energy/energy.json, or run with --checksum-only to verify only the sha256 over https"}
+		}
+		if c.Install.Signatures[name] == "" {
+			return &SignatureError{File: name, Reason: "no signature configured in " + MirrorUpgradeFile + ", refusing to replace the energy cli"}
+		}
+		// 升级配置未签名, 只有签名能证明执行文件可信
+		c.Install.VerifySignature = true
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(exe), ".energy-upgrade-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	pkg := filepath.Join(tmpDir, name)
+	if err = downloadFile(c, downloadURL, pkg); err != nil {
+		return err
+	}
+	if err = verifyChecksum(c, pkg); err != nil {
+		return err
+	}
+	newExe := filepath.Join(tmpDir, "energy.new")
+	if err = extractExecutable(pkg, newExe); err != nil {
+		return err
+	}
+	return replaceExecutable(exe, newExe)
+}
+
+// --checksum-only 时 sha256 的来源, 镜像和在线地址必须是 https, 本地镜像可以使用
+func checksumOnlySource(c *command.Config, url string) error {
+	source := c.Install.Mirror
+	if source == "" {
+		source = url
+	} else if !isRemoteMirror(source) {
+		return nil
+	}
+	if !strings.HasPrefix(source, "https://") {
+		return command.WithCode(command.ExitUsage, fmt.Errorf("--checksum-only requires https, %s", source))
+	}
+	return nil
+}
+
+// 压缩包中的执行文件名
+func isExecutableName(name string) bool {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	return name == "energy" || name == "energy.exe"
+}
+
+// 从 zip, tar.gz 中提取 energy 执行文件, 其它文件直接作为执行文件
+func extractExecutable(pkg, dst string) error {
+	switch {
+	case strings.HasSuffix(pkg, ".zip"):
+		zr, err := zip.OpenReader(pkg)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if !f.FileInfo().IsDir() && isExecutableName(f.Name) {
+				r, err := f.Open()
+				if err != nil {
+					return err
+				}
+				defer r.Close()
+				return writeExecutable(r, dst)
+			}
+		}
+	case strings.HasSuffix(pkg, ".tar.gz"):
+		tr, closeFn, err := tarFileReader(pkg)
+		if err != nil {
+			return err
+		}
+		defer closeFn()
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+			if header.Typeflag == tar.TypeReg && isExecutableName(header.Name) {
+				return writeExecutable(tr, dst)
+			}
+		}
+	default:
+		f, err := os.Open(pkg)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return writeExecutable(f, dst)
+	}
+	return fmt.Errorf("energy cli not found in %s", filepath.Base(pkg))
+}
+
+func writeExecutable(r io.Reader, dst string) error {
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
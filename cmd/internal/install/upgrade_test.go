@@ -0,0 +1,174 @@
+package install
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeMirror(t *testing.T) {
+	dir := t.TempDir()
+	mirror := filepath.Join(dir, "mirror")
+	os.MkdirAll(mirror, 0755)
+	// energy-1.0.3.tar.gz: bin/energy
+	pkg := filepath.Join(mirror, "energy-1.0.3.tar.gz")
+	f, _ := os.Create(pkg)
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	content := []byte("new energy")
+	tw.WriteHeader(&tar.Header{Name: "bin/energy", Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	gw.Close()
+	f.Close()
+	data, _ := os.ReadFile(pkg)
+	sum := sha256.Sum256(data)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, sum[:]))
+	writeManifest := func(checksum, signature string) {
+		manifest := fmt.Sprintf(`{"latest": "v1.0.3", "files": {"%s/%s": "https://example.com/cli/energy-1.0.3.tar.gz"}, "checksums": {"energy-1.0.3.tar.gz": "%s"}, "signatures": {"energy-1.0.3.tar.gz": "%s"}}`,
+			runtime.GOOS, runtime.GOARCH, checksum, signature)
+		os.WriteFile(filepath.Join(mirror, MirrorUpgradeFile), []byte(manifest), 0644)
+	}
+	newConfig := func() *command.Config {
+		c := &command.Config{}
+		c.Install.Mirror = mirror
+		c.EnergyCfg.PublicKey = base64.StdEncoding.EncodeToString(pub)
+		return c
+	}
+	exe := filepath.Join(dir, "bin", "energy")
+	os.MkdirAll(filepath.Dir(exe), 0755)
+	os.WriteFile(exe, []byte("old energy"), 0755)
+
+	// 校验失败时不替换
+	writeManifest("0000", signature)
+	c := newConfig()
+	info, err := checkUpgrade(c, "1.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Newer || info.Latest != "v1.0.3" || info.URL == "" {
+		t.Fatal("unexpected upgrade info", info)
+	}
+	if err = upgradeExecutable(c, exe, info.URL); err == nil {
+		t.Fatal("expected checksum error")
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old energy" {
+		t.Fatal("executable replaced after checksum error")
+	}
+
+	// 没有签名或签名错误时不替换
+	for _, sign := range []string{"", base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte("other")))} {
+		writeManifest(hex.EncodeToString(sum[:]), sign)
+		c = newConfig()
+		if info, err = checkUpgrade(c, "1.0.2"); err != nil {
+			t.Fatal(err)
+		}
+		var signatureErr *SignatureError
+		if err = upgradeExecutable(c, exe, info.URL); !errors.As(err, &signatureErr) {
+			t.Fatal("expected signature error", err)
+		}
+		if data, _ := os.ReadFile(exe); string(data) != "old energy" {
+			t.Fatal("executable replaced after signature error")
+		}
+	}
+
+	writeManifest(hex.EncodeToString(sum[:]), signature)
+	c = newConfig()
+	if info, err = checkUpgrade(c, "1.0.3"); err != nil || info.Newer {
+		t.Fatal("same version is not newer", info, err)
+	}
+	if err = upgradeExecutable(c, exe, info.URL); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new energy" {
+		t.Fatal("unexpected executable", string(data))
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(exe)); len(entries) != 1 {
+		t.Fatal("temporary files not removed", entries)
+	}
+}
+
+func TestUpgradeProxy(t *testing.T) {
+	var requested string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 代理请求使用完整地址
+		requested = r.URL.String()
+		w.Write([]byte(`{"latest": "v1.0.3"}`))
+	}))
+	defer proxy.Close()
+	c := &command.Config{}
+	c.Install.Mirror = "http://mirror.energy.invalid/cli"
+	c.Install.Proxy = proxy.URL
+	info, err := checkUpgrade(c, "1.0.2")
+	if err != nil || info.Latest != "v1.0.3" || requested != "http://mirror.energy.invalid/cli/"+MirrorUpgradeFile {
+		t.Fatal("upgrade.json not requested through the proxy", requested, info, err)
+	}
+}
+
+func TestUpgradeChecksumOnly(t *testing.T) {
+	dir := t.TempDir()
+	mirror := filepath.Join(dir, "mirror")
+	os.MkdirAll(mirror, 0755)
+	pkg := filepath.Join(mirror, "energy-99.0.0.tar.gz")
+	f, _ := os.Create(pkg)
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	content := []byte("new energy")
+	tw.WriteHeader(&tar.Header{Name: "energy", Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	gw.Close()
+	f.Close()
+	data, _ := os.ReadFile(pkg)
+	sum := sha256.Sum256(data)
+	manifest := fmt.Sprintf(`{"latest": "v99.0.0", "files": {"%s/%s": "https://example.com/cli/energy-99.0.0.tar.gz"}, "checksums": {"energy-99.0.0.tar.gz": "%s"}}`,
+		runtime.GOOS, runtime.GOARCH, hex.EncodeToString(sum[:]))
+	os.WriteFile(filepath.Join(mirror, MirrorUpgradeFile), []byte(manifest), 0644)
+
+	// 默认配置没有公钥, 下载前失败, 不会替换测试程序
+	c := &command.Config{}
+	c.Upgrade.Mirror = mirror
+	var signatureErr *SignatureError
+	if err := Upgrade(c); !errors.As(err, &signatureErr) || !strings.Contains(err.Error(), "--checksum-only") {
+		t.Fatal("expected signature error without public key", err)
+	}
+
+	// --checksum-only 只能使用 https 或本地镜像
+	c = &command.Config{}
+	c.Upgrade.Mirror = "http://mirror.energy.invalid/cli"
+	c.Upgrade.ChecksumOnly = true
+	if err := Upgrade(c); err == nil || !strings.Contains(err.Error(), "https") {
+		t.Fatal("expected https error", err)
+	}
+
+	exe := filepath.Join(dir, "bin", "energy")
+	os.MkdirAll(filepath.Dir(exe), 0755)
+	os.WriteFile(exe, []byte("old energy"), 0755)
+	c = &command.Config{}
+	c.Install.Mirror = mirror
+	c.Upgrade.ChecksumOnly = true
+	info, err := checkUpgrade(c, "1.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = upgradeExecutable(c, exe, info.URL); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new energy" {
+		t.Fatal("unexpected executable", string(data))
+	}
+}
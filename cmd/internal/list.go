@@ -0,0 +1,109 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 已安装框架列表
+
+package internal
+
+import (
+	"encoding/json"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/framework"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"github.com/pterm/pterm"
+	"os"
+	"path/filepath"
+)
+
+var CmdList = &command.Command{
+	UsageLine: "list -p [path]",
+	Short:     "List installed energy frameworks",
+	Long: `
+	List the frameworks installed by energy install
+	-p Project path, default current path. Marks the framework used by the project
+	.  Execute command
+
+	*  framework used globally: energy use [framework]
+	P  framework used by the project: energy use [framework] -p [path]
+`,
+}
+
+func init() {
+	CmdList.Run = runList
+}
+
+func runList(c *command.Config) error {
+	frameworks, err := framework.List()
+	if err != nil {
+		return err
+	}
+	c.Result = frameworks
+	if len(frameworks) == 0 {
+		term.Section.Println("No framework installed, see: energy install")
+		return nil
+	}
+	var current, projectFramework string
+	if f, err := framework.Current(); err == nil && f != nil {
+		current = f.Name
+	}
+	if proj, err := readProjectFramework(c.List.Path); err == nil {
+		projectFramework = proj
+	}
+	tableData := pterm.TableData{
+		{"", "Name", "Version", "CEF", "liblcl", "OS/Arch", "Path"},
+	}
+	for _, f := range frameworks {
+		var mark string
+		if f.Name == current {
+			mark += "*"
+		}
+		if f.Name == projectFramework || (projectFramework != "" && filepath.Clean(f.Path) == filepath.Clean(projectFramework)) {
+			mark += "P"
+		}
+		path := f.Path
+		if !f.Exists() {
+			path += " (missing)"
+		}
+		tableData = append(tableData, []string{mark, f.Name, f.Version, f.CEFVersion, f.Liblcl, f.OS + "/" + f.Arch, path})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithBoxed().WithData(tableData).Render()
+}
+
+// 项目 energy.json 指定的框架名称或目录
+func readProjectFramework(path string) (string, error) {
+	if path == "" {
+		path = tools.CurrentExecuteDir()
+	}
+	data, err := os.ReadFile(filepath.Join(path, consts.EnergyProjectConfig))
+	if err != nil {
+		return "", err
+	}
+	// 只读取框架配置, 不解析完整项目
+	var proj struct {
+		FrameworkPath    string `json:"frameworkPath"`
+		FrameworkVersion string `json:"frameworkVersion"`
+	}
+	if err = json.Unmarshal(data, &proj); err != nil {
+		return "", err
+	}
+	if proj.FrameworkPath != "" {
+		return proj.FrameworkPath, nil
+	}
+	if proj.FrameworkVersion != "" {
+		f, err := framework.Find(proj.FrameworkVersion)
+		if err != nil {
+			return "", err
+		}
+		return f.Name, nil
+	}
+	return "", nil
+}
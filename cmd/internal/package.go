@@ -11,26 +11,58 @@
 package internal
 
 import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/build"
 	"github.com/energye/energy/v2/cmd/internal/command"
 	"github.com/energye/energy/v2/cmd/internal/packager"
 	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/workspace"
+	"runtime"
 )
 
 var CmdPackage = &command.Command{
-	UsageLine: "package -p [path] -c [clean]",
+	UsageLine: "package -p [path] -c [clean] --format [formats] --target [os/arch] --dir [root] --projects [names]",
 	Short:     "Making an Installation Package",
 	Long: `
 	-p Project path, default current path. Can be configured in energy.json
 	-c Clear configuration and regenerate the default configuration
+	--format Linux package formats, comma separated: deb, rpm, appimage, tar.gz. default deb
+	--dpkg Using the dpkg command to create deb packages, default built-in writer
+	--dry-run Print the files, sizes and destination paths without creating the installation package
+	--target Package targets, os/arch separated by comma: windows/amd64,windows/386
+	  windows targets can be packaged on linux and macos, requires makensis (apt install nsis)
+	  executable: energy build --target output build/windows-[arch]/[name].exe
+	  framework: installed framework of the target, energy install --os=windows --arch=[arch]
+	  the windows overrides of energy.json are used, output: build/windows/[name]-windows-[arch]-installer.exe
+	--dir Workspace root, package all energy.json projects under it in parallel
+	--projects Workspace projects, names or relative paths separated by comma, default all
+	--jobs Number of projects packaged in parallel, default number of CPUs
 	.  Execute command
 
 Making an Installation Package
 	Windows: 
 		Creating an installation program using NSIS for Windows
 	Linux: 
-		Creating deb installation packages using dpkg
+		deb: built-in deb writer, dpkg is not required
+		rpm: pure Go rpm writer, install: sudo rpm -i
+		appimage: AppDir layout, packaged by appimagetool if installed
+		tar.gz: relocatable archive with a launcher script
 	MacOS:
 		Generate app package for energy
+
+Include and Exclude (energy.json nsis, dpkg, plist)
+	Paths are relative to the project (include) or the framework directory (exclude)
+	*, ?, [a-z] match within a path segment, ** matches any number of directories
+	Patterns starting with or containing / match from the root, others match at any depth
+	Patterns ending with / only match directories, a matched directory includes all its files
+	Patterns starting with ! are negated, the last matching pattern wins
+
+Workspace (energy.workspace.json in the monorepo root)
+	projects: project directories relative to the workspace, same patterns as include, default all
+	exclude: excluded directories, directories starting with . and node_modules are always skipped
+	defaults: energy.json defaults inherited by all projects, e.g. author, info.companyName, nsis.language
+	  project values win, objects are merged, name and projectPath are not inherited
 `,
 }
 
@@ -39,13 +71,73 @@ func init() {
 }
 
 func runPackage(c *command.Config) error {
-	if proj, err := project.NewProject(c.Package.Path); err != nil {
-		return err
-	} else {
-		proj.Clean = c.Package.Clean
-		proj.PList.Pkgbuild = c.Package.Pkgbuild
-		if err = packager.GeneraInstaller(proj); err != nil {
-			return err
+	if workspace.Enabled(c, c.Package.Projects) {
+		return workspace.Run(c, "package", c.Package.Projects, c.Package.Jobs)
+	}
+	if c.Package.Target != "" {
+		return packageTargets(c)
+	}
+	proj, err := newPackageProject(c, runtime.GOOS)
+	if err != nil {
+		return command.WithCode(command.ExitConfig, err)
+	}
+	if c.Package.DryRun {
+		files, err := packager.DryRun(proj)
+		c.Result = files
+		return command.WithCode(command.ExitPackage, err)
+	}
+	artifacts, err := packager.GeneraInstaller(proj)
+	c.Result = artifacts
+	return command.WithCode(command.ExitPackage, err)
+}
+
+// 读取目标系统的项目配置, 设置命令行参数
+func newPackageProject(c *command.Config, goos string) (*project.Project, error) {
+	proj, err := project.NewProjectOS(c.Package.Path, goos)
+	if err != nil {
+		return nil, err
+	}
+	proj.Clean = c.Package.Clean
+	proj.PList.Pkgbuild = c.Package.Pkgbuild
+	if c.Package.Format != "" {
+		proj.Dpkg.Format = c.Package.Format
+	}
+	if c.Package.Dpkg {
+		proj.Dpkg.UseDpkg = true
+	}
+	return proj, nil
+}
+
+// 打包 --target 的所有目标
+func packageTargets(c *command.Config) error {
+	targets, err := build.ParseTargets(c.Package.Target)
+	if err != nil {
+		return command.WithCode(command.ExitUsage, err)
+	}
+	var (
+		artifacts []*packager.Artifact
+		files     []*project.PackageFile
+	)
+	for _, t := range targets {
+		term.Section.Println("Packaging", t.String())
+		proj, err := newPackageProject(c, t.OS)
+		if err != nil {
+			return command.WithCode(command.ExitConfig, err)
+		}
+		if c.Package.DryRun {
+			result, err := packager.TargetDryRun(proj, t)
+			files = append(files, result...)
+			c.Result = files
+			if err != nil {
+				return command.WithCode(command.ExitPackage, err)
+			}
+			continue
+		}
+		result, err := packager.TargetInstaller(proj, t)
+		artifacts = append(artifacts, result...)
+		c.Result = artifacts
+		if err != nil {
+			return command.WithCode(command.ExitPackage, fmt.Errorf("%s: %w", t, err))
 		}
 	}
 	return nil
@@ -0,0 +1,182 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package packager
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// deb 安装包, 不依赖 dpkg
+//  ar 归档: debian-binary, control.tar.gz, data.tar.gz
+//  目录结构和 dpkg -b 相同, DEBIAN 为控制文件目录, 其它为安装文件
+//  文件时间固定, 所有者 root, 相同输入生成相同安装包
+
+const deb = "DEBIAN"
+
+// 维护脚本, 权限 0755
+var debScripts = map[string]bool{
+	"preinst":  true,
+	"postinst": true,
+	"prerm":    true,
+	"postrm":   true,
+	"config":   true,
+}
+
+// writeDeb 写入 deb 安装包, root 目录和 dpkg -b 参数相同
+func writeDeb(w io.Writer, root string, mtime time.Time) error {
+	// data.tar.gz 写入临时文件, 框架文件较大
+	data, err := os.CreateTemp("", "energy-deb-data-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		data.Close()
+		os.Remove(data.Name())
+	}()
+	var md5sums bytes.Buffer
+	tgz, err := newTarGz(data, mtime)
+	if err != nil {
+		return err
+	}
+	tgz.md5sums = &md5sums
+	if err = tgz.AddDir(root, ".", func(rel string) bool {
+		return rel == deb
+	}); err != nil {
+		return err
+	}
+	if err = tgz.Close(); err != nil {
+		return err
+	}
+	control, err := debControlTarGz(filepath.Join(root, deb), md5sums.Bytes(), mtime)
+	if err != nil {
+		return err
+	}
+	dataSize, err := data.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = data.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err = io.WriteString(w, "!<arch>\n"); err != nil {
+		return err
+	}
+	if err = writeArEntry(w, "debian-binary", mtime, 4, strings.NewReader("2.0\n")); err != nil {
+		return err
+	}
+	if err = writeArEntry(w, "control.tar.gz", mtime, int64(len(control)), bytes.NewReader(control)); err != nil {
+		return err
+	}
+	return writeArEntry(w, "data.tar.gz", mtime, dataSize, data)
+}
+
+// control.tar.gz, DEBIAN 目录文件, md5sums 和 conffiles
+//  conffiles: DEBIAN/conffiles 和 /etc 下的文件
+func debControlTarGz(controlDir string, md5sums []byte, mtime time.Time) ([]byte, error) {
+	entries, err := os.ReadDir(controlDir)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		buf       bytes.Buffer
+		conffiles []string
+		exists    = make(map[string]bool)
+	)
+	var addConffile = func(name string) {
+		if name != "" && !exists[name] {
+			exists[name] = true
+			conffiles = append(conffiles, name)
+		}
+	}
+	tgz, err := newTarGz(&buf, mtime)
+	if err != nil {
+		return nil, err
+	}
+	h := tgz.header("./", os.ModeDir)
+	h.Typeflag = tar.TypeDir
+	if err = tgz.tw.WriteHeader(h); err != nil {
+		return nil, err
+	}
+	var hasControl bool
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == "md5sums" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(controlDir, name))
+		if err != nil {
+			return nil, err
+		}
+		if name == "conffiles" {
+			for _, line := range strings.Split(string(content), "\n") {
+				addConffile(strings.TrimSpace(line))
+			}
+			continue
+		}
+		mode := os.FileMode(0644)
+		if debScripts[name] {
+			mode = 0755
+		}
+		hasControl = hasControl || name == "control"
+		if err = tgz.AddFile("./"+name, mode, content); err != nil {
+			return nil, err
+		}
+	}
+	if !hasControl {
+		return nil, fmt.Errorf("%s/control not found", deb)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(md5sums))
+	for scanner.Scan() {
+		// [md5]  [path]
+		if fields := strings.SplitN(scanner.Text(), "  ", 2); len(fields) == 2 && strings.HasPrefix(fields[1], "etc/") {
+			addConffile("/" + fields[1])
+		}
+	}
+	if len(conffiles) > 0 {
+		if err = tgz.AddFile("./conffiles", 0644, []byte(strings.Join(conffiles, "\n")+"\n")); err != nil {
+			return nil, err
+		}
+	}
+	if err = tgz.AddFile("./md5sums", 0644, md5sums); err != nil {
+		return nil, err
+	}
+	if err = tgz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ar 文件头和数据, 2字节对齐
+func writeArEntry(w io.Writer, name string, mtime time.Time, size int64, data io.Reader) error {
+	header := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8s%-10d`\n", name, mtime.Unix(), 0, 0, "100644", size)
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	n, err := io.Copy(w, data)
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("%s: size changed during packaging", name)
+	}
+	if size%2 != 0 {
+		_, err = w.Write([]byte{'\n'})
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package packager
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteDeb(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{deb, "opt/company/demo", "etc/demo"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	control := "Package: demo\nVersion: 1.0.0\nArchitecture: amd64\nMaintainer: demo <demo@example.com>\nDescription: demo\n"
+	os.WriteFile(filepath.Join(root, deb, "control"), []byte(control), 0644)
+	os.WriteFile(filepath.Join(root, deb, "postinst"), []byte("#!/bin/sh\nexit 0\n"), 0644)
+	os.WriteFile(filepath.Join(root, "opt/company/demo/demo"), []byte("demo"), 0700)
+	os.WriteFile(filepath.Join(root, "etc/demo/demo.conf"), []byte("a=1\n"), 0600)
+
+	mtime := time.Unix(1700000000, 0)
+	var first, second bytes.Buffer
+	if err := writeDeb(&first, root, mtime); err != nil {
+		t.Fatal(err)
+	}
+	// 权限不同, 内容相同
+	os.Chmod(filepath.Join(root, "opt/company/demo/demo"), 0755)
+	if err := writeDeb(&second, root, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Fatal("deb output is not reproducible")
+	}
+	if !bytes.HasPrefix(first.Bytes(), []byte("!<arch>\ndebian-binary   ")) {
+		t.Fatalf("bad ar header %q", first.Bytes()[:24])
+	}
+	if _, err := exec.LookPath("dpkg-deb"); err != nil {
+		return
+	}
+	file := filepath.Join(t.TempDir(), "demo.deb")
+	os.WriteFile(file, first.Bytes(), 0644)
+	out, err := exec.Command("dpkg-deb", "--contents", file).CombinedOutput()
+	if err != nil {
+		t.Fatalf("dpkg-deb --contents: %v %s", err, out)
+	}
+	var modes = make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		modes[fields[len(fields)-1]] = fields[0] + " " + fields[1]
+	}
+	for name, want := range map[string]string{"./opt/company/demo/demo": "-rwxr-xr-x root/root", "./etc/demo/demo.conf": "-rw-r--r-- root/root", "./opt/": "drwxr-xr-x root/root"} {
+		if modes[name] != want {
+			t.Errorf("%s mode %q, want %q", name, modes[name], want)
+		}
+	}
+	dir := t.TempDir()
+	if out, err = exec.Command("dpkg-deb", "--control", file, dir).CombinedOutput(); err != nil {
+		t.Fatalf("dpkg-deb --control: %v %s", err, out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "conffiles")); string(data) != "/etc/demo/demo.conf\n" {
+		t.Errorf("conffiles %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "md5sums")); !strings.Contains(string(data), "fe01ce2a7fbac8fafaed7c982a04e229  opt/company/demo/demo") {
+		t.Errorf("md5sums %q", data)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "postinst")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("postinst mode %v %v", info, err)
+	}
+}
@@ -0,0 +1,214 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// NSIS windows 安装包
+//  makensis 在 windows, linux, macos 都可以使用, linux: apt install nsis, macos: brew install makensis
+//  生成 build/windows/installer-nsis.nsi 和 installer-tools.nsh, 调用 makensis 生成安装包
+//  nsis.compress 为 7z, 7za 或 7zz 时使用已安装的 7z 命令压缩框架, 安装时 Nsis7z 插件解压
+
+package packager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/assets"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	windowsNsis      = "windows/installer-nsis.nsi"
+	windowsNsisTools = "windows/installer-tools.nsh"
+)
+
+// 生成 NSIS 安装包, exe 为应用执行文件, 返回安装包路径
+func nsisInstaller(proj *project.Project, exe string) (string, error) {
+	if !tools.CommandExists("makensis") {
+		return "", errors.New("failed to create application installation program. Could not find the makensis command")
+	}
+	var err error
+	// 创建构建输出目录
+	buildOutDir := assets.BuildOutPath(proj)
+	buildOutDir = filepath.Join(buildOutDir, "windows")
+	if !tools.IsExist(buildOutDir) {
+		if err := os.MkdirAll(buildOutDir, 0755); err != nil {
+			return "", fmt.Errorf("unable to create directory: %w", err)
+		}
+	}
+	// 7z 压缩 CEF
+	compress := compressCommand(proj.NSIS.Compress)
+	proj.NSIS.UseCompress = compress != ""
+	if proj.NSIS.UseCompress {
+		if cef7zFile, err := compressCEF7za(proj, compress, exe); err != nil {
+			return "", err
+		} else {
+			proj.NSIS.CompressFile = cef7zFile
+		}
+	}
+
+	// 生成 nsis 脚本
+	if err = windows(proj, exe); err != nil {
+		return "", err
+	}
+
+	// make
+	return makeNSIS(proj)
+}
+
+// 7z 压缩命令, 配置的命令不存在时使用其它已安装的 7z 命令, 未配置或未安装时返回空
+func compressCommand(compress string) string {
+	switch compress {
+	case "7z", "7za", "7zz":
+	default:
+		return ""
+	}
+	for _, name := range []string{compress, "7zz", "7za", "7z"} {
+		if tools.CommandExists(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+func compressCEF7za(proj *project.Project, compress, exe string) (string, error) {
+	term.Logger.Info(compress + " compress " + proj.NSIS.CompressName + ", This may take some time")
+	buildWindowsPath := filepath.Join(assets.BuildOutPath(proj), "windows")
+	outFilePath := filepath.Join(buildWindowsPath, proj.NSIS.CompressName)
+	if proj.Clean {
+		os.Remove(outFilePath)
+	} else if tools.IsExist(outFilePath) {
+		term.Logger.Info(proj.NSIS.CompressName + " file exist")
+		return outFilePath, nil
+	}
+
+	// 7z 文件列表, 相对框架目录, 排除 NSIS.Exclude
+	_, framework, _, err := nsisFiles(proj, exe)
+	if err != nil {
+		return "", err
+	}
+	var list strings.Builder
+	for _, f := range framework {
+		rel, err := filepath.Rel(proj.FrameworkPath, f.Source)
+		if err != nil {
+			return "", err
+		}
+		list.WriteString(rel + "\n")
+	}
+	listFile := filepath.Join(buildWindowsPath, "cef-files.txt")
+	if err = os.WriteFile(listFile, []byte(list.String()), 0644); err != nil {
+		return "", err
+	}
+	defer os.Remove(listFile)
+	cmd := exec.Command(compress, "a", outFilePath, "@"+listFile)
+	cmd.Dir = proj.FrameworkPath
+	if data, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(outFilePath)
+		return "", fmt.Errorf("%s: %v\n%s", compress, err, strings.TrimSpace(string(data)))
+	}
+	return outFilePath, nil
+}
+
+func windows(proj *project.Project, exe string) error {
+	term.Logger.Info("Generate NSIS script")
+	// 生成安装生成配置文件 nsis.nsi
+	if nsisData, err := assets.ReadFile(proj, assetsFSPath, windowsNsis); err != nil {
+		return err
+	} else {
+		if err = assets.WriteFile(proj, windowsNsis, nsisData); err != nil {
+			return err
+		}
+	}
+	// tools.nsh
+	if toolsData, err := assets.ReadFile(proj, assetsFSPath, windowsNsisTools); err != nil {
+		return err
+	} else {
+		data := make(map[string]any)
+		data["Name"] = proj.Name
+		data["ProjectPath"] = filepath.FromSlash(proj.ProjectPath)
+		data["FrameworkPath"] = filepath.FromSlash(proj.FrameworkPath)
+		data["Exe"] = exe
+		proj.Info.FromSlash()
+		proj.NSIS.FromSlash()
+		data["Info"] = proj.Info
+		data["NSIS"] = proj.NSIS
+		// 框架和 NSIS.Include 文件, 框架已压缩时只有 NSIS.Include
+		_, framework, include, err := nsisFiles(proj, exe)
+		if err != nil {
+			return err
+		}
+		if proj.NSIS.CompressFile != "" {
+			framework = nil
+		}
+		var files []nsisFile
+		for _, f := range append(framework, include...) {
+			files = append(files, nsisFile{Dir: installPath(path.Dir(f.Target)), Source: filepath.FromSlash(f.Source)})
+		}
+		data["Files"] = files
+		content, err := tools.RenderTemplate(string(toolsData), data)
+		if err != nil {
+			return err
+		}
+		// 文件列表每次重新生成, 执行文件和框架可能不同
+		if err = os.WriteFile(filepath.Join(assets.BuildOutPath(proj), windowsNsisTools), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NSIS File 指令
+type nsisFile struct {
+	Dir    string // 安装目录, $INSTDIR\[dir]
+	Source string // 当前系统路径
+}
+
+// 安装目录使用 windows 路径, 在其它系统生成时不使用 filepath
+func installPath(p string) string {
+	return strings.ReplaceAll(p, "/", `\`)
+}
+
+// 安装包文件, 目标路径 $INSTDIR/
+//  执行文件, 框架 (排除 NSIS.Exclude), NSIS.Include
+func nsisFiles(proj *project.Project, exe string) (exeFiles, framework, include []*project.PackageFile, err error) {
+	if !tools.IsExist(exe) {
+		return nil, nil, nil, fmt.Errorf("execution file not found: %s", exe)
+	}
+	if exeFiles, err = project.Files(exe, "$INSTDIR", nil); err != nil {
+		return
+	}
+	if framework, err = project.Files(proj.FrameworkPath, "$INSTDIR", proj.NSIS.Exclude); err != nil {
+		return
+	}
+	include, err = project.IncludeFiles(proj.ProjectPath, "$INSTDIR", proj.NSIS.Include, proj.NSIS.Exclude)
+	return
+}
+
+// 使用nsis生成安装包
+func makeNSIS(proj *project.Project) (string, error) {
+	installPackage := proj.Name + "-installer.exe"
+	term.Logger.Info("NSIS Making Installation, Almost complete", term.Logger.Args("Install Package", installPackage))
+	nsisScriptPath := filepath.Join(assets.BuildOutPath(proj), windowsNsis)
+	cmd := exec.Command("makensis", nsisScriptPath)
+	cmd.Dir = proj.ProjectPath
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("makensis: %v\n%s", err, strings.TrimSpace(out.String()))
+	}
+	outInstall := filepath.Join(filepath.Dir(nsisScriptPath), installPackage)
+	return outInstall, nil
+}
@@ -0,0 +1,62 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package packager
+
+import (
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNSISScript(t *testing.T) {
+	root := t.TempDir()
+	proj := &project.Project{
+		Name:          "demo",
+		ProjectPath:   filepath.Join(root, "demo"),
+		FrameworkPath: filepath.Join(root, "framework"),
+		AssetsDir:     filepath.Join(root, "assets"),
+		NSIS:          project.NSIS{Exclude: []string{"cache"}, Language: "English"},
+	}
+	exe := filepath.Join(proj.ProjectPath, "build", "windows-amd64", "demo.exe")
+	for _, file := range []string{exe, filepath.Join(proj.FrameworkPath, "libcef.dll"), filepath.Join(proj.FrameworkPath, "locales", "en-US.pak"), filepath.Join(proj.FrameworkPath, "cache", "x")} {
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.MkdirAll(filepath.Join(proj.ProjectPath, "build", "windows"), 0755)
+	if err := windows(proj, exe); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(proj.ProjectPath, "build", "windows", "installer-tools.nsh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(data)
+	for _, want := range []string{
+		`File "/oname=${PRODUCT_EXECUTABLE}" "` + exe + `"`,
+		`SetOutPath "$INSTDIR\locales"`,
+		`File "` + filepath.Join(proj.FrameworkPath, "locales", "en-US.pak") + `"`,
+		`!define ENERGY_LANGUAGE "English"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("installer-tools.nsh missing %s\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "cache") {
+		t.Error("excluded file in installer-tools.nsh")
+	}
+	if _, err = os.Stat(filepath.Join(proj.ProjectPath, "build", "windows", "installer-nsis.nsi")); err != nil {
+		t.Fatal(err)
+	}
+}
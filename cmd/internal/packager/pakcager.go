@@ -10,6 +10,100 @@
 
 package packager
 
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"github.com/pterm/pterm"
+	"io"
+	"os"
+	"path/filepath"
+)
+
 const (
 	assetsFSPath = "assets/packager/"
 )
+
+// Artifact 生成的安装包, --output json 输出
+type Artifact struct {
+	Format string `json:"format"` // deb, rpm, appimage, tar.gz, nsis, app, pkg
+	Path   string `json:"path"`
+	Size   int64  `json:"size"` // 目录为 0
+}
+
+func newArtifact(format, path string) *Artifact {
+	artifact := &Artifact{Format: format, Path: path}
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		artifact.Size = info.Size()
+	}
+	return artifact
+}
+
+// DryRun 输出将要打包的文件, 不生成安装包
+func DryRun(proj *project.Project) ([]*project.PackageFile, error) {
+	files, err := packageFiles(proj)
+	if err != nil {
+		return nil, err
+	}
+	return files, printPackageFiles(files)
+}
+
+func printPackageFiles(files []*project.PackageFile) error {
+	tableData := pterm.TableData{
+		{"Source", "Destination", "Size"},
+	}
+	var total int64
+	for _, f := range files {
+		total += f.Size
+		tableData = append(tableData, []string{f.Source, f.Target, formatSize(f.Size)})
+	}
+	term.Section.Println("Package Files")
+	if err := pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithBoxed().WithData(tableData).Render(); err != nil {
+		return err
+	}
+	term.Section.Println(fmt.Sprintf("%d files, %s", len(files), formatSize(total)))
+	return nil
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.2f MB", float64(size)/1024/1024)
+	case size >= 1024:
+		return fmt.Sprintf("%.2f KB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// 复制文件到 root/目标路径, 大小相同时跳过
+func copyPackageFiles(files []*project.PackageFile, root string) error {
+	for _, f := range files {
+		target := filepath.Join(root, filepath.FromSlash(f.Target))
+		if tools.IsExistAndSize(target, f.Size) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := copyFile(f.Source, target, f.Mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+	_, err = io.Copy(dstFile, srcFile)
+	return err
+}
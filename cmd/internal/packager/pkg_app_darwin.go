@@ -14,17 +14,16 @@
 package packager
 
 import (
-	"errors"
 	"fmt"
 	"github.com/energye/energy/v2/cmd/internal/assets"
-	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/icons"
 	"github.com/energye/energy/v2/cmd/internal/project"
 	"github.com/energye/energy/v2/cmd/internal/term"
 	"github.com/energye/energy/v2/cmd/internal/tools"
 	"github.com/energye/golcl/tools/command"
 	"io"
-	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -48,19 +47,7 @@ const (
 )
 
 var (
-	pkgInfo  = []byte{0x41, 0x50, 0x50, 0x4C, 0x3F, 0x3F, 0x3F, 0x3F, 0x0D, 0x0A}
-	sipsCmds = []string{
-		"-z 16 16 %s -o icons.iconset/%s_16x16.png",
-		"-z 32 32 %s -o icons.iconset/%s_16x16@2x.png",
-		"-z 32 32 %s -o icons.iconset/%s_32x32.png",
-		"-z 64 64 %s -o icons.iconset/%s_32x32@2x.png",
-		"-z 128 128 %s -o icons.iconset/%s_128x128.png",
-		"-z 256 256 %s -o icons.iconset/%s_128x128@2x.png",
-		"-z 256 256 %s -o icons.iconset/%s_256x256.png",
-		"-z 512 512 %s -o icons.iconset/%s_256x256@2x.png",
-		"-z 512 512 %s -o icons.iconset/%s_512x512.png",
-		"-z 1024 1024 %s -o icons.iconset/%s_512x512@2x.png",
-	}
+	pkgInfo = []byte{0x41, 0x50, 0x50, 0x4C, 0x3F, 0x3F, 0x3F, 0x3F, 0x0D, 0x0A}
 	helpers = []string{
 		macCefHelper,
 		macCefHelperGpu,
@@ -70,136 +57,84 @@ var (
 	projectPath string
 )
 
-func GeneraInstaller(proj *project.Project) error {
+func GeneraInstaller(proj *project.Project) ([]*Artifact, error) {
 	appRoot := fmt.Sprintf("darwin/%s.app", proj.Name)
 	buildOutDir := assets.BuildOutPath(proj)
 	buildOutDir = filepath.Join(buildOutDir, appRoot)
 	projectPath = proj.ProjectPath
 	if !tools.IsExist(buildOutDir) {
 		if err := os.MkdirAll(buildOutDir, 0755); err != nil {
-			return fmt.Errorf("unable to create directory: %w", err)
+			return nil, fmt.Errorf("unable to create directory: %w", err)
 		}
 	}
 	if err := createApp(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	if err := generateICNS(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	if err := createAppInfoPList(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	if err := createAppPkgInfo(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	if err := copyFrameworkFile(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	if err := copyHelperFile(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	if proj.PList.Pkgbuild {
 		if err := pkgbuild(proj, appRoot); err != nil {
-			return err
+			return nil, err
 		}
+		// pkgbuild 后删除 .app
+		return []*Artifact{newArtifact("pkg", filepath.Join(filepath.Dir(buildOutDir), proj.Name+".pkg"))}, nil
 	}
-	return nil
+	return []*Artifact{newArtifact("app", buildOutDir)}, nil
 }
 
-func copyFiles(proj *project.Project, src, dst string) error {
-	if srcFile, err := os.Open(src); err != nil {
-		return err
-	} else {
-		st, err := srcFile.Stat()
-		if err != nil {
-			return err
-		}
-		if st.IsDir() {
-			srcFile.Close() //close
-			var pathLen = len(src)
-			err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
-				if path == src { // current root
-					return nil
-				}
-				outPath := path[pathLen:]
-				// exclude file or dir
-				for _, p := range proj.PList.Exclude {
-					if strings.Contains(outPath, p) {
-						return nil
-					}
-				}
-				targetPath := filepath.Join(dst, outPath)
-				info, _ := d.Info()
-				if d.IsDir() {
-					return os.MkdirAll(targetPath, info.Mode())
-				} else {
-					if tools.IsExistAndSize(targetPath, info.Size()) {
-						//term.Logger.Info("\tcopy skip: " + outPath)
-						return nil
-					}
-					srcFile, err := os.Open(path)
-					if err != nil {
-						return err
-					}
-					defer srcFile.Close()
-					dstFile, err := os.OpenFile(targetPath, os.O_CREATE|os.O_WRONLY, info.Mode())
-					if err != nil {
-						return err
-					}
-					defer dstFile.Close()
-					//term.Logger.Info("\tcopy: " + outPath)
-					_, err = io.Copy(dstFile, srcFile)
-					return err
-				}
-			})
-			if err != nil {
-				return err
-			}
-		} else {
-			defer srcFile.Close()
-			dstFilePath := filepath.Join(dst, st.Name())
-			dstFile, err := os.OpenFile(dstFilePath, os.O_CREATE|os.O_WRONLY, st.Mode())
-			if err != nil {
-				return err
-			}
-			defer dstFile.Close()
-			_, err = io.Copy(dstFile, srcFile)
-			if err != nil {
-				return err
-			}
-		}
-	}
-	return nil
-}
-
-func copyFrameworkFile(proj *project.Project, appRoot string) error {
-	term.Logger.Info("Generate app copy framework:",
-		term.Logger.Args("company", proj.Info.CompanyName, "product", proj.Info.ProductName))
-	buildOutDir := assets.BuildOutPath(proj)
-	appDir := filepath.Join(buildOutDir, appRoot)
-	// Contents
-	contents := filepath.Join(appDir, appContents)
+// 安装包文件, 目标路径 [name].app/Contents/
+//  MacOS: 执行文件, Frameworks: 框架 (排除 PList.Exclude), Resources: PList.Include
+func packageFiles(proj *project.Project) ([]*project.PackageFile, error) {
+	contents := path.Join(proj.Name+".app", appContents)
 	exeDir := filepath.Join(proj.ProjectPath, proj.OutputFilename)
 	if !tools.IsExist(exeDir) {
-		return fmt.Errorf("execution file not found: %s", exeDir)
+		return nil, fmt.Errorf("execution file not found: %s", exeDir)
 	}
-	cefDir := os.Getenv(consts.EnergyHomeKey)
+	cefDir := proj.FrameworkPath
 	if !tools.IsExist(cefDir) {
-		return fmt.Errorf("%s not found: %s", consts.EnergyHomeKey, cefDir)
+		return nil, fmt.Errorf("energy framework not found: %s", cefDir)
 	}
-	term.Logger.Info("Generate app copy:", term.Logger.Args("execution", exeDir))
 	// Contents/MacOS/exe
-	outExe := filepath.Join(contents, appContentsMacOS)
-	if err := copyFiles(proj, exeDir, outExe); err != nil {
-		return err
+	files, err := project.Files(exeDir, path.Join(contents, appContentsMacOS), nil)
+	if err != nil {
+		return nil, err
 	}
-	term.Logger.Info("Generate app copy:", term.Logger.Args("framework", cefDir))
 	// Contents/Frameworks/cef
-	outCEF := filepath.Join(contents, appContentsFrameworks)
-	if err := copyFiles(proj, cefDir, outCEF); err != nil {
+	framework, err := project.Files(cefDir, path.Join(contents, appContentsFrameworks), proj.PList.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	// Contents/Resources
+	include, err := project.IncludeFiles(proj.ProjectPath, path.Join(contents, appContentsResources), proj.PList.Include, proj.PList.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, framework...)
+	return append(files, include...), nil
+}
+
+func copyFrameworkFile(proj *project.Project, appRoot string) error {
+	term.Logger.Info("Generate app copy framework:",
+		term.Logger.Args("company", proj.Info.CompanyName, "product", proj.Info.ProductName))
+	files, err := packageFiles(proj)
+	if err != nil {
 		return err
 	}
-	return nil
+	term.Logger.Info("Generate app copy:", term.Logger.Args("execution", proj.OutputFilename, "framework", proj.FrameworkPath, "files", len(files)))
+	return copyPackageFiles(files, filepath.Join(assets.BuildOutPath(proj), "darwin"))
 }
 
 // pkgbuild --root demo.app --identifier com.demo.demo --version 1.0.0 --install-location /Applications/demo.app demo.pkg
@@ -326,69 +261,31 @@ func generateICNS(proj *project.Project, appRoot string) error {
 	iconExt := strings.ToLower(filepath.Ext(proj.PList.Icon))
 	tmpWorkDir := filepath.Join(buildOutDir, "tmp")
 	os.Remove(tmpWorkDir)
-	if iconExt == ".png" {
-		term.Logger.Info("\tcreate icnns")
-		src, err := os.Open(proj.PList.Icon)
-		if err != nil {
-			return err
-		}
-		var closeSrc = func() {
-			if src != nil {
-				src.Close()
-				src = nil
-			}
-		}
-		defer closeSrc()
-		_, icnsName := filepath.Split(proj.PList.Icon)
-		os.MkdirAll(tmpWorkDir, fs.ModePerm)
-
-		outIconsetPath := filepath.Join(tmpWorkDir, "icons.iconset")
-		os.MkdirAll(outIconsetPath, fs.ModePerm)
-		tmpIconFilePath := filepath.Join(tmpWorkDir, icnsName)
-		dst, err := os.OpenFile(tmpIconFilePath, os.O_CREATE|os.O_WRONLY, 0755)
+	if iconExt != ".icns" {
+		// png => icns
+		term.Logger.Info("\tcreate icns")
+		img, err := icons.Load(proj.PList.Icon)
 		if err != nil {
 			return err
 		}
-		io.Copy(dst, src)
-		dst.Close()
-		closeSrc()
-		name := icnsName[:len(icnsName)-4]
-		// 生成图标
-		cmd := command.NewCMD()
-		cmd.Dir = tmpWorkDir
-		cmd.IsPrint = false
-		cmd.MessageCallback = func(bytes []byte, e error) {
-			if e != nil {
-				err = e
-			}
-		}
-		for _, arg := range sipsCmds {
-			cmd.Command("sips", strings.Split(fmt.Sprintf(arg, icnsName, name), " ")...)
-		}
-		cmd.Command("iconutil", strings.Split(fmt.Sprintf("-c icns icons.iconset -o %s.icns", name), " ")...)
-		cmd.Close()
-		if err != nil {
+		_, iconName := filepath.Split(proj.PList.Icon)
+		icnsFile := filepath.Join(tmpWorkDir, strings.TrimSuffix(iconName, filepath.Ext(iconName))+".icns")
+		if err = icons.ICNS(img, icnsFile); err != nil {
 			return err
 		}
-		proj.PList.Icon = filepath.Join(tmpWorkDir, name+".icns")
+		proj.PList.Icon = icnsFile
 	}
-	iconExt = strings.ToLower(filepath.Ext(proj.PList.Icon))
-	if iconExt == ".icns" {
-		term.Logger.Info("\tcopy icns")
-		// Contents
-		contents := filepath.Join(appDir, appContents)
-		// Contents/Resources
-		_, icnsName := filepath.Split(proj.PList.Icon)
-		outIcnsFilePath := filepath.Join(contents, appContentsResources)
-		err := copyFiles(proj, proj.PList.Icon, outIcnsFilePath)
-		if err != nil {
-			return err
-		}
-		// 设置icon文件名称
-		proj.PList.Icon = icnsName
-	} else {
-		return errors.New("app icon, only supports png or icns")
+	term.Logger.Info("\tcopy icns")
+	// Contents
+	contents := filepath.Join(appDir, appContents)
+	// Contents/Resources
+	_, icnsName := filepath.Split(proj.PList.Icon)
+	outIcnsFilePath := filepath.Join(contents, appContentsResources, icnsName)
+	if err := copyFile(proj.PList.Icon, outIcnsFilePath, 0644); err != nil {
+		return err
 	}
+	// 设置icon文件名称
+	proj.PList.Icon = icnsName
 	return nil
 }
 
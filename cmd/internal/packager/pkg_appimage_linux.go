@@ -0,0 +1,159 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+//go:build linux
+// +build linux
+
+package packager
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/assets"
+	"github.com/energye/energy/v2/cmd/internal/build"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// 生成 AppImage
+//  AppDir 目录
+//    AppRun                启动脚本
+//    [name].desktop
+//    [icon], .DirIcon
+//    usr/lib/[name]/       应用和框架
+//    usr/share/icons/hicolor/
+//  安装 appimagetool 时生成 [name]-[arch].AppImage, 否则只生成 AppDir
+func linuxAppImage(proj *project.Project, appRoot string) (string, error) {
+	linuxDir := filepath.Join(assets.BuildOutPath(proj), "linux")
+	optDir := filepath.Join(assets.BuildOutPath(proj), appRoot, opt(proj))
+	appDir := filepath.Join(linuxDir, proj.Name+".AppDir")
+	term.Logger.Info("Generate AppImage AppDir", term.Logger.Args("AppDir", appDir))
+	if err := os.RemoveAll(appDir); err != nil {
+		return "", err
+	}
+	libDir := filepath.ToSlash(filepath.Join("usr", "lib", proj.Name))
+	if err := copyTree(optDir, filepath.Join(appDir, libDir), skipOptStartup(proj)); err != nil {
+		return "", err
+	}
+	// AppRun
+	appRun, err := linuxLauncherSH(proj, libDir)
+	if err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(filepath.Join(appDir, "AppRun"), appRun, 0755); err != nil {
+		return "", err
+	}
+	// 图标, desktop Icon 不带扩展名, 优先使用 256 hicolor 图标
+	icon := proj.Name + ".png"
+	iconFile := hicolorIcon(proj, appRoot, 256)
+	if tools.IsExist(iconFile) {
+		hicolor := filepath.Join(assets.BuildOutPath(proj), appRoot, usrShareHicolor)
+		if err = copyTree(hicolor, filepath.Join(appDir, usrShareHicolor), nil); err != nil {
+			return "", err
+		}
+	} else {
+		iconFile = filepath.Join(optDir, filepath.Base(proj.Info.Icon))
+		icon = filepath.Base(proj.Info.Icon)
+	}
+	iconName := strings.TrimSuffix(icon, filepath.Ext(icon))
+	if err = copyTree(iconFile, filepath.Join(appDir, icon), nil); err != nil {
+		return "", err
+	}
+	if err = os.Symlink(icon, filepath.Join(appDir, ".DirIcon")); err != nil {
+		return "", err
+	}
+	// desktop
+	desktopData, err := assets.ReadFile(proj, assetsFSPath, linuxAppDesktop)
+	if err != nil {
+		return "", err
+	}
+	data := make(map[string]any)
+	data["Name"] = proj.Name
+	data["Exec"] = proj.Name
+	data["Icon"] = iconName
+	data["Comments"] = proj.Info.Comments
+	content, err := tools.RenderTemplate(string(desktopData), data)
+	if err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(filepath.Join(appDir, proj.Name+".desktop"), content, 0644); err != nil {
+		return "", err
+	}
+	if !tools.CommandExists("appimagetool") {
+		term.Logger.Warn("appimagetool command not found, generated AppDir only. Install: https://github.com/AppImage/appimagetool")
+		return appDir, nil
+	}
+	outFile := filepath.Join(linuxDir, fmt.Sprintf("%s-%s.AppImage", proj.Name, rpmArch(runtime.GOARCH)))
+	term.Logger.Info("Generate AppImage. Almost complete", term.Logger.Args("AppImage", filepath.Base(outFile)))
+	cmd := exec.Command("appimagetool", appDir, outFile)
+	cmd.Env = append(os.Environ(), "ARCH="+rpmArch(runtime.GOARCH),
+		"SOURCE_DATE_EPOCH="+strconv.FormatInt(build.NewBuildInfo(proj).Time.Unix(), 10))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("appimagetool: %v\n%s", err, strings.TrimSpace(string(out)))
+	}
+	return outFile, nil
+}
+
+// 复制文件或目录, 保留权限和符号链接, skip 返回 true 时跳过
+func copyTree(src, dst string, skip func(rel string) bool) error {
+	return filepath.WalkDir(src, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, filePath)
+		if err != nil {
+			return err
+		}
+		if rel != "." && skip != nil && skip(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := os.Lstat(filePath)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		in, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		_, err = io.Copy(out, in)
+		return err
+	})
+}
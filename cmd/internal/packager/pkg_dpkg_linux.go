@@ -17,13 +17,13 @@ import (
 	"errors"
 	"fmt"
 	"github.com/energye/energy/v2/cmd/internal/assets"
+	"github.com/energye/energy/v2/cmd/internal/build"
 	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/icons"
 	"github.com/energye/energy/v2/cmd/internal/project"
 	"github.com/energye/energy/v2/cmd/internal/term"
 	"github.com/energye/energy/v2/cmd/internal/tools"
 	"github.com/energye/golcl/tools/command"
-	"io"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -31,13 +31,13 @@ import (
 )
 
 const (
-	deb               = "DEBIAN"
 	debControl        = deb + "/control"
 	debPreinit        = deb + "/preinit"
 	debPostinit       = deb + "/postinit"
 	debPrerm          = deb + "/prerm"
 	debPostrm         = deb + "/postrm"
 	usrSharApps       = "usr/share/applications"
+	usrShareHicolor   = "usr/share/icons/hicolor"
 	optCompanyProduct = "opt/%s/%s"
 )
 
@@ -47,9 +47,51 @@ const (
 	linuxARMStartup = "linux/startup.sh"
 )
 
-func GeneraInstaller(proj *project.Project) error {
-	if !tools.CommandExists("dpkg") {
-		return errors.New("failed to create application installation program. Could not find the dpkg command")
+// linux 安装包格式
+const (
+	formatDeb      = "deb"
+	formatRPM      = "rpm"
+	formatAppImage = "appimage"
+	formatTarGz    = "tar.gz"
+)
+
+// 解析安装包格式, 逗号分隔, 默认 deb
+func linuxFormats(value string) ([]string, error) {
+	var (
+		formats []string
+		exists  = make(map[string]bool)
+	)
+	for _, item := range strings.Split(value, ",") {
+		format := strings.ToLower(strings.TrimSpace(item))
+		switch format {
+		case "":
+			continue
+		case "tgz":
+			format = formatTarGz
+		case formatDeb, formatRPM, formatAppImage, formatTarGz:
+		default:
+			return nil, fmt.Errorf("unsupported package format %s, supported: deb, rpm, appimage, tar.gz", item)
+		}
+		if !exists[format] {
+			exists[format] = true
+			formats = append(formats, format)
+		}
+	}
+	if len(formats) == 0 {
+		formats = append(formats, formatDeb)
+	}
+	return formats, nil
+}
+
+func GeneraInstaller(proj *project.Project) ([]*Artifact, error) {
+	formats, err := linuxFormats(proj.Dpkg.Format)
+	if err != nil {
+		return nil, err
+	}
+	for _, format := range formats {
+		if format == formatDeb && proj.Dpkg.UseDpkg && !tools.CommandExists("dpkg") {
+			return nil, errors.New("failed to create application installation program. Could not find the dpkg command")
+		}
 	}
 	// 创建构建输出目录
 	appRoot := fmt.Sprintf("linux/%s-%s", proj.Name, proj.Info.ProductVersion)
@@ -57,29 +99,32 @@ func GeneraInstaller(proj *project.Project) error {
 	buildOutDir = filepath.Join(buildOutDir, appRoot)
 	if !tools.IsExist(buildOutDir) {
 		if err := os.MkdirAll(buildOutDir, 0755); err != nil {
-			return fmt.Errorf("unable to create directory: %w", err)
+			return nil, fmt.Errorf("unable to create directory: %w", err)
 		}
 	}
-	var err error
 	// create debian/control
 	if err = linuxControl(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	// create debian/copyright
 	if err = linuxCopyright(proj, appRoot); err != nil {
-		return err
+		return nil, err
+	}
+	// create hicolor icons
+	if err = linuxIcons(proj, appRoot); err != nil {
+		return nil, err
 	}
 	// create app.desktop
 	if err = linuxDesktop(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	// copy source
 	if err = linuxOptCopy(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	// copy linux arm startup.sh
 	if err = linuxARMStartupSH(proj, appRoot); err != nil {
-		return err
+		return nil, err
 	}
 	// 7zz 压缩 CEF
 	comper := proj.NSIS.Compress
@@ -88,16 +133,70 @@ func GeneraInstaller(proj *project.Project) error {
 		proj.NSIS.UseCompress = tools.CommandExists(comper)
 	}
 
-	// dpkg -b
-	var debName string
-	if debName, err = dpkgB(proj); err != nil {
-		return err
+	// 所有格式使用相同的 opt 目录生成
+	var artifacts []*Artifact
+	for _, format := range formats {
+		switch format {
+		case formatDeb:
+			var debName string
+			if proj.Dpkg.UseDpkg {
+				// dpkg -b
+				debName, err = dpkgB(proj)
+			} else {
+				debName, err = linuxDeb(proj)
+			}
+			if err != nil {
+				return nil, err
+			}
+			// out log
+			outInstall := filepath.Join(assets.BuildOutPath(proj), "linux", debName)
+			successLog := "Success \n\tInstall Package: %s\n\tInstall: sudo dpkg -i %s\n\tRemove:  sudo dpkg -r %s"
+			term.Section.Println(fmt.Sprintf(successLog, outInstall, debName, proj.Dpkg.Package))
+			artifacts = append(artifacts, newArtifact(format, outInstall))
+		case formatRPM:
+			var rpmFile, rpmName string
+			if rpmFile, rpmName, err = linuxRPM(proj, appRoot); err != nil {
+				return nil, err
+			}
+			successLog := "Success \n\tInstall Package: %s\n\tInstall: sudo rpm -i %s\n\tRemove:  sudo rpm -e %s"
+			term.Section.Println(fmt.Sprintf(successLog, rpmFile, filepath.Base(rpmFile), rpmName))
+			artifacts = append(artifacts, newArtifact(format, rpmFile))
+		case formatAppImage:
+			var out string
+			if out, err = linuxAppImage(proj, appRoot); err != nil {
+				return nil, err
+			}
+			term.Section.Println(fmt.Sprintf("Success \n\tAppImage: %s", out))
+			artifacts = append(artifacts, newArtifact(format, out))
+		case formatTarGz:
+			var out string
+			if out, err = linuxTarGz(proj, appRoot); err != nil {
+				return nil, err
+			}
+			term.Section.Println(fmt.Sprintf("Success \n\tArchive: %s\n\tRun: %s.sh", out, proj.Name))
+			artifacts = append(artifacts, newArtifact(format, out))
+		}
 	}
-	// out log
-	outInstall := filepath.Join(assets.BuildOutPath(proj), "linux", debName)
-	successLog := "Success \n\tInstall Package: %s\n\tInstall: sudo dpkg -i %s\n\tRemove:  sudo dpkg -r %s"
-	term.Section.Println(fmt.Sprintf(successLog, outInstall, debName, proj.Dpkg.Package))
-	return nil
+	return artifacts, nil
+}
+
+// 生成 deb 安装包, 不使用 dpkg, 文件名和 dpkg -b 相同
+func linuxDeb(proj *project.Project) (string, error) {
+	dir := filepath.Join(assets.BuildOutPath(proj), "linux")
+	app := fmt.Sprintf("%s-%s", proj.Name, proj.Info.ProductVersion)
+	debName := fmt.Sprintf("%s-%s-%s.deb", proj.Name, runtime.GOOS, runtime.GOARCH)
+	outFile := filepath.Join(dir, debName)
+	term.Logger.Info("Generate deb package. Almost complete", term.Logger.Args("deb", debName))
+	f, err := os.Create(outFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err = writeDeb(f, filepath.Join(dir, app), build.NewBuildInfo(proj).Time); err != nil {
+		os.Remove(outFile)
+		return "", err
+	}
+	return debName, nil
 }
 
 func dpkgB(proj *project.Project) (string, error) {
@@ -127,110 +226,59 @@ func opt(proj *project.Project) string {
 	return filepath.Join("/opt", proj.Info.CompanyName, proj.Info.ProductName)
 }
 
-func linuxOptCopy(proj *project.Project, appRoot string) error {
-	term.Logger.Info("Generate dpkg copy:",
-		term.Logger.Args("company", proj.Info.CompanyName, "product", proj.Info.ProductName, "opt",
-			fmt.Sprintf("/opt/%s/%s", proj.Info.CompanyName, proj.Info.ProductName)))
-	buildOutDir := assets.BuildOutPath(proj)
-	appDir := filepath.Join(buildOutDir, appRoot)
-	// app/opt/[company]/[product]
-	optDir := filepath.Join(appDir, fmt.Sprintf(optCompanyProduct, proj.Info.CompanyName, proj.Info.ProductName))
-	if err := os.MkdirAll(optDir, 0755); err != nil {
-		return fmt.Errorf("unable to create directory: %w", err)
-	}
+// 安装包文件, 目标路径 /opt/[company]/[product]/
+//  执行文件, 图标, 框架 (排除 Dpkg.Exclude), Dpkg.Include
+func packageFiles(proj *project.Project) ([]*project.PackageFile, error) {
+	optDir := filepath.ToSlash(opt(proj))
 	exeDir := filepath.Join(proj.ProjectPath, proj.OutputFilename)
 	if !tools.IsExist(exeDir) {
-		return fmt.Errorf("execution file not found: %s", exeDir)
+		return nil, fmt.Errorf("execution file not found: %s", exeDir)
 	}
 	exeIconDir := proj.Info.Icon
 	if !tools.IsExist(exeIconDir) {
-		return fmt.Errorf("execution file not found: %s", exeDir)
+		return nil, fmt.Errorf("icon file not found: %s", exeIconDir)
 	}
-
-	term.Logger.Info("Generate dpkg execution " + exeDir)
-	cefDir := os.Getenv(consts.EnergyHomeKey)
+	cefDir := proj.FrameworkPath
 	if !tools.IsExist(cefDir) {
-		return fmt.Errorf("%s not found: %s", consts.EnergyHomeKey, cefDir)
+		return nil, fmt.Errorf("energy framework not found: %s", cefDir)
 	}
-	term.Logger.Info("Generate dpkg framework " + cefDir)
-	var copyFiles = func(src, dst string) error {
-		if srcFile, err := os.Open(src); err != nil {
-			return err
-		} else {
-			st, err := srcFile.Stat()
-			if err != nil {
-				return err
-			}
-			if st.IsDir() {
-				srcFile.Close() //close
-				var pathLen = len(src)
-				err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
-					if path == src { // current root
-						return nil
-					}
-					outPath := path[pathLen:]
-					// exclude file or dir
-					for _, p := range proj.Dpkg.Exclude {
-						if strings.Contains(outPath, p) {
-							return nil
-						}
-					}
-					targetPath := filepath.Join(dst, outPath)
-					info, _ := d.Info()
-					if d.IsDir() {
-						return os.MkdirAll(targetPath, info.Mode())
-					} else {
-						if tools.IsExistAndSize(targetPath, info.Size()) {
-							//term.Logger.Info("\tcopy skip: " + outPath)
-							return nil
-						}
-						srcFile, err := os.Open(path)
-						if err != nil {
-							return err
-						}
-						defer srcFile.Close()
-						dstFile, err := os.OpenFile(targetPath, os.O_CREATE|os.O_WRONLY, info.Mode())
-						if err != nil {
-							return err
-						}
-						defer dstFile.Close()
-						//term.Logger.Info("\tcopy: " + outPath)
-						_, err = io.Copy(dstFile, srcFile)
-						return err
-					}
-				})
-				if err != nil {
-					return err
-				}
-			} else {
-				defer srcFile.Close()
-				dstFilePath := filepath.Join(dst, st.Name())
-				dstFile, err := os.OpenFile(dstFilePath, os.O_CREATE|os.O_WRONLY, st.Mode())
-				if err != nil {
-					return err
-				}
-				defer dstFile.Close()
-				_, err = io.Copy(dstFile, srcFile)
-				if err != nil {
-					return err
-				}
-			}
+	var files []*project.PackageFile
+	for _, src := range []string{exeDir, exeIconDir} {
+		f, err := project.Files(src, optDir, nil)
+		if err != nil {
+			return nil, err
 		}
-		return nil
+		files = append(files, f...)
 	}
-	term.Logger.Info("Generate dpkg copy:", term.Logger.Args("execution", exeDir))
-	if err := copyFiles(exeDir, optDir); err != nil {
-		return err
+	framework, err := project.Files(cefDir, optDir, proj.Dpkg.Exclude)
+	if err != nil {
+		return nil, err
 	}
-	term.Logger.Info("Generate dpkg copy:", term.Logger.Args("icon", exeIconDir))
-	if err := copyFiles(exeIconDir, optDir); err != nil {
-		return err
+	include, err := project.IncludeFiles(proj.ProjectPath, optDir, proj.Dpkg.Include, proj.Dpkg.Exclude)
+	if err != nil {
+		return nil, err
 	}
-	term.Logger.Info("Generate dpkg copy:", term.Logger.Args("framework", cefDir))
-	if err := copyFiles(cefDir, optDir); err != nil {
+	files = append(files, framework...)
+	return append(files, include...), nil
+}
+
+func linuxOptCopy(proj *project.Project, appRoot string) error {
+	term.Logger.Info("Generate dpkg copy:",
+		term.Logger.Args("company", proj.Info.CompanyName, "product", proj.Info.ProductName, "opt",
+			fmt.Sprintf("/opt/%s/%s", proj.Info.CompanyName, proj.Info.ProductName)))
+	buildOutDir := assets.BuildOutPath(proj)
+	appDir := filepath.Join(buildOutDir, appRoot)
+	// app/opt/[company]/[product]
+	optDir := filepath.Join(appDir, fmt.Sprintf(optCompanyProduct, proj.Info.CompanyName, proj.Info.ProductName))
+	if err := os.MkdirAll(optDir, 0755); err != nil {
+		return fmt.Errorf("unable to create directory: %w", err)
+	}
+	files, err := packageFiles(proj)
+	if err != nil {
 		return err
 	}
-	return nil
+	term.Logger.Info("Generate dpkg copy:", term.Logger.Args("execution", proj.OutputFilename, "framework", proj.FrameworkPath, "files", len(files)))
+	return copyPackageFiles(files, appDir)
 }
 
 func linuxARMStartupSH(proj *project.Project, appRoot string) error {
@@ -276,7 +324,6 @@ func linuxDesktop(proj *project.Project, appRoot string) error {
 		return err
 	} else {
 		optDir := opt(proj)
-		_, icon := filepath.Split(proj.Info.Icon)
 		startup := proj.Name
 		if consts.IsLinux && consts.IsARM64 {
 			startup += ".sh"
@@ -284,7 +331,7 @@ func linuxDesktop(proj *project.Project, appRoot string) error {
 		data := make(map[string]any)
 		data["Name"] = proj.Name
 		data["Exec"] = filepath.Join(optDir, startup)
-		data["Icon"] = filepath.Join(optDir, icon)
+		data["Icon"] = desktopIcon(proj, appRoot)
 		data["Comments"] = proj.Info.Comments
 		if content, err := tools.RenderTemplate(string(desktopData), data); err != nil {
 			return err
@@ -298,6 +345,34 @@ func linuxDesktop(proj *project.Project, appRoot string) error {
 	return nil
 }
 
+// 生成 hicolor 图标 usr/share/icons/hicolor/[size]x[size]/apps/[name].png
+//  图标无法读取时只使用 opt 目录中的图标
+func linuxIcons(proj *project.Project, appRoot string) error {
+	term.Logger.Info("Generate dpkg hicolor icons")
+	img, err := icons.Load(proj.Info.Icon)
+	if err != nil {
+		term.Logger.Warn("Generate hicolor icons: " + err.Error())
+		return nil
+	}
+	hicolor := filepath.Join(assets.BuildOutPath(proj), appRoot, usrShareHicolor)
+	_, err = icons.Hicolor(img, hicolor, proj.Name)
+	return err
+}
+
+// desktop 图标, 有 hicolor 图标时使用图标名, 否则使用 opt 目录中的图标
+func desktopIcon(proj *project.Project, appRoot string) string {
+	if tools.IsExist(hicolorIcon(proj, appRoot, 256)) {
+		return proj.Name
+	}
+	_, icon := filepath.Split(proj.Info.Icon)
+	return filepath.Join(opt(proj), icon)
+}
+
+// hicolor 图标文件
+func hicolorIcon(proj *project.Project, appRoot string, size int) string {
+	return filepath.Join(assets.BuildOutPath(proj), appRoot, usrShareHicolor, fmt.Sprintf("%dx%d", size, size), "apps", proj.Name+".png")
+}
+
 func linuxCopyright(proj *project.Project, appRoot string) error {
 	term.Logger.Info("Generate dpkg copyright")
 	return nil
@@ -318,6 +393,7 @@ func linuxControl(proj *project.Project, appRoot string) error {
 		data := make(map[string]any)
 		data["Arch"] = runtime.GOARCH
 		data["Info"] = proj.Info
+		data["BuildInfo"] = build.NewBuildInfo(proj) // 和编译注入的版本相同
 		data["Author"] = proj.Author
 		data["Dpkg"] = proj.Dpkg
 		if content, err := tools.RenderTemplate(string(controlData), data); err != nil {
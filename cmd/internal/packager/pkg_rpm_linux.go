@@ -0,0 +1,89 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+//go:build linux
+// +build linux
+
+package packager
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/assets"
+	"github.com/energye/energy/v2/cmd/internal/build"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// 生成 rpm 安装包, 使用 dpkg 相同的 opt 和 usr/share/applications 目录
+//  返回安装包文件和包名
+func linuxRPM(proj *project.Project, appRoot string) (string, string, error) {
+	appDir := filepath.Join(assets.BuildOutPath(proj), appRoot)
+	info := build.NewBuildInfo(proj)
+	name := proj.Dpkg.Package
+	if name == "" {
+		name = proj.Name
+	}
+	var comments string
+	if proj.Info.Comments != nil {
+		comments = *proj.Info.Comments
+	}
+	summary := comments
+	if summary == "" {
+		summary = proj.Info.ProductName
+	}
+	pkg := &rpmPackage{
+		Name:        name,
+		Version:     rpmVersion(info.Version),
+		Arch:        rpmArch(runtime.GOARCH),
+		Summary:     summary,
+		Description: summary,
+		License:     "Proprietary",
+		Group:       "Applications/Internet",
+		URL:         proj.Dpkg.Homepage,
+		Vendor:      proj.Info.CompanyName,
+		Packager:    fmt.Sprintf("%s <%s>", proj.Author.Name, proj.Author.Email),
+		BuildTime:   info.Time,
+	}
+	// 只记录 /opt/[company] 下的目录, /opt 和 /usr/share/applications 属于系统
+	companyDir := path.Dir(filepath.ToSlash(opt(proj)))
+	ownDir := func(name string) bool {
+		return name == companyDir || strings.HasPrefix(name, companyDir+"/")
+	}
+	if err := pkg.AddDir(filepath.Join(appDir, "opt"), "/opt", ownDir); err != nil {
+		return "", "", err
+	}
+	if err := pkg.AddDir(filepath.Join(appDir, usrSharApps), "/"+usrSharApps, nil); err != nil {
+		return "", "", err
+	}
+	if tools.IsExist(filepath.Join(appDir, usrShareHicolor)) {
+		if err := pkg.AddDir(filepath.Join(appDir, usrShareHicolor), "/"+usrShareHicolor, nil); err != nil {
+			return "", "", err
+		}
+	}
+	rpmName := fmt.Sprintf("%s-%s-%s.rpm", proj.Name, runtime.GOOS, runtime.GOARCH)
+	outFile := filepath.Join(assets.BuildOutPath(proj), "linux", rpmName)
+	term.Logger.Info("Generate rpm package. Almost complete", term.Logger.Args("rpm", rpmName))
+	f, err := os.Create(outFile)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	if err = pkg.Write(f); err != nil {
+		os.Remove(outFile)
+		return "", "", err
+	}
+	return outFile, name, nil
+}
@@ -0,0 +1,84 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+//go:build linux
+// +build linux
+
+package packager
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/assets"
+	"github.com/energye/energy/v2/cmd/internal/build"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const linuxLauncher = "linux/launcher.sh"
+
+// 可移动启动脚本, libDir 为应用和框架相对脚本的目录
+func linuxLauncherSH(proj *project.Project, libDir string) ([]byte, error) {
+	launcherData, err := assets.ReadFile(proj, assetsFSPath, linuxLauncher)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]any)
+	data["LIBDIR"] = libDir
+	data["EXECUTE"] = proj.Name
+	data["PRELOAD"] = consts.IsLinux && consts.IsARM64
+	sh := strings.NewReplacer("\r", "")
+	return tools.RenderTemplate(sh.Replace(string(launcherData)), data)
+}
+
+// opt 目录中的文件, 跳过 arm 安装路径启动脚本
+func skipOptStartup(proj *project.Project) func(rel string) bool {
+	return func(rel string) bool {
+		return rel == proj.Name+".sh"
+	}
+}
+
+// 生成 tar.gz 压缩包, 解压到任意目录运行 [name].sh
+//  [name]-[version]-linux-[arch]/
+func linuxTarGz(proj *project.Project, appRoot string) (string, error) {
+	optDir := filepath.Join(assets.BuildOutPath(proj), appRoot, opt(proj))
+	info := build.NewBuildInfo(proj)
+	root := fmt.Sprintf("%s-%s-linux-%s", proj.Name, info.Version, runtime.GOARCH)
+	outFile := filepath.Join(assets.BuildOutPath(proj), "linux", root+".tar.gz")
+	term.Logger.Info("Generate tar.gz archive", term.Logger.Args("archive", filepath.Base(outFile)))
+	launcher, err := linuxLauncherSH(proj, ".")
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Create(outFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	tgz, err := newTarGz(f, info.Time)
+	if err != nil {
+		return "", err
+	}
+	if err = tgz.AddDir(optDir, root, skipOptStartup(proj)); err != nil {
+		return "", err
+	}
+	if err = tgz.AddFile(root+"/"+proj.Name+".sh", 0755, launcher); err != nil {
+		return "", err
+	}
+	if err = tgz.Close(); err != nil {
+		return "", err
+	}
+	return outFile, nil
+}
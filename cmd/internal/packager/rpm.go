@@ -0,0 +1,574 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package packager
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RPM 安装包, 不依赖 rpmbuild
+//  文件格式: lead(96) + 签名头(8字节对齐) + 头 + gzip cpio(newc)
+//  https://rpm-software-management.github.io/rpm/manual/format.html
+
+// 头数据类型
+const (
+	rpmInt16       = 3
+	rpmInt32       = 4
+	rpmString      = 6
+	rpmBin         = 7
+	rpmStringArray = 8
+	rpmI18NString  = 9
+)
+
+// 签名头 tag
+const (
+	rpmSigHeaderSignatures = 62
+	rpmSigSHA1             = 269
+	rpmSigSHA256           = 273
+	rpmSigSize             = 1000
+	rpmSigMD5              = 1004
+	rpmSigPayloadSize      = 1007
+)
+
+// 头 tag
+const (
+	rpmTagHeaderImmutable   = 63
+	rpmTagHeaderI18NTable   = 100
+	rpmTagName              = 1000
+	rpmTagVersion           = 1001
+	rpmTagRelease           = 1002
+	rpmTagSummary           = 1004
+	rpmTagDescription       = 1005
+	rpmTagBuildTime         = 1006
+	rpmTagBuildHost         = 1007
+	rpmTagSize              = 1009
+	rpmTagVendor            = 1011
+	rpmTagLicense           = 1014
+	rpmTagPackager          = 1015
+	rpmTagGroup             = 1016
+	rpmTagURL               = 1020
+	rpmTagOS                = 1021
+	rpmTagArch              = 1022
+	rpmTagFileSizes         = 1028
+	rpmTagFileModes         = 1030
+	rpmTagFileRdevs         = 1033
+	rpmTagFileMtimes        = 1034
+	rpmTagFileDigests       = 1035
+	rpmTagFileLinkTos       = 1036
+	rpmTagFileFlags         = 1037
+	rpmTagFileUserName      = 1039
+	rpmTagFileGroupName     = 1040
+	rpmTagSourceRPM         = 1044
+	rpmTagProvideName       = 1047
+	rpmTagRequireFlags      = 1048
+	rpmTagRequireName       = 1049
+	rpmTagRequireVersion    = 1050
+	rpmTagFileDevices       = 1095
+	rpmTagFileInodes        = 1096
+	rpmTagFileLangs         = 1097
+	rpmTagProvideFlags      = 1112
+	rpmTagProvideVersion    = 1113
+	rpmTagDirIndexes        = 1116
+	rpmTagBaseNames         = 1117
+	rpmTagDirNames          = 1118
+	rpmTagPayloadFormat     = 1124
+	rpmTagPayloadCompressor = 1125
+	rpmTagPayloadFlags      = 1126
+	rpmTagFileDigestAlgo    = 5011
+	rpmTagPayloadDigest     = 5092
+	rpmTagPayloadDigestAlgo = 5093
+)
+
+const (
+	rpmSenseLess   = 0x02
+	rpmSenseEqual  = 0x08
+	rpmSenseRPMLib = 0x1000000
+	rpmDigestSHA   = 8 // sha256
+)
+
+// rpmFile 安装包内文件
+type rpmFile struct {
+	Name   string      // 安装路径, /opt/company/product/app
+	Mode   fs.FileMode // 权限和类型
+	Size   int64
+	Src    string // 本地文件
+	LinkTo string // 符号链接目标
+	digest string
+}
+
+// rpmPackage RPM 安装包信息
+type rpmPackage struct {
+	Name        string
+	Version     string // 不能包含 -
+	Release     string
+	Arch        string // x86_64, aarch64, i386
+	Summary     string
+	Description string
+	License     string
+	Group       string
+	URL         string
+	Vendor      string
+	Packager    string
+	BuildTime   time.Time // 文件修改时间和构建时间, 可重现构建
+	Files       []*rpmFile
+}
+
+// rpmArch GOARCH 转 RPM 架构名
+func rpmArch(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "386":
+		return "i386"
+	}
+	return goarch
+}
+
+// rpmVersion RPM 版本号不能包含 -, 预发布版本使用 ~ 排序在正式版本之前
+func rpmVersion(version string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(version, "v"), "-", "~")
+}
+
+// AddDir 添加目录中的文件, 安装到 installPath
+//  ownDir 返回 true 的目录记录在安装包中, 卸载时删除; 系统目录 (/opt, /usr/share/applications) 不应记录
+func (m *rpmPackage) AddDir(dir, installPath string, ownDir func(name string) bool) error {
+	return filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		name := path.Join(installPath, filepath.ToSlash(rel))
+		info, err := os.Lstat(filePath)
+		if err != nil {
+			return err
+		}
+		file := &rpmFile{Name: name, Mode: info.Mode()}
+		switch {
+		case info.IsDir():
+			if rel == "." || ownDir == nil || !ownDir(name) {
+				return nil
+			}
+		case info.Mode()&fs.ModeSymlink != 0:
+			if file.LinkTo, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+			file.Size = int64(len(file.LinkTo))
+		case info.Mode().IsRegular():
+			file.Src, file.Size = filePath, info.Size()
+		default:
+			return nil
+		}
+		m.Files = append(m.Files, file)
+		return nil
+	})
+}
+
+// Write 写入 RPM 安装包
+func (m *rpmPackage) Write(w io.Writer) error {
+	if m.Release == "" {
+		m.Release = "1"
+	}
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Name < m.Files[j].Name
+	})
+	// payload 写入临时文件, 框架文件较大
+	payload, err := os.CreateTemp("", "energy-rpm-payload-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		payload.Close()
+		os.Remove(payload.Name())
+	}()
+	payloadSize, err := m.writePayload(payload)
+	if err != nil {
+		return err
+	}
+	payloadDigest := sha256.New()
+	compressedSize, err := payload.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = payload.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err = io.Copy(payloadDigest, payload); err != nil {
+		return err
+	}
+	header := m.header(hex.EncodeToString(payloadDigest.Sum(nil))).bytes(rpmTagHeaderImmutable)
+
+	// 签名头, 头和 payload 摘要
+	md5Sum := md5.New()
+	md5Sum.Write(header)
+	if _, err = payload.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err = io.Copy(md5Sum, payload); err != nil {
+		return err
+	}
+	sha1Sum := sha1.Sum(header)
+	sha256Sum := sha256.Sum256(header)
+	sig := &rpmHeader{}
+	sig.addString(rpmSigSHA1, hex.EncodeToString(sha1Sum[:]))
+	sig.addString(rpmSigSHA256, hex.EncodeToString(sha256Sum[:]))
+	sig.addInt32(rpmSigSize, uint32(int64(len(header))+compressedSize))
+	sig.add(rpmSigMD5, rpmBin, 16, md5Sum.Sum(nil))
+	sig.addInt32(rpmSigPayloadSize, uint32(payloadSize))
+	sigData := sig.bytes(rpmSigHeaderSignatures)
+	if pad := len(sigData) % 8; pad != 0 {
+		sigData = append(sigData, make([]byte, 8-pad)...)
+	}
+
+	if _, err = w.Write(m.lead()); err != nil {
+		return err
+	}
+	if _, err = w.Write(sigData); err != nil {
+		return err
+	}
+	if _, err = w.Write(header); err != nil {
+		return err
+	}
+	if _, err = payload.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, payload)
+	return err
+}
+
+// lead 96 字节, 只用于识别文件类型
+func (m *rpmPackage) lead() []byte {
+	lead := make([]byte, 96)
+	copy(lead, []byte{0xed, 0xab, 0xee, 0xdb, 3, 0})
+	// type 0 binary, archnum
+	archNum := uint16(1)
+	if m.Arch == "aarch64" {
+		archNum = 19
+	}
+	binary.BigEndian.PutUint16(lead[6:], 0)
+	binary.BigEndian.PutUint16(lead[8:], archNum)
+	name := fmt.Sprintf("%s-%s-%s", m.Name, m.Version, m.Release)
+	if len(name) > 65 {
+		name = name[:65]
+	}
+	copy(lead[10:], name)
+	binary.BigEndian.PutUint16(lead[76:], 1) // osnum linux
+	binary.BigEndian.PutUint16(lead[78:], 5) // signature type, header
+	return lead
+}
+
+// gzip cpio newc, 返回未压缩大小
+func (m *rpmPackage) writePayload(w io.Writer) (int64, error) {
+	gw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return 0, err
+	}
+	counter := &countWriter{w: gw}
+	mtime := m.BuildTime.Unix()
+	for i, file := range m.Files {
+		var data io.Reader
+		switch {
+		case file.Mode.IsRegular():
+			f, err := os.Open(file.Src)
+			if err != nil {
+				return 0, err
+			}
+			h := sha256.New()
+			data = io.TeeReader(f, h)
+			err = writeCpioEntry(counter, "."+file.Name, uint32(i+1), rpmFileMode(file.Mode), mtime, file.Size, data)
+			f.Close()
+			if err != nil {
+				return 0, err
+			}
+			file.digest = hex.EncodeToString(h.Sum(nil))
+			continue
+		case file.Mode&fs.ModeSymlink != 0:
+			data = strings.NewReader(file.LinkTo)
+		}
+		if err := writeCpioEntry(counter, "."+file.Name, uint32(i+1), rpmFileMode(file.Mode), mtime, file.Size, data); err != nil {
+			return 0, err
+		}
+	}
+	if err := writeCpioEntry(counter, "TRAILER!!!", 0, 0, 0, 0, nil); err != nil {
+		return 0, err
+	}
+	if err := gw.Close(); err != nil {
+		return 0, err
+	}
+	return counter.n, nil
+}
+
+// unix 文件模式, 类型 + 权限
+func rpmFileMode(mode fs.FileMode) uint32 {
+	perm := uint32(mode.Perm())
+	switch {
+	case mode.IsDir():
+		return 0040000 | perm
+	case mode&fs.ModeSymlink != 0:
+		return 0120000 | 0777
+	}
+	return 0100000 | perm
+}
+
+// cpio newc 文件头和数据, 4字节对齐
+func writeCpioEntry(w io.Writer, name string, ino, mode uint32, mtime, size int64, data io.Reader) error {
+	nlink := 1
+	if mode&0170000 == 0040000 {
+		nlink = 2
+	}
+	header := fmt.Sprintf("070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+		ino, mode, 0, 0, nlink, mtime, size, 0, 0, 0, 0, len(name)+1, 0)
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString(name)
+	buf.WriteByte(0)
+	if pad := buf.Len() % 4; pad != 0 {
+		buf.Write(make([]byte, 4-pad))
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if data != nil {
+		n, err := io.Copy(w, data)
+		if err != nil {
+			return err
+		}
+		if n != size {
+			return fmt.Errorf("%s: size changed during packaging", name)
+		}
+	}
+	if pad := size % 4; pad != 0 {
+		if _, err := w.Write(make([]byte, 4-pad)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (m *countWriter) Write(p []byte) (int, error) {
+	n, err := m.w.Write(p)
+	m.n += int64(n)
+	return n, err
+}
+
+// 安装包头
+func (m *rpmPackage) header(payloadDigest string) *rpmHeader {
+	h := &rpmHeader{}
+	h.add(rpmTagHeaderI18NTable, rpmStringArray, 1, []byte("C\x00"))
+	h.addString(rpmTagName, m.Name)
+	h.addString(rpmTagVersion, m.Version)
+	h.addString(rpmTagRelease, m.Release)
+	h.add(rpmTagSummary, rpmI18NString, 1, append([]byte(m.Summary), 0))
+	h.add(rpmTagDescription, rpmI18NString, 1, append([]byte(m.Description), 0))
+	h.addInt32(rpmTagBuildTime, uint32(m.BuildTime.Unix()))
+	h.addString(rpmTagBuildHost, "localhost")
+	h.addString(rpmTagLicense, m.License)
+	h.add(rpmTagGroup, rpmI18NString, 1, append([]byte(m.Group), 0))
+	if m.Vendor != "" {
+		h.addString(rpmTagVendor, m.Vendor)
+	}
+	if m.Packager != "" {
+		h.addString(rpmTagPackager, m.Packager)
+	}
+	if m.URL != "" {
+		h.addString(rpmTagURL, m.URL)
+	}
+	h.addString(rpmTagOS, "linux")
+	h.addString(rpmTagArch, m.Arch)
+	// 二进制包必须有 SOURCERPM, 否则识别为源码包
+	h.addString(rpmTagSourceRPM, fmt.Sprintf("%s-%s-%s.src.rpm", m.Name, m.Version, m.Release))
+
+	var (
+		totalSize                                     uint32
+		sizes, mtimes, flags, inodes, devices, dirIdx []uint32
+		modes, rdevs                                  []uint16
+		digests, linkTos, users, groups, langs, bases []string
+		dirNames                                      []string
+		dirIndex                                      = make(map[string]uint32)
+	)
+	for i, file := range m.Files {
+		if file.Mode.IsRegular() {
+			totalSize += uint32(file.Size)
+		}
+		sizes = append(sizes, uint32(file.Size))
+		modes = append(modes, uint16(rpmFileMode(file.Mode)))
+		rdevs = append(rdevs, 0)
+		mtimes = append(mtimes, uint32(m.BuildTime.Unix()))
+		digests = append(digests, file.digest)
+		linkTos = append(linkTos, file.LinkTo)
+		flags = append(flags, 0)
+		users = append(users, "root")
+		groups = append(groups, "root")
+		devices = append(devices, 1)
+		inodes = append(inodes, uint32(i+1))
+		langs = append(langs, "")
+		dir, base := path.Split(file.Name)
+		idx, ok := dirIndex[dir]
+		if !ok {
+			idx = uint32(len(dirNames))
+			dirIndex[dir] = idx
+			dirNames = append(dirNames, dir)
+		}
+		dirIdx = append(dirIdx, idx)
+		bases = append(bases, base)
+	}
+	h.addInt32(rpmTagSize, totalSize)
+	if len(m.Files) > 0 {
+		h.addInt32(rpmTagFileSizes, sizes...)
+		h.addInt16(rpmTagFileModes, modes...)
+		h.addInt16(rpmTagFileRdevs, rdevs...)
+		h.addInt32(rpmTagFileMtimes, mtimes...)
+		h.addStrings(rpmTagFileDigests, digests...)
+		h.addStrings(rpmTagFileLinkTos, linkTos...)
+		h.addInt32(rpmTagFileFlags, flags...)
+		h.addStrings(rpmTagFileUserName, users...)
+		h.addStrings(rpmTagFileGroupName, groups...)
+	}
+	h.addStrings(rpmTagProvideName, m.Name)
+	h.addInt32(rpmTagRequireFlags, rpmSenseLess|rpmSenseEqual|rpmSenseRPMLib, rpmSenseLess|rpmSenseEqual|rpmSenseRPMLib, rpmSenseLess|rpmSenseEqual|rpmSenseRPMLib)
+	h.addStrings(rpmTagRequireName, "rpmlib(CompressedFileNames)", "rpmlib(FileDigests)", "rpmlib(PayloadFilesHavePrefix)")
+	h.addStrings(rpmTagRequireVersion, "3.0.4-1", "4.6.0-1", "4.0-1")
+	if len(m.Files) > 0 {
+		h.addInt32(rpmTagFileDevices, devices...)
+		h.addInt32(rpmTagFileInodes, inodes...)
+		h.addStrings(rpmTagFileLangs, langs...)
+	}
+	h.addInt32(rpmTagProvideFlags, rpmSenseEqual)
+	h.addStrings(rpmTagProvideVersion, m.Version+"-"+m.Release)
+	if len(m.Files) > 0 {
+		h.addInt32(rpmTagDirIndexes, dirIdx...)
+		h.addStrings(rpmTagBaseNames, bases...)
+		h.addStrings(rpmTagDirNames, dirNames...)
+	}
+	h.addString(rpmTagPayloadFormat, "cpio")
+	h.addString(rpmTagPayloadCompressor, "gzip")
+	h.addString(rpmTagPayloadFlags, "9")
+	h.addInt32(rpmTagFileDigestAlgo, rpmDigestSHA)
+	h.addStrings(rpmTagPayloadDigest, payloadDigest)
+	h.addInt32(rpmTagPayloadDigestAlgo, rpmDigestSHA)
+	return h
+}
+
+// RPM 头, 索引 + 数据
+type rpmHeader struct {
+	entries []*rpmEntry
+}
+
+type rpmEntry struct {
+	tag, typ, count uint32
+	data            []byte
+}
+
+func (m *rpmHeader) add(tag, typ, count uint32, data []byte) {
+	m.entries = append(m.entries, &rpmEntry{tag: tag, typ: typ, count: count, data: data})
+}
+
+func (m *rpmHeader) addString(tag uint32, value string) {
+	m.add(tag, rpmString, 1, append([]byte(value), 0))
+}
+
+func (m *rpmHeader) addStrings(tag uint32, values ...string) {
+	var buf bytes.Buffer
+	for _, v := range values {
+		buf.WriteString(v)
+		buf.WriteByte(0)
+	}
+	m.add(tag, rpmStringArray, uint32(len(values)), buf.Bytes())
+}
+
+func (m *rpmHeader) addInt32(tag uint32, values ...uint32) {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint32(data[i*4:], v)
+	}
+	m.add(tag, rpmInt32, uint32(len(values)), data)
+}
+
+func (m *rpmHeader) addInt16(tag uint32, values ...uint16) {
+	data := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint16(data[i*2:], v)
+	}
+	m.add(tag, rpmInt16, uint32(len(values)), data)
+}
+
+// 数据类型对齐
+func rpmAlign(typ uint32) int {
+	switch typ {
+	case rpmInt16:
+		return 2
+	case rpmInt32:
+		return 4
+	}
+	return 1
+}
+
+// 头数据
+//  第一个索引为区域标记 regionTag, 数据在末尾: 16字节索引, offset 为索引区负长度
+//  其它索引按 tag 排序, 数据顺序和索引顺序一致
+func (m *rpmHeader) bytes(regionTag uint32) []byte {
+	sort.SliceStable(m.entries, func(i, j int) bool {
+		return m.entries[i].tag < m.entries[j].tag
+	})
+	var (
+		store   bytes.Buffer
+		offsets = make([]int, len(m.entries))
+	)
+	for i, e := range m.entries {
+		if pad := store.Len() % rpmAlign(e.typ); pad != 0 {
+			store.Write(make([]byte, rpmAlign(e.typ)-pad))
+		}
+		offsets[i] = store.Len()
+		store.Write(e.data)
+	}
+	count := len(m.entries) + 1
+	regionOffset := store.Len()
+	trailer := make([]byte, 16)
+	binary.BigEndian.PutUint32(trailer, regionTag)
+	binary.BigEndian.PutUint32(trailer[4:], rpmBin)
+	binary.BigEndian.PutUint32(trailer[8:], uint32(int32(-16*count)))
+	binary.BigEndian.PutUint32(trailer[12:], 16)
+	store.Write(trailer)
+
+	var buf bytes.Buffer
+	buf.Write([]byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0})
+	binary.Write(&buf, binary.BigEndian, []uint32{uint32(count), uint32(store.Len())})
+	binary.Write(&buf, binary.BigEndian, []uint32{regionTag, rpmBin, uint32(regionOffset), 16})
+	for i, e := range m.entries {
+		binary.Write(&buf, binary.BigEndian, []uint32{e.tag, e.typ, uint32(offsets[i]), e.count})
+	}
+	buf.Write(store.Bytes())
+	return buf.Bytes()
+}
@@ -0,0 +1,135 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package packager
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 读取头, 返回 tag 数据和头长度
+func readRPMHeader(t *testing.T, data []byte) (map[uint32][]byte, int) {
+	if !bytes.Equal(data[:4], []byte{0x8e, 0xad, 0xe8, 0x01}) {
+		t.Fatalf("bad header magic %x", data[:4])
+	}
+	count := int(binary.BigEndian.Uint32(data[8:]))
+	size := int(binary.BigEndian.Uint32(data[12:]))
+	store := data[16+count*16 : 16+count*16+size]
+	tags := make(map[uint32][]byte)
+	var prev int
+	for i := 0; i < count; i++ {
+		entry := data[16+i*16:]
+		tag, offset := binary.BigEndian.Uint32(entry), int(binary.BigEndian.Uint32(entry[8:]))
+		if i > 0 {
+			if offset < prev {
+				t.Fatalf("tag %d offset %d before %d", tag, offset, prev)
+			}
+			prev = offset
+		}
+		tags[tag] = store[offset:]
+	}
+	return tags, 16 + count*16 + size
+}
+
+func cstring(data []byte) string {
+	return string(data[:bytes.IndexByte(data, 0)])
+}
+
+func TestRPMWrite(t *testing.T) {
+	dir := t.TempDir()
+	appDir := filepath.Join(dir, "opt", "company", "demo")
+	if err := os.MkdirAll(filepath.Join(appDir, "locales"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(appDir, "demo"), []byte("#!/bin/sh\necho demo\n"), 0755)
+	os.WriteFile(filepath.Join(appDir, "locales", "en-US.pak"), []byte("pak"), 0644)
+	os.Symlink("demo", filepath.Join(appDir, "demo-link"))
+
+	pkg := &rpmPackage{
+		Name:      "demo",
+		Version:   rpmVersion("1.0.0-beta"),
+		Arch:      rpmArch("amd64"),
+		Summary:   "demo app",
+		License:   "MIT",
+		BuildTime: time.Unix(1700000000, 0),
+	}
+	if err := pkg.AddDir(filepath.Join(dir, "opt"), "/opt", func(name string) bool {
+		return strings.HasPrefix(name, "/opt/company")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := pkg.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if !bytes.Equal(data[:4], []byte{0xed, 0xab, 0xee, 0xdb}) {
+		t.Fatalf("bad lead magic %x", data[:4])
+	}
+	if name := cstring(data[10:76]); name != "demo-1.0.0~beta-1" {
+		t.Errorf("lead name %s", name)
+	}
+	sig, sigSize := readRPMHeader(t, data[96:])
+	if pad := sigSize % 8; pad != 0 {
+		sigSize += 8 - pad
+	}
+	header, headerSize := readRPMHeader(t, data[96+sigSize:])
+	rest := data[96+sigSize:]
+	if size := int(binary.BigEndian.Uint32(sig[rpmSigSize])); size != len(rest) {
+		t.Errorf("signature size %d, want %d", size, len(rest))
+	}
+	if sum := md5.Sum(rest); !bytes.Equal(sig[rpmSigMD5][:16], sum[:]) {
+		t.Error("signature md5 mismatch")
+	}
+	if name := cstring(header[rpmTagName]); name != "demo" {
+		t.Errorf("name %s", name)
+	}
+	if arch := cstring(header[rpmTagArch]); arch != "x86_64" {
+		t.Errorf("arch %s", arch)
+	}
+	if _, ok := header[rpmTagSourceRPM]; !ok {
+		t.Error("missing SOURCERPM, package is treated as source rpm")
+	}
+	// opt 不属于安装包, company 目录属于
+	dirNames := strings.Split(string(header[rpmTagDirNames]), "\x00")
+	if dirNames[0] != "/opt/" {
+		t.Errorf("dir names %v", dirNames[:3])
+	}
+	bases := strings.Split(string(header[rpmTagBaseNames]), "\x00")
+	want := []string{"company", "demo", "demo", "demo-link", "locales", "en-US.pak"}
+	for i, base := range want {
+		if bases[i] != base {
+			t.Fatalf("base names %v, want %v", bases[:len(want)], want)
+		}
+	}
+	if headerSize == 0 || headerSize > len(rest) {
+		t.Fatalf("header size %d", headerSize)
+	}
+	// bsdtar 可以读取 rpm payload
+	if _, err := exec.LookPath("bsdtar"); err == nil {
+		file := filepath.Join(dir, "demo.rpm")
+		os.WriteFile(file, data, 0644)
+		out, err := exec.Command("bsdtar", "-tf", file).CombinedOutput()
+		if err != nil {
+			t.Fatalf("bsdtar: %v %s", err, out)
+		}
+		if !strings.Contains(string(out), "./opt/company/demo/locales/en-US.pak") {
+			t.Errorf("bsdtar list:\n%s", out)
+		}
+	}
+}
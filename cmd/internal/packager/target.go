@@ -0,0 +1,108 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package packager
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/build"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// 交叉打包 energy package --target windows/amd64
+//  在 linux, macos 使用 makensis 生成 windows 安装包, 项目配置使用 windows 的覆盖配置
+//  执行文件: energy build --target 的输出 build/windows-[arch]/[name].exe
+//  框架: 已安装的目标系统架构框架, energy install --os=windows --arch=[arch]
+//  nsis.icon 未配置时使用 info.icon 生成 ico, nsis.language 默认 English
+//  安装包: build/windows/[name]-windows-[arch]-installer.exe
+
+// 准备目标的项目配置, 返回执行文件
+func prepareTarget(proj *project.Project, t build.Target) (string, error) {
+	if t.OS != "windows" {
+		return "", command.WithCode(command.ExitUsage, fmt.Errorf("packaging %s is not supported on %s/%s, only windows targets can be packaged on other systems", t, runtime.GOOS, runtime.GOARCH))
+	}
+	f := build.TargetFramework(proj, t)
+	if f == nil {
+		return "", command.WithCode(command.ExitEnv, fmt.Errorf("no framework installed for %s, install: energy install --os=%s --arch=%s .", t, t.OS, t.Arch))
+	}
+	proj.FrameworkPath = f.Path
+	exe := build.TargetOutput(proj, t)
+	if !tools.IsExist(exe) {
+		return "", fmt.Errorf("execution file not found: %s, build: energy build --target %s .", exe, t)
+	}
+	if proj.NSIS.Language == "" {
+		proj.NSIS.Language = "English"
+	}
+	if proj.NSIS.Icon == "" {
+		icon, err := build.GeneraICON(proj)
+		if err != nil {
+			return "", fmt.Errorf("nsis.icon is not set, generate from info.icon %s: %w", proj.Info.Icon, err)
+		}
+		proj.NSIS.Icon = icon
+	}
+	if proj.NSIS.UnIcon == "" {
+		proj.NSIS.UnIcon = proj.NSIS.Icon
+	}
+	if proj.Info.FileVersion == "" {
+		proj.Info.FileVersion = proj.Info.ProductVersion
+	}
+	// 不同架构的框架压缩包
+	if proj.NSIS.CompressName == "" {
+		proj.NSIS.CompressName = "framework.7z"
+	}
+	proj.NSIS.CompressName = strings.TrimSuffix(proj.NSIS.CompressName, ".7z") + "-" + t.OS + "-" + t.Arch + ".7z"
+	return exe, nil
+}
+
+// TargetInstaller 生成目标系统架构的安装包, 当前系统架构时同 GeneraInstaller
+func TargetInstaller(proj *project.Project, t build.Target) ([]*Artifact, error) {
+	if t.IsHost() {
+		return GeneraInstaller(proj)
+	}
+	exe, err := prepareTarget(proj, t)
+	if err != nil {
+		return nil, err
+	}
+	outInstall, err := nsisInstaller(proj, exe)
+	if err != nil {
+		return nil, err
+	}
+	// 不同架构的安装包
+	target := filepath.Join(filepath.Dir(outInstall), fmt.Sprintf("%s-%s-%s-installer.exe", proj.Name, t.OS, t.Arch))
+	if err = os.Rename(outInstall, target); err != nil {
+		return nil, err
+	}
+	term.Section.Println("Success \n\tInstall package:", target)
+	return []*Artifact{newArtifact("nsis", target)}, nil
+}
+
+// TargetDryRun 输出目标系统架构将要打包的文件, 当前系统架构时同 DryRun
+func TargetDryRun(proj *project.Project, t build.Target) ([]*project.PackageFile, error) {
+	if t.IsHost() {
+		return DryRun(proj)
+	}
+	exe, err := prepareTarget(proj, t)
+	if err != nil {
+		return nil, err
+	}
+	exeFiles, framework, include, err := nsisFiles(proj, exe)
+	if err != nil {
+		return nil, err
+	}
+	files := append(append(exeFiles, framework...), include...)
+	return files, printPackageFiles(files)
+}
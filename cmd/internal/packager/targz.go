@@ -0,0 +1,137 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package packager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tarGz tar.gz 压缩包
+//  文件所有者 root, 修改时间固定, 权限 0755/0644, 相同输入生成相同压缩包
+type tarGz struct {
+	gw      *gzip.Writer
+	tw      *tar.Writer
+	mtime   time.Time
+	md5sums *bytes.Buffer // 不为空时记录文件 md5, deb md5sums 格式
+}
+
+func newTarGz(w io.Writer, mtime time.Time) (*tarGz, error) {
+	gw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	return &tarGz{gw: gw, tw: tar.NewWriter(gw), mtime: mtime}, nil
+}
+
+func (m *tarGz) header(name string, mode fs.FileMode) *tar.Header {
+	perm := int64(0644)
+	if mode.IsDir() || mode&0111 != 0 {
+		perm = 0755
+	}
+	return &tar.Header{
+		Name:    name,
+		Mode:    perm,
+		ModTime: m.mtime,
+		Uname:   "root",
+		Gname:   "root",
+		Format:  tar.FormatPAX,
+	}
+}
+
+// AddDir 添加目录, 压缩包内路径 prefix/相对路径, skip 返回 true 时跳过
+//  prefix 为 . 时路径为 ./相对路径
+func (m *tarGz) AddDir(dir, prefix string, skip func(rel string) bool) error {
+	return filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != "." && skip != nil && skip(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := os.Lstat(filePath)
+		if err != nil {
+			return err
+		}
+		name := prefix
+		if rel != "." {
+			name += "/" + rel
+		}
+		h := m.header(name, info.Mode())
+		switch {
+		case info.IsDir():
+			h.Typeflag, h.Name = tar.TypeDir, h.Name+"/"
+			return m.tw.WriteHeader(h)
+		case info.Mode()&fs.ModeSymlink != 0:
+			if h.Linkname, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+			h.Typeflag = tar.TypeSymlink
+			return m.tw.WriteHeader(h)
+		case info.Mode().IsRegular():
+			h.Typeflag, h.Size = tar.TypeReg, info.Size()
+			if err = m.tw.WriteHeader(h); err != nil {
+				return err
+			}
+			f, err := os.Open(filePath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if m.md5sums == nil {
+				_, err = io.Copy(m.tw, f)
+				return err
+			}
+			sum := md5.New()
+			if _, err = io.Copy(io.MultiWriter(m.tw, sum), f); err != nil {
+				return err
+			}
+			fmt.Fprintf(m.md5sums, "%x  %s\n", sum.Sum(nil), strings.TrimPrefix(name, "./"))
+			return nil
+		}
+		return nil
+	})
+}
+
+// AddFile 添加文件
+func (m *tarGz) AddFile(name string, mode fs.FileMode, data []byte) error {
+	h := m.header(name, mode)
+	h.Typeflag, h.Size = tar.TypeReg, int64(len(data))
+	if err := m.tw.WriteHeader(h); err != nil {
+		return err
+	}
+	_, err := m.tw.Write(data)
+	return err
+}
+
+func (m *tarGz) Close() error {
+	if err := m.tw.Close(); err != nil {
+		return err
+	}
+	return m.gw.Close()
+}
@@ -0,0 +1,254 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 项目配置 energy.json
+//  读取顺序: JSON 语法检查, ${ENV} 环境变量替换, schema 校验, 合并当前系统的覆盖配置
+//  字符串中 ${NAME} 或 ${NAME:-default} 替换为环境变量, $${ 输出 ${
+//  windows, linux, darwin 为对应系统的覆盖配置, 对象逐级合并, 其它值直接替换
+//  configVersion 配置版本, 低于当前版本时使用 energy config migrate 升级
+
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/energye/energy/v2/pkgs/json/schema"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ConfigVersion 当前配置版本
+const ConfigVersion = 1
+
+// 覆盖配置的系统
+var osKeys = []string{"windows", "linux", "darwin"}
+
+var envPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+var energySchema *schema.Schema
+
+func init() {
+	var err error
+	if energySchema, err = schema.New(schemaJSON); err != nil {
+		panic("energy.schema.json: " + err.Error())
+	}
+}
+
+// checkConfig 检查配置, 返回替换环境变量后的节点和所有警告, 错误
+func checkConfig(file string, data []byte) (*node, []*Issue, error) {
+	root, err := parseNode(file, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var issues []*Issue
+	interpolate(file, data, root, "", &issues)
+	validateSchema(energySchema, file, data, root, "", &issues)
+	if root.kind == "object" && configVersion(root) < ConfigVersion {
+		issues = append(issues, &Issue{File: file, Path: "configVersion", Warning: true,
+			Message: fmt.Sprintf("configuration is older than version %d, run energy config migrate", ConfigVersion)})
+	}
+	return root, issues, nil
+}
+
+// loadConfig 读取配置, 返回合并 goos 覆盖配置后的值和警告, 有错误时返回 *ConfigError
+func loadConfig(file string, data []byte, goos string) (map[string]any, []*Issue, error) {
+	root, issues, err := checkConfig(file, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var warnings, errs []*Issue
+	for _, issue := range issues {
+		if issue.Warning {
+			warnings = append(warnings, issue)
+		} else {
+			errs = append(errs, issue)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, warnings, &ConfigError{Issues: errs}
+	}
+	value := root.value().(map[string]any)
+	if override, ok := value[goos].(map[string]any); ok {
+		merge(value, override)
+	}
+	for _, key := range osKeys {
+		delete(value, key)
+	}
+	return value, warnings, nil
+}
+
+// 配置版本, 未配置时为 0
+func configVersion(root *node) int {
+	if v := root.field("configVersion"); v != nil && v.kind == "number" {
+		if n, err := json.Number(v.str).Int64(); err == nil {
+			return int(n)
+		}
+	}
+	return 0
+}
+
+// interpolate 替换字符串中的环境变量, 未定义且没有默认值时警告并替换为空
+func interpolate(file string, data []byte, n *node, path string, issues *[]*Issue) {
+	switch n.kind {
+	case "object":
+		for i, key := range n.keys {
+			interpolate(file, data, n.fields[i], joinPath(path, key.str), issues)
+		}
+	case "array":
+		for i, item := range n.items {
+			interpolate(file, data, item, fmt.Sprintf("%s[%d]", path, i), issues)
+		}
+	case "string":
+		n.str = envPattern.ReplaceAllStringFunc(n.str, func(s string) string {
+			if s == "$${" {
+				return "${"
+			}
+			match := envPattern.FindStringSubmatch(s)
+			if value, ok := os.LookupEnv(match[1]); ok {
+				return value
+			}
+			if match[2] != "" {
+				return match[3]
+			}
+			line, column := lineColumn(data, n.offset)
+			*issues = append(*issues, &Issue{File: file, Line: line, Column: column, Path: path, Warning: true,
+				Message: fmt.Sprintf("environment variable %s is not set", match[1])})
+			return ""
+		})
+	}
+}
+
+// merge 合并覆盖配置, 对象逐级合并, 其它值替换
+func merge(dst, src map[string]any) {
+	for key, value := range src {
+		if s, ok := value.(map[string]any); ok {
+			if d, ok := dst[key].(map[string]any); ok {
+				merge(d, s)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+// Validate 校验配置文件, 返回所有警告和错误, 包括 JSON 语法错误
+func Validate(file string) ([]*Issue, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	_, issues, err := checkConfig(file, data)
+	if e, ok := err.(*ConfigError); ok {
+		return e.Issues, nil
+	}
+	return issues, err
+}
+
+// migration 配置迁移, 从 version-1 升级到 version, 返回变更说明
+type migration struct {
+	version int
+	migrate func(root *node) []string
+}
+
+var migrations = []migration{
+	{version: 1, migrate: migrateV1},
+}
+
+// Migrate 升级配置到当前版本, 保持 key 顺序, 返回升级后的配置和变更说明
+func Migrate(file string, data []byte) ([]byte, []string, error) {
+	root, err := parseNode(file, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if root.kind != "object" {
+		return nil, nil, &ConfigError{Issues: []*Issue{{File: file, Line: 1, Column: 1, Message: "expected object"}}}
+	}
+	version := configVersion(root)
+	var changes []string
+	for _, m := range migrations {
+		if m.version > version {
+			changes = append(changes, m.migrate(root)...)
+		}
+	}
+	if version < ConfigVersion {
+		setField(root, "configVersion", &node{kind: "number", str: fmt.Sprint(ConfigVersion)})
+		changes = append(changes, fmt.Sprintf("configVersion: %d -> %d", version, ConfigVersion))
+	}
+	var s strings.Builder
+	root.encode(&s, "")
+	s.WriteString("\n")
+	return []byte(s.String()), changes, nil
+}
+
+// 设置字段, 不存在时添加到 $schema 之后
+func setField(root *node, key string, value *node) {
+	for i, k := range root.keys {
+		if k.str == key {
+			root.fields[i] = value
+			return
+		}
+	}
+	index := 0
+	if len(root.keys) > 0 && root.keys[0].str == "$schema" {
+		index = 1
+	}
+	root.keys = append(root.keys[:index], append([]*node{{kind: "string", str: key}}, root.keys[index:]...)...)
+	root.fields = append(root.fields[:index], append([]*node{value}, root.fields[index:]...)...)
+}
+
+// migrateV1
+//  key 大小写和 schema 一致, 例如 info.FileVersion -> info.fileVersion
+//  删除未使用的 dpkg.compressName
+func migrateV1(root *node) []string {
+	var changes []string
+	renameKeys(energySchema, root, "", &changes)
+	for _, key := range append([]string{""}, osKeys...) {
+		n := root
+		if key != "" {
+			if n = root.field(key); n == nil {
+				continue
+			}
+		}
+		if dpkg := n.field("dpkg"); dpkg != nil && dpkg.kind == "object" && dpkg.field("compressName") != nil {
+			dpkg.remove("compressName")
+			changes = append(changes, joinPath(key, "dpkg.compressName")+": removed, not used by linux packages")
+		}
+	}
+	return changes
+}
+
+// 按 schema 修正 key 大小写, 正确的 key 已存在时删除错误的 key
+func renameKeys(s *schema.Schema, n *node, path string, changes *[]string) {
+	properties := schemaProperties(s)
+	if properties == nil || n.kind != "object" {
+		return
+	}
+	names := propertyNames(s)
+	for i := 0; i < len(n.keys); i++ {
+		key := n.keys[i]
+		prop, ok := properties[key.str]
+		if !ok {
+			name := foldName(names, key.str)
+			if name == "" {
+				continue
+			}
+			if n.field(name) != nil {
+				*changes = append(*changes, fmt.Sprintf("%s: removed, %s is used", joinPath(path, key.str), name))
+				n.remove(key.str)
+				i--
+				continue
+			}
+			*changes = append(*changes, fmt.Sprintf("%s -> %s", joinPath(path, key.str), joinPath(path, name)))
+			key.str, prop = name, properties[name]
+		}
+		renameKeys(prop, n.fields[i], joinPath(path, key.str), changes)
+	}
+}
@@ -0,0 +1,145 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package project
+
+import (
+	"github.com/energye/energy/v2/pkgs/json/schema"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testConfig = `{
+  "configVersion": 1,
+  "name": "demo",
+  "info": {
+    "icon": "${TEST_ENERGY_ICON}",
+    "FileVersion": "1.0.0",
+    "productVersion": "${TEST_ENERGY_UNSET:-2.0.0}",
+    "compnyName": "demo"
+  },
+  "dpkg": {
+    "format": "deb, rpm",
+    "compressName": "framework.7z"
+  },
+  "linux": {
+    "info": {
+      "icon": "linux.png"
+    },
+    "outputFilename": "demo-linux"
+  }
+}`
+
+func issueStrings(issues []*Issue) []string {
+	var result []string
+	for _, issue := range issues {
+		result = append(result, issue.Error())
+	}
+	return result
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("TEST_ENERGY_ICON", "icon.png")
+	value, warnings, err := loadConfig("energy.json", []byte(testConfig), "windows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`energy.json:6:5: warning: info.FileVersion: key "FileVersion" should be "fileVersion", run energy config migrate`,
+		`energy.json:8:5: warning: info.compnyName: unknown key "compnyName", did you mean "companyName"?`,
+		`energy.json:12:5: warning: dpkg.compressName: unknown key "compressName"`,
+	}
+	if got := issueStrings(warnings); !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	info := value["info"].(map[string]any)
+	if info["icon"] != "icon.png" || info["productVersion"] != "2.0.0" {
+		t.Errorf("interpolate: %v", info)
+	}
+	if _, ok := value["linux"]; ok {
+		t.Error("override not removed")
+	}
+
+	value, _, err = loadConfig("energy.json", []byte(testConfig), "linux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info = value["info"].(map[string]any)
+	if info["icon"] != "linux.png" || info["FileVersion"] != "1.0.0" || value["outputFilename"] != "demo-linux" {
+		t.Errorf("linux override: %v", value)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	_, _, err := loadConfig("energy.json", []byte("{\n  \"name\": 1,\n  \"dpkg\": {\"format\": \"zip\"},\n  \"windows\": {\"nsis\": {\"requestExecutionLevel\": \"root\"}}\n}"), "linux")
+	want := []string{
+		`energy.json:2:11: name: expected string, got integer`,
+		`energy.json:3:22: dpkg.format: does not match pattern "^\\s*((deb|rpm|appimage|tar\\.gz|tgz)\\s*(,\\s*|$))*$"`,
+		`energy.json:4:49: windows.nsis.requestExecutionLevel: must be one of ["","admin","user","highest","none"]`,
+	}
+	e, ok := err.(*ConfigError)
+	if !ok {
+		t.Fatalf("expected *ConfigError, got %v", err)
+	}
+	if got := issueStrings(e.Issues); !reflect.DeepEqual(got, want) {
+		t.Errorf("errors\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	_, _, err = loadConfig("energy.json", []byte("{\n  \"name\": \"demo\",\n  \"info\": {,}\n}"), "linux")
+	if err == nil || !strings.HasPrefix(err.Error(), "energy.json:3:12: ") {
+		t.Errorf("syntax error %v", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	old := strings.Replace(testConfig, "\"configVersion\": 1,\n  ", "", 1)
+	migrated, changes, err := Migrate("energy.json", []byte(old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"info.FileVersion -> info.fileVersion",
+		"dpkg.compressName: removed, not used by linux packages",
+		"configVersion: 0 -> 1",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes %v, want %v", changes, want)
+	}
+	expected := strings.NewReplacer(`"FileVersion"`, `"fileVersion"`, ",\n    \"compressName\": \"framework.7z\"", "").Replace(testConfig) + "\n"
+	if string(migrated) != expected {
+		t.Errorf("migrated\n%s\nwant\n%s", migrated, expected)
+	}
+	if _, changes, _ = Migrate("energy.json", migrated); len(changes) != 0 {
+		t.Errorf("second migration changes %v", changes)
+	}
+}
+
+// schema 和 Project json 字段一致
+func TestSchemaFields(t *testing.T) {
+	var check func(typ reflect.Type, s *schema.Schema, path string)
+	check = func(typ reflect.Type, s *schema.Schema, path string) {
+		for i := 0; i < typ.NumField(); i++ {
+			tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+			if tag == "" || tag == "-" {
+				continue
+			}
+			prop, ok := schemaProperties(s)[tag]
+			if !ok {
+				t.Errorf("schema missing %s", joinPath(path, tag))
+				continue
+			}
+			if ft := typ.Field(i).Type; ft.Kind() == reflect.Struct {
+				check(ft, prop, joinPath(path, tag))
+			}
+		}
+	}
+	check(reflect.TypeOf(Project{}), energySchema, "")
+}
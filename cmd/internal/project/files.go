@@ -0,0 +1,301 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package project
+
+import (
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 打包文件匹配, NSIS, DPKG, PList 的 include 和 exclude 使用相同规则
+//  路径使用 / 分隔, 相对匹配目录
+//  *, ?, [a-z] 匹配一级目录或文件名中的字符, *.* 和 * 相同 (windows 习惯)
+//  ** 匹配任意级目录
+//  /开头 或 包含 / 时从根目录匹配, 否则匹配任意一级目录或文件名, 例如 locales 匹配 a/locales
+//  /结尾 只匹配目录
+//  匹配目录时包含目录中所有文件
+//  !开头 取反, 按顺序匹配, 最后匹配的规则生效, 例如 ["locales/", "!locales/en-US.pak"]
+
+type pattern struct {
+	negate  bool
+	dirOnly bool
+	segs    []string
+}
+
+// Matcher 文件匹配
+type Matcher struct {
+	patterns []*pattern
+}
+
+// NewMatcher 创建文件匹配, 规则无效时返回错误
+func NewMatcher(patterns []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, text := range patterns {
+		p := &pattern{}
+		s := strings.TrimSpace(filepath.ToSlash(text))
+		if strings.HasPrefix(s, "!") {
+			p.negate, s = true, s[1:]
+		}
+		if strings.HasSuffix(s, "/") {
+			p.dirOnly, s = true, strings.TrimRight(s, "/")
+		}
+		anchored := strings.Contains(s, "/")
+		s = strings.TrimPrefix(s, "/")
+		if s == "" {
+			continue
+		}
+		if !anchored {
+			p.segs = append(p.segs, "**")
+		}
+		for _, seg := range strings.Split(s, "/") {
+			switch seg {
+			case "", ".":
+				continue
+			case "*.*":
+				seg = "*"
+			}
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", text, err)
+			}
+			p.segs = append(p.segs, seg)
+		}
+		m.patterns = append(m.patterns, p)
+	}
+	return m, nil
+}
+
+// Empty 没有规则
+func (m *Matcher) Empty() bool {
+	return m == nil || len(m.patterns) == 0
+}
+
+// 是否有 ! 规则, 有时匹配的目录中的文件可能不匹配, 不能跳过目录
+func (m *Matcher) hasNegate() bool {
+	if m == nil {
+		return false
+	}
+	for _, p := range m.patterns {
+		if p.negate {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipDir 排除目录, 跳过整个目录
+func (m *Matcher) SkipDir(rel string) bool {
+	return !m.hasNegate() && m.Match(rel, true)
+}
+
+// Match 是否匹配, rel 为相对路径, 上级目录匹配时也匹配
+func (m *Matcher) Match(rel string, isDir bool) bool {
+	if m.Empty() {
+		return false
+	}
+	parts := strings.Split(strings.Trim(filepath.ToSlash(rel), "/"), "/")
+	var matched bool
+	for _, p := range m.patterns {
+		for i := 1; i <= len(parts); i++ {
+			if p.dirOnly && i == len(parts) && !isDir {
+				continue
+			}
+			if matchSegs(p.segs, parts[:i]) {
+				matched = !p.negate
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// 逐级匹配, ** 匹配零或多级
+func matchSegs(segs, parts []string) bool {
+	for len(segs) > 0 {
+		if segs[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegs(segs[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(segs[0], parts[0]); !ok {
+			return false
+		}
+		segs, parts = segs[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// PackageFile 打包文件
+type PackageFile struct {
+	Source string      `json:"source"` // 源文件
+	Target string      `json:"target"` // 安装包内路径, / 分隔
+	Size   int64       `json:"size"`   // 文件大小
+	Mode   fs.FileMode `json:"-"`      // 文件权限
+}
+
+// Files 返回 src 中未排除的文件, 目标路径 dst/相对路径, src 为文件时目标路径 dst/文件名
+func Files(src, dst string, exclude []string) ([]*PackageFile, error) {
+	excludes, err := NewMatcher(exclude)
+	if err != nil {
+		return nil, err
+	}
+	return walkFiles(src, func(filePath string, isDir bool) (string, bool) {
+		rel, _ := filepath.Rel(src, filePath)
+		if rel == "." {
+			rel = filepath.Base(src)
+		}
+		rel = filepath.ToSlash(rel)
+		if isDir {
+			return "", !excludes.SkipDir(rel)
+		}
+		if excludes.Match(rel, false) {
+			return "", true
+		}
+		return path.Join(dst, rel), true
+	})
+}
+
+// IncludeFiles 返回匹配 include 且未排除的文件
+//  相对路径和项目中的绝对路径: 目标路径 dst/相对项目路径
+//  项目外存在的绝对路径:
+//   /to/dir, /to/file.txt 目标路径 dst/dir/相对路径, dst/file.txt
+//   /to/dir/*.* 目录中的内容, 目标路径 dst/相对 dir 的路径
+//  其它 / 开头的路径从项目目录匹配
+func IncludeFiles(projectPath, dst string, include, exclude []string) ([]*PackageFile, error) {
+	excludes, err := NewMatcher(exclude)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		result []*PackageFile
+		exists = make(map[string]bool)
+	)
+	for _, text := range include {
+		s := strings.TrimSpace(filepath.ToSlash(text))
+		if s == "" || strings.HasPrefix(s, "!") {
+			continue
+		}
+		root, rel := projectPath, strings.TrimPrefix(s, "/")
+		if filepath.IsAbs(filepath.FromSlash(s)) {
+			if r, err := filepath.Rel(projectPath, filepath.FromSlash(s)); err == nil && !strings.HasPrefix(r, "..") {
+				rel = filepath.ToSlash(r)
+			} else if base := staticBase(s); base != "" && tools.IsExist(filepath.FromSlash(base)) {
+				if base == s {
+					// 目录或文件本身
+					root = filepath.Dir(filepath.FromSlash(base))
+					rel = path.Base(base)
+				} else {
+					// 通配符匹配目录中的内容, 同 NSIS File /r "/to/dir/*.*"
+					root = filepath.FromSlash(base)
+					rel = strings.TrimPrefix(s, base+"/")
+				}
+			}
+		}
+		// include 规则和所有 ! 规则
+		includes, err := NewMatcher(append([]string{"/" + rel}, negations(include)...))
+		if err != nil {
+			return nil, err
+		}
+		start := filepath.Join(root, filepath.FromSlash(staticBase(rel)))
+		if !tools.IsExist(start) {
+			return nil, fmt.Errorf("include %s: %s does not exist", text, start)
+		}
+		files, err := walkFiles(start, func(filePath string, isDir bool) (string, bool) {
+			r, _ := filepath.Rel(root, filePath)
+			r = filepath.ToSlash(r)
+			if isDir {
+				return "", !excludes.SkipDir(r)
+			}
+			if excludes.Match(r, false) || !includes.Match(r, false) {
+				return "", true
+			}
+			return path.Join(dst, r), true
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if !exists[f.Target] {
+				exists[f.Target] = true
+				result = append(result, f)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Target < result[j].Target
+	})
+	return result, nil
+}
+
+// ! 规则
+func negations(patterns []string) []string {
+	var result []string
+	for _, p := range patterns {
+		if strings.HasPrefix(strings.TrimSpace(p), "!") {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// 第一个通配符之前的路径
+func staticBase(s string) string {
+	var base []string
+	for _, seg := range strings.Split(s, "/") {
+		if strings.ContainsAny(seg, "*?[") {
+			break
+		}
+		base = append(base, seg)
+	}
+	return strings.TrimSuffix(strings.Join(base, "/"), "/")
+}
+
+// 遍历 root, root 为文件时只有 root
+//  target 返回目标路径, false 时跳过, 目录返回 false 时跳过整个目录, 目标路径为空时不添加
+func walkFiles(root string, target func(filePath string, isDir bool) (string, bool)) ([]*PackageFile, error) {
+	var files []*PackageFile
+	err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == root && d.IsDir() {
+			return nil
+		}
+		t, ok := target(filePath, d.IsDir())
+		if !ok {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || t == "" {
+			return nil
+		}
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, &PackageFile{Source: filePath, Target: t, Size: info.Size(), Mode: info.Mode()})
+		return nil
+	})
+	return files, err
+}
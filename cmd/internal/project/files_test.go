@@ -0,0 +1,122 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{[]string{"locales"}, "locales/zh-CN.pak", false, true},
+		{[]string{"locales"}, "a/locales/zh-CN.pak", false, true},
+		{[]string{"/locales"}, "a/locales/zh-CN.pak", false, false},
+		{[]string{"locales/"}, "locales", false, false},
+		{[]string{"locales/"}, "locales", true, true},
+		{[]string{"*.pak"}, "locales/zh-CN.pak", false, true},
+		{[]string{"locales/*.pak"}, "locales/zh-CN.pak", false, true},
+		{[]string{"locales/*.pak"}, "a/locales/zh-CN.pak", false, false},
+		{[]string{"**/locales/*.pak"}, "a/b/locales/zh-CN.pak", false, true},
+		{[]string{"a/**/c.txt"}, "a/c.txt", false, true},
+		{[]string{"a/**"}, "a/b/c.txt", false, true},
+		{[]string{"/to/dir/*.*"}, "to/dir/LICENSE", false, true},
+		{[]string{"locales/", "!locales/en-US.pak"}, "locales/en-US.pak", false, false},
+		{[]string{"locales/", "!locales/en-US.pak"}, "locales/zh-CN.pak", false, true},
+		{[]string{"!*.pak", "*.pak"}, "en-US.pak", false, true},
+		{nil, "a", false, false},
+	}
+	for _, tt := range tests {
+		m, err := NewMatcher(tt.patterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("%v Match(%s, %v) = %v, want %v", tt.patterns, tt.path, tt.isDir, got, tt.want)
+		}
+	}
+	if _, err := NewMatcher([]string{"a/[b"}); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
+
+func TestIncludeFiles(t *testing.T) {
+	proj := t.TempDir()
+	outside := t.TempDir()
+	for _, file := range []string{
+		filepath.Join(proj, "resources", "a.png"),
+		filepath.Join(proj, "resources", "b.psd"),
+		filepath.Join(proj, "resources", "icons", "c.png"),
+		filepath.Join(proj, "config.json"),
+		filepath.Join(outside, "data", "d.txt"),
+	} {
+		os.MkdirAll(filepath.Dir(file), 0755)
+		os.WriteFile(file, []byte("x"), 0644)
+	}
+	files, err := IncludeFiles(proj, "opt/app", []string{
+		"resources/**/*.png",
+		"config.json",
+		filepath.Join(outside, "data"),
+		"!resources/icons/",
+	}, []string{"*.psd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var targets []string
+	for _, f := range files {
+		targets = append(targets, f.Target)
+	}
+	want := []string{"opt/app/config.json", "opt/app/data/d.txt", "opt/app/resources/a.png"}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets %v, want %v", targets, want)
+	}
+	// 项目外目录中的内容直接在 dst 中
+	os.MkdirAll(filepath.Join(outside, "data", "sub"), 0755)
+	os.WriteFile(filepath.Join(outside, "data", "sub", "e.txt"), []byte("x"), 0644)
+	files, err = IncludeFiles(proj, "$INSTDIR", []string{filepath.ToSlash(filepath.Join(outside, "data")) + "/*.*"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets = nil
+	for _, f := range files {
+		targets = append(targets, f.Target)
+	}
+	want = []string{"$INSTDIR/d.txt", "$INSTDIR/sub/e.txt"}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets %v, want %v", targets, want)
+	}
+	if _, err = IncludeFiles(proj, "opt/app", []string{"missing/*.png"}, nil); err == nil {
+		t.Error("expected error for missing include")
+	}
+
+	files, err = Files(filepath.Join(proj, "resources"), "opt/app", []string{"icons/", "*.psd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Target != "opt/app/a.png" {
+		t.Errorf("files %v", files)
+	}
+	// ! 规则包含排除目录中的文件
+	files, err = Files(filepath.Join(proj, "resources"), "opt/app", []string{"*.p*", "icons/", "!icons/c.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Target != "opt/app/icons/c.png" {
+		t.Errorf("files %v", files)
+	}
+}
@@ -12,7 +12,10 @@ package project
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/framework"
+	"github.com/energye/energy/v2/cmd/internal/term"
 	"github.com/energye/energy/v2/cmd/internal/tools"
 	"os"
 	"path/filepath"
@@ -29,22 +32,25 @@ const (
 
 // Project holds the data related to a ENERGY project
 type Project struct {
-	AppType        AppType `json:"-"`              // app, helper
-	Clean          bool    `json:"-"`              // 清空配置重新生成
-	TempDll        bool    `json:"-"`              // 使用内置liblcl构建
-	Name           string  `json:"name"`           // 应用名称
-	ProjectPath    string  `json:"projectPath"`    // 项目目录
-	FrameworkPath  string  `json:"frameworkPath"`  // 框架目录 未指定时使用环境变量 ENERGY_HOME
-	AssetsDir      string  `json:"assetsDir"`      // 构建配置所在目录 未指定使用田默认内置配置
-	OutputFilename string  `json:"outputFilename"` // 输出安装包文件名
-	Info           Info    `json:"info"`           // 应用信息
-	NSIS           NSIS    `json:"nsis"`           // windows nsis 安装包
-	Dpkg           DPKG    `json:"dpkg"`           // linux dpkg 安装包
-	PList          PList   `json:"plist"`          // darwin plist 安装包
-	Author         Author  `json:"author"`         // 作者信息
+	AppType          AppType `json:"-"`                          // app, helper
+	ConfigVersion    int     `json:"configVersion"`              // 配置版本
+	Clean            bool    `json:"-"`                          // 清空配置重新生成
+	TempDll          bool    `json:"-"`                          // 使用内置liblcl构建
+	Name             string  `json:"name"`                       // 应用名称
+	ProjectPath      string  `json:"projectPath"`                // 项目目录
+	FrameworkPath    string  `json:"frameworkPath"`              // 框架目录 未指定时使用 frameworkVersion, energy use 或环境变量 ENERGY_HOME
+	FrameworkVersion string  `json:"frameworkVersion,omitempty"` // 框架名称或版本, energy list 列出的已安装框架
+	AssetsDir        string  `json:"assetsDir"`                  // 构建配置所在目录 未指定使用田默认内置配置
+	OutputFilename   string  `json:"outputFilename"`             // 输出安装包文件名
+	Info             Info    `json:"info"`                       // 应用信息
+	NSIS             NSIS    `json:"nsis"`                       // windows nsis 安装包
+	Dpkg             DPKG    `json:"dpkg"`                       // linux dpkg 安装包
+	PList            PList   `json:"plist"`                      // darwin plist 安装包
+	Author           Author  `json:"author"`                     // 作者信息
+	Dev              Dev     `json:"dev"`                        // energy dev 开发配置
 }
 
-func (m *Project) setDefaults() {
+func (m *Project) setDefaults(goos string) error {
 	if m.Name == "" {
 		m.Name = "energyapp"
 	}
@@ -53,10 +59,14 @@ func (m *Project) setDefaults() {
 		m.ProjectPath = tools.CurrentExecuteDir()
 	}
 	if m.FrameworkPath == "" {
-		m.FrameworkPath = os.Getenv(consts.EnergyHomeKey)
+		frameworkPath, err := framework.Resolve(m.FrameworkVersion)
+		if err != nil {
+			return err
+		}
+		m.FrameworkPath = frameworkPath
 	}
 	if !tools.IsExist(m.FrameworkPath) {
-		panic("energy framework directory does not exist: " + m.FrameworkPath)
+		return errors.New("energy framework directory does not exist: " + m.FrameworkPath)
 	}
 	if m.AssetsDir == "" {
 		m.AssetsDir = "assets"
@@ -87,7 +97,7 @@ func (m *Project) setDefaults() {
 		v := "Built using ENERGY (https://github.com/energye/energy)"
 		m.Info.FileDescription = &v
 	}
-	switch runtime.GOOS {
+	switch goos {
 	case "windows":
 		if !strings.HasSuffix(m.OutputFilename, ".exe") {
 			m.OutputFilename += ".exe"
@@ -95,7 +105,7 @@ func (m *Project) setDefaults() {
 	case "darwin", "linux":
 		m.OutputFilename = strings.TrimSuffix(m.OutputFilename, ".exe")
 	}
-
+	return nil
 }
 
 type Info struct {
@@ -103,7 +113,7 @@ type Info struct {
 	Icon            string  `json:"icon"`            //应用图标
 	CompanyName     string  `json:"companyName"`     //公司名称
 	ProductName     string  `json:"productName"`     //产品名称
-	FileVersion     string  `json:"FileVersion"`     //文件版本
+	FileVersion     string  `json:"fileVersion"`     //文件版本
 	ProductVersion  string  `json:"productVersion"`  //产品版本
 	Copyright       *string `json:"copyright"`       //版权
 	Comments        *string `json:"comments"`        //exe详情描述
@@ -157,6 +167,8 @@ type DPKG struct {
 	Package      string   `json:"package"`
 	Homepage     string   `json:"homepage"`
 	Compress     string   `json:"compress"` //压纹CEF, 当前仅支持7z/a压缩，""(空)时不启用压缩 默认: 7za
+	Format       string   `json:"format"`   //安装包格式, 逗号分隔: deb, rpm, appimage, tar.gz 默认: deb
+	UseDpkg      bool     `json:"useDpkg"`  //使用 dpkg -b 生成 deb, 默认内置生成
 	UseCompress  bool     `json:"-"`        //如果支持配置的, true=使用压缩
 	CompressFile string   `json:"-"`        //压缩后的文件完全目录
 }
@@ -167,7 +179,7 @@ type PList struct {
 	Icon                       string   `json:"icon"`                       //应用图标, png 或 icns, 如果指定png则生成icns, 如果指定icns则直接使用
 	CompanyName                string   `json:"companyName"`                //公司名称
 	ProductName                string   `json:"productName"`                //产品名称
-	FileVersion                string   `json:"FileVersion"`                //文件版本
+	FileVersion                string   `json:"fileVersion"`                //文件版本
 	Locals                     []string `json:"locals"`                     //语言
 	CFBundleVersion            string   `json:"cfBundleVersion"`            //内部版本
 	CFBundleShortVersionString string   `json:"cfBundleShortVersionString"` //发布版本号版本
@@ -177,24 +189,63 @@ type PList struct {
 	Pkgbuild                   bool     `json:"-"`                          // 生成pkg安装包
 }
 
+// Dev energy dev 开发配置
+type Dev struct {
+	Frontend string   `json:"frontend"` //前端目录, 默认: 项目中存在 frontend/package.json 时为 frontend
+	Command  string   `json:"command"`  //前端开发服务命令, 在前端目录执行, 默认: npm run dev
+	URL      string   `json:"url"`      //前端开发服务地址, 默认: http://localhost:5173
+	Timeout  int      `json:"timeout"`  //等待前端开发服务端口的超时秒数, 默认: 60
+	Exclude  []string `json:"exclude"`  //不监听变化的目录或文件 ["/to/dir", "*_test.go"], 前端目录和 build 目录默认排除
+	Args     []string `json:"args"`     //应用启动参数
+}
+
 type Author struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 }
 
 //  APP项目配置转换到Project
-func parse(projectData []byte) (*Project, error) {
-	m := &Project{}
-	err := json.Unmarshal(projectData, m)
+//  校验配置, 输出警告, 合并 goos 的覆盖配置
+func parse(file string, projectData []byte, goos string) (*Project, error) {
+	value, warnings, err := loadConfig(file, projectData, goos)
+	for _, warning := range warnings {
+		term.Logger.Warn(warning.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
-	m.setDefaults()
+	// 继承工作区默认配置
+	workspace, warnings, err := FindWorkspace(filepath.Dir(file))
+	for _, warning := range warnings {
+		term.Logger.Warn(warning.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if workspace != nil {
+		value = workspace.inherit(value, goos)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	m := &Project{}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if err = m.setDefaults(goos); err != nil {
+		return nil, err
+	}
 	return m, nil
 }
 
 // NewProject 创建项目对象, 根据energy.json配置
 func NewProject(projectPath string) (*Project, error) {
+	return NewProjectOS(projectPath, runtime.GOOS)
+}
+
+// NewProjectOS 创建目标系统的项目对象, 使用 goos 的覆盖配置, 用于交叉打包
+func NewProjectOS(projectPath, goos string) (*Project, error) {
 	if projectPath == "" {
 		// 设置当前执行目录为项目目录
 		projectPath = tools.CurrentExecuteDir()
@@ -204,7 +255,7 @@ func NewProject(projectPath string) (*Project, error) {
 	if err != nil {
 		return nil, err
 	}
-	m, err := parse(rawBytes)
+	m, err := parse(config, rawBytes, goos)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,505 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// energy.json JSON Schema 校验
+//  使用 pkgs/json/schema 校验, 按错误的 JSON Pointer 查找节点位置, 错误和警告包含行列号
+//  additionalProperties: false 时未知的 key 为警告, 其它为错误
+
+package project
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/energye/energy/v2/pkgs/json/schema"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed energy.schema.json
+var schemaJSON []byte
+
+// Schema 返回 energy.json 的 JSON Schema
+func Schema() []byte {
+	return schemaJSON
+}
+
+// Issue 配置错误或警告
+type Issue struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string // 配置路径, 例如 info.icon
+	Message string
+	Warning bool
+}
+
+func (m *Issue) Error() string {
+	var s strings.Builder
+	s.WriteString(m.File)
+	if m.Line > 0 {
+		fmt.Fprintf(&s, ":%d:%d", m.Line, m.Column)
+	}
+	s.WriteString(": ")
+	if m.Warning {
+		s.WriteString("warning: ")
+	}
+	if m.Path != "" {
+		s.WriteString(m.Path + ": ")
+	}
+	s.WriteString(m.Message)
+	return s.String()
+}
+
+// ConfigError 配置校验错误
+type ConfigError struct {
+	Issues []*Issue
+}
+
+func (m *ConfigError) Error() string {
+	var msg = make([]string, len(m.Issues))
+	for i, issue := range m.Issues {
+		msg[i] = issue.Error()
+	}
+	return strings.Join(msg, "\n")
+}
+
+// JSON 节点, 记录位置和 key 顺序, 用于错误行列号和迁移后保持原有顺序
+type node struct {
+	kind   string // object, array, string, number, boolean, null
+	offset int
+	str    string // string 值, number 原始文本
+	boolV  bool
+	keys   []*node // object key, 顺序和 fields 相同
+	fields []*node
+	items  []*node
+}
+
+func (m *node) field(key string) *node {
+	for i, k := range m.keys {
+		if k.str == key {
+			return m.fields[i]
+		}
+	}
+	return nil
+}
+
+func (m *node) remove(key string) {
+	for i, k := range m.keys {
+		if k.str == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			m.fields = append(m.fields[:i], m.fields[i+1:]...)
+			return
+		}
+	}
+}
+
+// value 转换为 encoding/json 的值
+func (m *node) value() any {
+	switch m.kind {
+	case "object":
+		result := make(map[string]any, len(m.keys))
+		for i, k := range m.keys {
+			result[k.str] = m.fields[i].value()
+		}
+		return result
+	case "array":
+		result := make([]any, len(m.items))
+		for i, item := range m.items {
+			result[i] = item.value()
+		}
+		return result
+	case "string":
+		return m.str
+	case "number":
+		return json.Number(m.str)
+	case "boolean":
+		return m.boolV
+	}
+	return nil
+}
+
+// encode 格式化输出, 两个空格缩进, 只有简单值的数组输出在一行
+func (m *node) encode(s *strings.Builder, indent string) {
+	switch m.kind {
+	case "object":
+		if len(m.keys) == 0 {
+			s.WriteString("{}")
+			return
+		}
+		s.WriteString("{\n")
+		for i, k := range m.keys {
+			s.WriteString(indent + "  ")
+			writeJSONString(s, k.str)
+			s.WriteString(": ")
+			m.fields[i].encode(s, indent+"  ")
+			if i < len(m.keys)-1 {
+				s.WriteString(",")
+			}
+			s.WriteString("\n")
+		}
+		s.WriteString(indent + "}")
+	case "array":
+		simple := true
+		for _, item := range m.items {
+			simple = simple && item.kind != "object" && item.kind != "array"
+		}
+		if simple {
+			s.WriteString("[")
+			for i, item := range m.items {
+				if i > 0 {
+					s.WriteString(", ")
+				}
+				item.encode(s, indent)
+			}
+			s.WriteString("]")
+			return
+		}
+		s.WriteString("[\n")
+		for i, item := range m.items {
+			s.WriteString(indent + "  ")
+			item.encode(s, indent+"  ")
+			if i < len(m.items)-1 {
+				s.WriteString(",")
+			}
+			s.WriteString("\n")
+		}
+		s.WriteString(indent + "]")
+	case "string":
+		writeJSONString(s, m.str)
+	case "number":
+		s.WriteString(m.str)
+	case "boolean":
+		fmt.Fprint(s, m.boolV)
+	default:
+		s.WriteString("null")
+	}
+}
+
+func writeJSONString(s *strings.Builder, v string) {
+	// 不转义 <, >, &
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	s.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// JSON 解析, 记录每个节点的位置
+type parser struct {
+	data []byte
+	pos  int
+}
+
+// 解析 JSON, 语法错误返回 *Issue 包含行列号
+func parseNode(file string, data []byte) (*node, error) {
+	// 语法检查使用 encoding/json, 错误信息一致
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		issue := &Issue{File: file, Message: err.Error()}
+		if e, ok := err.(*json.SyntaxError); ok {
+			issue.Line, issue.Column = lineColumn(data, int(e.Offset)-1)
+		}
+		return nil, &ConfigError{Issues: []*Issue{issue}}
+	}
+	p := &parser{data: data}
+	// 跳过 UTF-8 BOM
+	if strings.HasPrefix(string(data), "\xef\xbb\xbf") {
+		p.pos = 3
+	}
+	return p.parse(), nil
+}
+
+func (m *parser) skip() {
+	for m.pos < len(m.data) && strings.IndexByte(" \t\r\n", m.data[m.pos]) >= 0 {
+		m.pos++
+	}
+}
+
+// 输入已经通过 json.Unmarshal 检查, 不再处理语法错误
+func (m *parser) parse() *node {
+	m.skip()
+	n := &node{offset: m.pos}
+	switch c := m.data[m.pos]; {
+	case c == '{':
+		n.kind = "object"
+		m.pos++
+		for {
+			m.skip()
+			if m.data[m.pos] == '}' {
+				m.pos++
+				return n
+			}
+			if m.data[m.pos] == ',' {
+				m.pos++
+				continue
+			}
+			key := m.parse()
+			m.skip()
+			m.pos++ // :
+			n.keys = append(n.keys, key)
+			n.fields = append(n.fields, m.parse())
+		}
+	case c == '[':
+		n.kind = "array"
+		m.pos++
+		for {
+			m.skip()
+			if m.data[m.pos] == ']' {
+				m.pos++
+				return n
+			}
+			if m.data[m.pos] == ',' {
+				m.pos++
+				continue
+			}
+			n.items = append(n.items, m.parse())
+		}
+	case c == '"':
+		n.kind = "string"
+		start := m.pos
+		for m.pos++; m.data[m.pos] != '"'; m.pos++ {
+			if m.data[m.pos] == '\\' {
+				m.pos++
+			}
+		}
+		m.pos++
+		json.Unmarshal(m.data[start:m.pos], &n.str)
+	case c == 't' || c == 'f':
+		n.kind, n.boolV = "boolean", c == 't'
+		if n.boolV {
+			m.pos += 4
+		} else {
+			m.pos += 5
+		}
+	case c == 'n':
+		n.kind = "null"
+		m.pos += 4
+	default:
+		n.kind = "number"
+		start := m.pos
+		for m.pos < len(m.data) && strings.IndexByte("+-.0123456789eE", m.data[m.pos]) >= 0 {
+			m.pos++
+		}
+		n.str = string(m.data[start:m.pos])
+	}
+	return n
+}
+
+// 偏移量转换为行列号, 从 1 开始, 列按字符计算
+func lineColumn(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	line, start := 1, 0
+	for i := 0; i < offset; i++ {
+		if data[i] == '\n' {
+			line, start = line+1, i+1
+		}
+	}
+	return line, utf8.RuneCount(data[start:offset]) + 1
+}
+
+// schema 的属性, 包括 $ref 引用的 schema
+func schemaProperties(s *schema.Schema) map[string]*schema.Schema {
+	for s != nil {
+		if s.Properties != nil {
+			return s.Properties
+		}
+		next := s.Resolve()
+		if next == s {
+			break
+		}
+		s = next
+	}
+	return nil
+}
+
+// schema 的数组元素, 包括 $ref 引用的 schema
+func schemaItems(s *schema.Schema) *schema.Schema {
+	for s != nil {
+		if s.Items != nil {
+			return s.Items
+		}
+		next := s.Resolve()
+		if next == s {
+			break
+		}
+		s = next
+	}
+	return nil
+}
+
+// 属性名称, 用于相似 key 提示
+func propertyNames(s *schema.Schema) []string {
+	var names []string
+	for name := range schemaProperties(s) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 大小写不同的属性名
+func foldName(names []string, key string) string {
+	for _, name := range names {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+	}
+	return ""
+}
+
+// JSON Pointer 对应的节点
+type location struct {
+	node   *node          // 值, 不存在时为 nil
+	key    *node          // object 的 key, 数组元素为 nil
+	parent *node          // 上级节点
+	schema *schema.Schema // 上级节点的 schema
+	path   string         // 配置路径
+}
+
+// 按 JSON Pointer 查找节点和上级 schema
+func locate(s *schema.Schema, n *node, path, pointer string) *location {
+	loc := &location{node: n, path: path, schema: s}
+	if pointer == "" {
+		return loc
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		cur := loc.node
+		loc.parent, loc.schema, loc.key, loc.node = cur, s, nil, nil
+		if cur == nil {
+			break
+		}
+		switch cur.kind {
+		case "object":
+			loc.path = joinPath(loc.path, token)
+			s = schemaProperties(s)[token]
+			for i, k := range cur.keys {
+				if k.str == token {
+					loc.key, loc.node = k, cur.fields[i]
+				}
+			}
+		case "array":
+			loc.path = fmt.Sprintf("%s[%s]", loc.path, token)
+			s = schemaItems(s)
+			if i, err := strconv.Atoi(token); err == nil && i < len(cur.items) {
+				loc.node = cur.items[i]
+			}
+		}
+	}
+	return loc
+}
+
+// 使用 JSON Schema 校验节点, path 为节点的配置路径, 例如工作区的 defaults
+//  错误按位置排序, 相同位置和内容的错误只保留一个 ($ref 和引用它的 schema 都会校验)
+func validateSchema(s *schema.Schema, file string, data []byte, n *node, path string, issues *[]*Issue) {
+	e, ok := s.Validate(n.value()).(*schema.ValidationError)
+	if !ok {
+		return
+	}
+	var (
+		result []*Issue
+		seen   = make(map[string]bool)
+	)
+	for _, schemaErr := range e.Errors {
+		loc := locate(s, n, path, schemaErr.Pointer)
+		issue := &Issue{File: file, Path: loc.path, Message: schemaErr.Message}
+		at := loc.node
+		if schemaErr.Keyword == "additionalProperties" {
+			// 未知的 key 为警告
+			at, issue.Warning = loc.key, true
+			names := propertyNames(loc.schema)
+			key := loc.key.str
+			if name := foldName(names, key); name != "" {
+				issue.Message = fmt.Sprintf("key %q should be %q, run energy config migrate", key, name)
+			} else if similar := similarName(names, key); similar != "" {
+				issue.Message = fmt.Sprintf("unknown key %q, did you mean %q?", key, similar)
+			} else {
+				issue.Message = fmt.Sprintf("unknown key %q", key)
+			}
+		}
+		if at == nil {
+			at = loc.parent
+		}
+		if at != nil {
+			issue.Line, issue.Column = lineColumn(data, at.offset)
+		}
+		if id := issue.Error(); !seen[id] {
+			seen[id] = true
+			result = append(result, issue)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Line != result[j].Line {
+			return result[i].Line < result[j].Line
+		}
+		return result[i].Column < result[j].Column
+	})
+	*issues = append(*issues, result...)
+}
+
+// 相似的属性名, 编辑距离不大于 2
+func similarName(names []string, key string) string {
+	var (
+		result string
+		best   = 3
+	)
+	for _, name := range names {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < best {
+			result, best = name, d
+		}
+	}
+	return result
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,254 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 工作区 energy.workspace.json, monorepo 中多个项目共享默认配置
+//  projects: 项目目录, 相对工作区, 规则同 include, 未配置时为工作区下所有包含 energy.json 的目录
+//  exclude: 排除的目录, 规则同 exclude, 默认排除 . 开头的目录和 node_modules
+//  defaults: 项目默认配置, 格式同 energy.json, 例如 author, info.companyName, nsis.language
+//  项目目录或上级目录中最近的工作区生效, 项目配置覆盖默认配置, 对象逐级合并
+//  合并顺序: defaults, defaults 的系统覆盖配置, 项目配置, 项目的系统覆盖配置
+//  defaults 中的 name, projectPath 不继承
+
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// 不继承的配置
+var notInherited = []string{"name", "projectPath", "configVersion", "$schema"}
+
+// Workspace 工作区
+type Workspace struct {
+	Root     string         `json:"-"` // 工作区目录
+	File     string         `json:"-"` // 工作区文件, 没有时为空
+	Projects []string       `json:"projects"`
+	Exclude  []string       `json:"exclude"`
+	Defaults map[string]any `json:"-"`
+}
+
+// WorkspaceProject 工作区中的项目
+type WorkspaceProject struct {
+	Name string `json:"name"` // energy.json name, 未配置时为目录名
+	Path string `json:"path"` // 项目目录
+	Rel  string `json:"rel"`  // 相对工作区目录, / 分隔
+}
+
+// LoadWorkspace 读取工作区文件, 返回警告, 有错误时返回 *ConfigError
+func LoadWorkspace(file string) (*Workspace, []*Issue, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	root, err := parseNode(file, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if root.kind != "object" {
+		return nil, nil, &ConfigError{Issues: []*Issue{{File: file, Line: 1, Column: 1, Message: "expected object"}}}
+	}
+	var issues []*Issue
+	for _, key := range root.keys {
+		if key.str != "projects" && key.str != "exclude" && key.str != "defaults" && key.str != "$schema" {
+			line, column := lineColumn(data, key.offset)
+			issues = append(issues, &Issue{File: file, Line: line, Column: column, Path: key.str, Warning: true,
+				Message: fmt.Sprintf("unknown key %q", key.str)})
+		}
+	}
+	defaults := root.field("defaults")
+	if defaults != nil {
+		interpolate(file, data, defaults, "defaults", &issues)
+		validateSchema(energySchema, file, data, defaults, "defaults", &issues)
+	}
+	var warnings, errs []*Issue
+	for _, issue := range issues {
+		if issue.Warning {
+			warnings = append(warnings, issue)
+		} else {
+			errs = append(errs, issue)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, warnings, &ConfigError{Issues: errs}
+	}
+	m := &Workspace{Root: filepath.Dir(file), File: file}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, warnings, &ConfigError{Issues: []*Issue{{File: file, Message: err.Error()}}}
+	}
+	if defaults != nil {
+		m.Defaults, _ = defaults.value().(map[string]any)
+	}
+	return m, warnings, nil
+}
+
+// OpenWorkspace 工作区目录, 读取其中的工作区文件, 没有时使用默认配置
+func OpenWorkspace(root string) (*Workspace, []*Issue, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	file := filepath.Join(root, consts.EnergyWorkspaceConfig)
+	if _, err = os.Stat(file); os.IsNotExist(err) {
+		return &Workspace{Root: root}, nil, nil
+	}
+	return LoadWorkspace(file)
+}
+
+// FindWorkspace 从 dir 向上查找工作区文件, 没有时返回 nil
+func FindWorkspace(dir string) (*Workspace, []*Issue, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		file := filepath.Join(dir, consts.EnergyWorkspaceConfig)
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return LoadWorkspace(file)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil, nil
+		}
+		dir = parent
+	}
+}
+
+// inherit 项目配置继承默认配置, value 为已合并系统覆盖配置的项目配置
+func (m *Workspace) inherit(value map[string]any, goos string) map[string]any {
+	if len(m.Defaults) == 0 {
+		return value
+	}
+	// 默认配置可能被多个项目使用, 合并前复制
+	result := copyValue(m.Defaults).(map[string]any)
+	if override, ok := result[goos].(map[string]any); ok {
+		merge(result, override)
+	}
+	for _, key := range append(osKeys, notInherited...) {
+		delete(result, key)
+	}
+	merge(result, value)
+	return result
+}
+
+func copyValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, value := range v {
+			result[key] = copyValue(value)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, value := range v {
+			result[i] = copyValue(value)
+		}
+		return result
+	}
+	return v
+}
+
+// Discover 查找工作区中的项目, 按相对路径排序
+func (m *Workspace) Discover() ([]*WorkspaceProject, error) {
+	projects, err := NewMatcher(m.Projects)
+	if err != nil {
+		return nil, fmt.Errorf("%s: projects: %w", consts.EnergyWorkspaceConfig, err)
+	}
+	excludes, err := NewMatcher(m.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("%s: exclude: %w", consts.EnergyWorkspaceConfig, err)
+	}
+	var result []*WorkspaceProject
+	err = filepath.WalkDir(m.Root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(m.Root, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != "." {
+			if name := d.Name(); strings.HasPrefix(name, ".") || name == "node_modules" || excludes.SkipDir(rel) {
+				return filepath.SkipDir
+			}
+		}
+		if !projects.Empty() && !projects.Match(rel, true) {
+			return nil
+		}
+		config := filepath.Join(file, consts.EnergyProjectConfig)
+		if info, err := os.Stat(config); err != nil || info.IsDir() {
+			return nil
+		}
+		p := &WorkspaceProject{Name: projectName(config), Path: file, Rel: rel}
+		if p.Name == "" {
+			p.Name = filepath.Base(file)
+		}
+		result = append(result, p)
+		return nil
+	})
+	return result, err
+}
+
+// 项目名称, 只读取 name, 配置错误由构建时报告
+func projectName(config string) string {
+	data, err := os.ReadFile(config)
+	if err != nil {
+		return ""
+	}
+	var v struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(data, &v) != nil || strings.Contains(v.Name, "${") {
+		return ""
+	}
+	return v.Name
+}
+
+// SelectProjects 选择项目, 按名称或相对路径匹配, 支持 *, ?, [a-z], 未指定时返回所有项目
+func SelectProjects(projects []*WorkspaceProject, names []string) ([]*WorkspaceProject, error) {
+	if len(names) == 0 {
+		return projects, nil
+	}
+	var result []*WorkspaceProject
+	selected := make(map[*WorkspaceProject]bool)
+	for _, name := range names {
+		name = strings.Trim(filepath.ToSlash(strings.TrimSpace(name)), "/")
+		var found bool
+		for _, p := range projects {
+			if match(name, p.Name) || match(name, p.Rel) {
+				found = true
+				if !selected[p] {
+					selected[p] = true
+					result = append(result, p)
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no project matches %q", name)
+		}
+	}
+	return result, nil
+}
+
+func match(pattern, name string) bool {
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
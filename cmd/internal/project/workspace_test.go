@@ -0,0 +1,73 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWorkspace(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, data string) {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("energy.workspace.json", `{
+  "exclude": ["apps/legacy/"],
+  "defaults": {
+    "name": "ignored",
+    "author": {"name": "team", "email": "team@example.com"},
+    "info": {"companyName": "example", "productVersion": "1.0.0"},
+    "nsis": {"language": "SimpChinese"},
+    "windows": {"info": {"productVersion": "1.0.0.0"}}
+  }
+}`)
+	write("apps/a/energy.json", `{"name": "a", "info": {"productVersion": "2.0.0"}}`)
+	write("apps/b/energy.json", `{}`)
+	write("apps/legacy/energy.json", `{"name": "legacy"}`)
+	write("apps/a/node_modules/x/energy.json", `{"name": "x"}`)
+
+	workspace, warnings, err := FindWorkspace(filepath.Join(root, "apps", "a"))
+	if err != nil || workspace == nil || len(warnings) > 0 {
+		t.Fatal("FindWorkspace", workspace, warnings, err)
+	}
+	projects, err := workspace.Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range projects {
+		names = append(names, p.Name+":"+p.Rel)
+	}
+	if want := []string{"a:apps/a", "b:apps/b"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Discover = %v, want %v", names, want)
+	}
+	if selected, err := SelectProjects(projects, []string{"apps/b"}); err != nil || len(selected) != 1 || selected[0].Name != "b" {
+		t.Fatal("SelectProjects", selected, err)
+	}
+	if _, err = SelectProjects(projects, []string{"c"}); err == nil {
+		t.Fatal("expected no project matches error")
+	}
+
+	value, _, err := loadConfig("energy.json", []byte(`{"name": "a", "info": {"productVersion": "2.0.0"}}`), "windows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value = workspace.inherit(value, "windows")
+	want := map[string]any{
+		"name":   "a",
+		"author": map[string]any{"name": "team", "email": "team@example.com"},
+		"info":   map[string]any{"companyName": "example", "productVersion": "2.0.0"},
+		"nsis":   map[string]any{"language": "SimpChinese"},
+	}
+	if !reflect.DeepEqual(value, want) {
+		t.Fatalf("inherit = %v\nwant %v", value, want)
+	}
+	if workspace.Defaults["name"] != "ignored" {
+		t.Fatal("defaults modified")
+	}
+}
@@ -0,0 +1,74 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package term
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/pterm/pterm"
+	"os"
+)
+
+// 输出模式, 全局参数设置, 用于 CI 等非交互环境
+//  --yes, --non-interactive: 不显示交互式输入, 使用默认选项
+//  --quiet: 只输出错误, 写入标准错误
+//  --output json: 标准输出只输出 json 结果, 其它输出写入标准错误
+var (
+	Yes   bool
+	Quiet bool
+	JSON  bool
+)
+
+// ErrInteractive 需要用户输入, 但输出模式无法显示交互式输入
+var ErrInteractive = &command.Error{
+	Code: command.ExitInteractive,
+	Err:  errors.New("user input required, run with --yes or --non-interactive to use the default options"),
+}
+
+// 原标准输出, json 模式时只写入结果
+var stdout = os.Stdout
+
+// SetMode 设置输出模式
+func SetMode(yes, quiet, jsonOutput bool) {
+	Yes, Quiet, JSON = yes, quiet, jsonOutput
+	if quiet || jsonOutput {
+		pterm.DisableOutput()
+		Logger = Logger.WithLevel(pterm.LogLevelError).WithWriter(os.Stderr)
+	}
+	if jsonOutput {
+		// 命令和子进程的输出写入标准错误
+		os.Stdout = os.Stderr
+	}
+}
+
+// Interactive 是否可以显示交互式输入
+//  --yes: 返回 false, 使用默认选项
+//  --quiet, --output json: 无法显示, 返回 ErrInteractive
+func Interactive() (bool, error) {
+	if Yes {
+		return false, nil
+	}
+	if Quiet || JSON {
+		return false, ErrInteractive
+	}
+	return true, nil
+}
+
+// WriteJSON 结果写入标准输出
+func WriteJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,100 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+)
+
+// SetJSONFields
+//  修改 json 配置文件根对象的字段, 保持原有字段顺序, 新字段追加在末尾
+//  value 为 nil 时删除字段, 文件不存在时创建
+func SetJSONFields(file string, fields map[string]any) error {
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var (
+		keys   []string
+		values = make(map[string]json.RawMessage)
+	)
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(data) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if t, err := dec.Token(); err != nil {
+			return err
+		} else if d, ok := t.(json.Delim); !ok || d != '{' {
+			return errors.New(file + ": json object expected")
+		}
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := t.(string)
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return err
+			}
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
+			}
+			values[key] = raw
+		}
+	}
+	var names []string
+	for key := range fields {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	for _, key := range names {
+		if fields[key] == nil {
+			delete(values, key)
+			continue
+		}
+		raw, err := json.Marshal(fields[key])
+		if err != nil {
+			return err
+		}
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = raw
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	var n int
+	for _, key := range keys {
+		raw, ok := values[key]
+		if !ok {
+			continue
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(raw)
+		n++
+	}
+	buf.WriteByte('}')
+	var out bytes.Buffer
+	if err = json.Indent(&out, buf.Bytes(), "", "\t"); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	return os.WriteFile(file, out.Bytes(), 0644)
+}
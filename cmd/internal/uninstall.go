@@ -0,0 +1,70 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 卸载已安装框架
+
+package internal
+
+import (
+	"errors"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/framework"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/pterm/pterm"
+	"os"
+)
+
+var CmdUninstall = &command.Command{
+	UsageLine: "uninstall [framework] -y [yes]",
+	Short:     "Uninstall an installed energy framework",
+	Long: `
+	Delete the framework directory and remove it from: energy list
+	[framework] Framework name, energy version (v2.3.0) or CEF version (109, 106, 87)
+	-y Uninstall without confirmation
+	.  Execute command
+`,
+}
+
+func init() {
+	CmdUninstall.Run = runUninstall
+}
+
+func runUninstall(c *command.Config) error {
+	if c.Uninstall.Args.Framework == "" {
+		return errors.New("framework not specified, see installed frameworks: energy list")
+	}
+	f, err := framework.Find(c.Uninstall.Args.Framework)
+	if err != nil {
+		return err
+	}
+	// -y, --yes 不确认
+	if interactive, err := term.Interactive(); err != nil {
+		return err
+	} else if interactive {
+		ok, err := pterm.DefaultInteractiveConfirm.Show("Uninstall " + f.Name + ", delete " + f.Path)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+	if f.Exists() {
+		term.Section.Println("Delete", f.Path)
+		if err = os.RemoveAll(f.Path); err != nil {
+			return err
+		}
+	}
+	if err = framework.Remove(f.Name); err != nil {
+		return err
+	}
+	term.Section.Println("Uninstalled", f.Name)
+	return nil
+}
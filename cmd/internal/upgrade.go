@@ -0,0 +1,41 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 升级 energy 命令行
+
+package internal
+
+import (
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/install"
+)
+
+var CmdUpgrade = &command.Command{
+	UsageLine: "upgrade --check -f [force] --mirror [dir or url] --proxy [url] --checksum-only",
+	Short:     "Upgrade the energy cli",
+	Long: `
+	Download the latest energy cli for the current system, verify the sha256 and ed25519 signature and replace the running energy cli
+	the public key is publicKey in ~/.energy/energy.json or the built-in key, the upgrade fails without a public key
+	--check Only compare the current and latest version, do not upgrade
+	-f Upgrade even if the current version is the latest
+	--mirror Upgrade from a local directory or http(s) mirror, containing upgrade.json and the energy cli files
+	--proxy Download proxy, default proxy in energy.json or HTTP_PROXY, HTTPS_PROXY environment
+	--checksum-only Without a public key, verify only the sha256, upgrade.json and the energy cli must be downloaded over https or from a local mirror
+	.  Execute command
+`,
+}
+
+func init() {
+	CmdUpgrade.Run = runUpgrade
+}
+
+func runUpgrade(c *command.Config) error {
+	return install.Upgrade(c)
+}
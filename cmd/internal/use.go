@@ -0,0 +1,70 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 切换使用的框架
+
+package internal
+
+import (
+	"errors"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/env"
+	"github.com/energye/energy/v2/cmd/internal/framework"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/energye/energy/v2/cmd/internal/tools"
+	"path/filepath"
+)
+
+var CmdUse = &command.Command{
+	UsageLine: "use [framework] -p [path]",
+	Short:     "Use an installed energy framework",
+	Long: `
+	Switch the energy framework, see installed frameworks: energy list
+	[framework] Framework name, energy version (v2.3.0), CEF version (109, 106, 87) or latest
+	-p Project path, set frameworkVersion in the energy.json of the project,
+	   default set the global framework and ENERGY_HOME
+	.  Execute command
+`,
+}
+
+func init() {
+	CmdUse.Run = runUse
+}
+
+func runUse(c *command.Config) error {
+	if c.Use.Args.Framework == "" {
+		return errors.New("framework not specified, see installed frameworks: energy list")
+	}
+	f, err := framework.Find(c.Use.Args.Framework)
+	if err != nil {
+		return err
+	}
+	if !f.Exists() {
+		return errors.New("framework directory does not exist: " + f.Path)
+	}
+	if c.Use.Path != "" {
+		// 项目使用
+		if err = tools.SetJSONFields(filepath.Join(c.Use.Path, consts.EnergyProjectConfig), map[string]any{"frameworkVersion": f.Name, "frameworkPath": ""}); err != nil {
+			return err
+		}
+		term.Section.Println("Project", c.Use.Path, "uses framework", f.Name)
+		return nil
+	}
+	// 全局使用
+	if err = framework.SetCurrent(f.Name); err != nil {
+		return err
+	}
+	if f.IsHost() {
+		env.SetEnergyHomeEnv(f.Path)
+	}
+	term.Section.Println("Using framework", f.Name, f.Path)
+	return nil
+}
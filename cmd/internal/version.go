@@ -16,8 +16,8 @@ import (
 	"fmt"
 	"github.com/energye/energy/v2/cmd/internal/command"
 	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/term"
 	"github.com/energye/energy/v2/cmd/internal/tools"
-	"os"
 	"sort"
 	"strings"
 )
@@ -35,17 +35,26 @@ func init() {
 	CmdVersion.Run = runVersion
 }
 
+// 版本列表, --output json 输出
+type versionResult struct {
+	Latest   string         `json:"latest"`
+	Versions []*versionInfo `json:"versions"` // 从新到旧
+}
+
+type versionInfo struct {
+	Version string         `json:"version"`
+	Info    map[string]any `json:"info,omitempty"` // -a 时输出
+}
+
 func runVersion(c *command.Config) error {
 	downloadJSON, err := tools.HttpRequestGET(consts.DownloadVersionURL)
 	if err != nil {
-		fmt.Fprint(os.Stderr, err.Error()+"\n")
-		os.Exit(1)
+		return command.WithCode(command.ExitNetwork, err)
 	}
 	var edv map[string]interface{}
 	downloadJSON = bytes.TrimPrefix(downloadJSON, []byte("\xef\xbb\xbf"))
 	if err := json.Unmarshal(downloadJSON, &edv); err != nil {
-		fmt.Fprint(os.Stderr, err.Error()+"\n")
-		os.Exit(1)
+		return command.WithCode(command.ExitNetwork, err)
 	}
 	if versionList, ok := edv["versionList"].(map[string]interface{}); ok {
 		var keys []string
@@ -53,27 +62,32 @@ func runVersion(c *command.Config) error {
 			keys = append(keys, k)
 		}
 		sort.Strings(keys)
-		println("Latest:", edv["latest"].(string))
-		println("Version list")
+		result := &versionResult{Latest: tools.ToString(edv["latest"]), Versions: []*versionInfo{}}
+		c.Result = result
+		term.Println("Latest:", result.Latest)
+		term.Println("Version list")
 		for i := len(keys) - 1; i >= 0; i-- {
 			var version = keys[i]
 			var ver = versionList[version].(map[string]interface{})
+			info := &versionInfo{Version: version}
+			result.Versions = append(result.Versions, info)
 			if c.Version.All {
+				info.Info = make(map[string]any)
 				wrt := &bytes.Buffer{}
 				wrt.WriteString("  ")
 				wrt.WriteString(version)
 				wrt.WriteString("\r\n")
 				for key, value := range ver {
-					key = strings.ToUpper(key)
-					if key == "MODULES" {
+					if strings.ToUpper(key) == "MODULES" {
 						continue
 					}
+					info.Info[key] = value
 					wrt.WriteString(fmt.Sprintf("\t%s: %s", strings.ToUpper(key), value))
 					wrt.WriteString("\r\n")
 				}
-				println(wrt.String())
+				term.Println(wrt.String())
 			} else {
-				println("  ", version)
+				term.Println("  ", version)
 			}
 		}
 	}
@@ -0,0 +1,67 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package workspace
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// 多个子进程共用的输出, 按行写入, 避免不同项目的输出混在一行
+type output struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// prefixWriter 每行添加前缀, 不完整的行缓存到换行或 Flush
+type prefixWriter struct {
+	out    *output
+	prefix string
+	buf    []byte
+}
+
+func (m *output) writer(prefix string) *prefixWriter {
+	return &prefixWriter{out: m, prefix: prefix}
+}
+
+func (m *prefixWriter) Write(p []byte) (int, error) {
+	m.buf = append(m.buf, p...)
+	for {
+		i := bytes.IndexByte(m.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := m.writeLine(m.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		m.buf = m.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush 输出缓存的不完整的行
+func (m *prefixWriter) Flush() error {
+	if len(m.buf) == 0 {
+		return nil
+	}
+	line := append(m.buf, '\n')
+	m.buf = nil
+	return m.writeLine(line)
+}
+
+func (m *prefixWriter) writeLine(line []byte) error {
+	m.out.mu.Lock()
+	defer m.out.mu.Unlock()
+	line = bytes.TrimRight(line, "\r\n")
+	_, err := m.out.w.Write(append(append([]byte(m.prefix+" "), line...), '\n'))
+	return err
+}
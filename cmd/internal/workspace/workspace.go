@@ -0,0 +1,246 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 工作区多项目执行 energy build/package --dir [root] --projects [names]
+//  查找工作区中的项目, 每个项目在子进程中执行 energy [command] -p [项目目录] --yes .
+//  项目之间不共享状态, 子进程并行执行, 数量 --jobs, 默认 CPU 数量
+//  子进程的标准输出和标准错误添加 [项目名称] 前缀写入标准错误, 完成后输出汇总
+//  子进程的命令结果写入环境变量 ENERGY_RESULT_FILE 指定的临时文件, 格式同 --output json
+//  退出码: 第一个失败项目的退出码
+
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/command"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"github.com/energye/energy/v2/cmd/internal/term"
+	"github.com/pterm/pterm"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result 项目执行结果
+type Result struct {
+	Name     string          `json:"name"`
+	Path     string          `json:"path"`
+	Success  bool            `json:"success"`
+	Code     int             `json:"code"` // 退出码
+	Error    string          `json:"error,omitempty"`
+	Duration string          `json:"duration"`
+	Result   json.RawMessage `json:"result,omitempty"` // 子进程的命令结果
+}
+
+// Enabled 是否在工作区执行, 指定 --dir 或 --projects
+func Enabled(c *command.Config, projects string) bool {
+	return c.Dir != "" || projects != ""
+}
+
+// Run 在工作区选择的项目中执行命令
+func Run(c *command.Config, name, projects string, jobs int) error {
+	workspace, err := open(c)
+	if err != nil {
+		return command.WithCode(command.ExitConfig, err)
+	}
+	all, err := workspace.Discover()
+	if err != nil {
+		return command.WithCode(command.ExitConfig, err)
+	}
+	if len(all) == 0 {
+		return command.WithCode(command.ExitConfig, fmt.Errorf("no %s found in %s", consts.EnergyProjectConfig, workspace.Root))
+	}
+	var names []string
+	if projects != "" {
+		names = strings.Split(projects, ",")
+	}
+	selected, err := project.SelectProjects(all, names)
+	if err != nil {
+		return command.WithCode(command.ExitUsage, err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	term.Logger.Info(fmt.Sprintf("Workspace %s, %s %d projects, %d parallel", workspace.Root, name, len(selected), jobs))
+	args := forwardArgs(os.Args[1:])
+	results := make([]*Result, len(selected))
+	out := &output{w: os.Stderr}
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for i, p := range selected {
+		wg.Add(1)
+		go func(i int, p *project.WorkspaceProject) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = runProject(exe, args, p, out)
+		}(i, p)
+	}
+	wg.Wait()
+	c.Result = results
+	printSummary(name, results)
+	var failed []string
+	var code int
+	for _, r := range results {
+		if !r.Success {
+			if code == 0 {
+				code = r.Code
+			}
+			failed = append(failed, r.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return &command.Error{Code: code, Err: fmt.Errorf("%s failed: %s", name, strings.Join(failed, ", "))}
+	}
+	return nil
+}
+
+// 工作区, --dir 未指定时从当前目录向上查找工作区文件, 没有时为当前目录
+func open(c *command.Config) (*project.Workspace, error) {
+	var (
+		workspace *project.Workspace
+		warnings  []*project.Issue
+		err       error
+	)
+	if c.Dir != "" {
+		workspace, warnings, err = project.OpenWorkspace(c.Dir)
+	} else if workspace, warnings, err = project.FindWorkspace(c.Wd); workspace == nil && err == nil {
+		workspace, warnings, err = project.OpenWorkspace(c.Wd)
+	}
+	for _, warning := range warnings {
+		term.Logger.Warn(warning.Error())
+	}
+	return workspace, err
+}
+
+// 工作区参数, 子进程不使用
+var (
+	workspaceFlags = []string{"--dir", "--projects", "--jobs", "-p", "--path", "--output"}
+	modeFlags      = []string{"-y", "--yes", "--non-interactive", "-q", "--quiet"}
+)
+
+// forwardArgs 子进程参数, 去掉工作区, 项目目录和输出模式参数, 和结尾的 .
+func forwardArgs(args []string) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "." && i == len(args)-1 {
+			continue
+		}
+		if hasFlag(modeFlags, arg) {
+			continue
+		}
+		if flag, _, ok := strings.Cut(arg, "="); hasFlag(workspaceFlags, flag) {
+			if !ok {
+				// 参数值为下一个参数
+				i++
+			}
+			continue
+		}
+		if strings.HasPrefix(arg, "-p") && !strings.HasPrefix(arg, "--") {
+			// -p[path]
+			continue
+		}
+		result = append(result, arg)
+	}
+	return result
+}
+
+func hasFlag(flags []string, arg string) bool {
+	for _, flag := range flags {
+		if arg == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// 在子进程中执行项目, 输出添加前缀, 读取结果文件
+func runProject(exe string, args []string, p *project.WorkspaceProject, out *output) *Result {
+	prefix := pterm.FgCyan.Sprint("[" + p.Name + "]")
+	result := &Result{Name: p.Name, Path: p.Path}
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start).Round(time.Millisecond).String()
+	}()
+	term.Logger.Info(prefix + " start")
+	var (
+		r struct {
+			Success bool            `json:"success"`
+			Code    int             `json:"code"`
+			Error   string          `json:"error"`
+			Result  json.RawMessage `json:"result"`
+		}
+		data []byte
+	)
+	resultFile, err := os.CreateTemp("", "energy-result-*.json")
+	if err == nil {
+		resultFile.Close()
+		defer os.Remove(resultFile.Name())
+		stdout, stderr := out.writer(prefix), out.writer(prefix)
+		cmd := exec.Command(exe, append(args, "-p", p.Path, "--yes", ".")...)
+		cmd.Dir = p.Path
+		cmd.Env = append(os.Environ(), consts.EnergyResultFileKey+"="+resultFile.Name())
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		err = cmd.Run()
+		stdout.Flush()
+		stderr.Flush()
+		data, _ = os.ReadFile(resultFile.Name())
+	}
+	if e := json.Unmarshal(data, &r); e != nil {
+		// 子进程未输出结果, 例如启动失败
+		if err == nil {
+			err = e
+		}
+		result.Code = command.ExitFailure
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.Code = exitErr.ExitCode()
+		}
+		result.Error = err.Error()
+	} else {
+		result.Success, result.Code, result.Error, result.Result = r.Success, r.Code, r.Error, r.Result
+	}
+	if result.Success {
+		term.Logger.Info(prefix + " done")
+	} else {
+		term.Logger.Error(prefix + " failed: " + result.Error)
+	}
+	return result
+}
+
+// 输出汇总
+func printSummary(name string, results []*Result) {
+	tableData := pterm.TableData{
+		{"Project", "Path", "Status", "Duration"},
+	}
+	for _, r := range results {
+		status := pterm.FgGreen.Sprint("ok")
+		if !r.Success {
+			status = pterm.FgRed.Sprintf("failed (%d)", r.Code)
+		}
+		tableData = append(tableData, []string{r.Name, r.Path, status, r.Duration})
+	}
+	term.Section.Println(name + " summary")
+	if err := pterm.DefaultTable.WithHasHeader().WithHeaderRowSeparator("-").WithBoxed().WithData(tableData).Render(); err != nil {
+		term.Logger.Error(err.Error())
+	}
+}
@@ -0,0 +1,52 @@
+package workspace
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/energye/energy/v2/cmd/internal/consts"
+	"github.com/energye/energy/v2/cmd/internal/project"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForwardArgs(t *testing.T) {
+	args := []string{"build", "--dir", "apps", "--projects=a,b", "--jobs", "2", "-p", "x", "-u", "--output", "json", "-q", "--target", "linux/amd64", "."}
+	want := []string{"build", "-u", "--target", "linux/amd64"}
+	if got := forwardArgs(args); !reflect.DeepEqual(got, want) {
+		t.Fatalf("forwardArgs = %v, want %v", got, want)
+	}
+}
+
+// 子进程, 输出进度日志并写入结果文件
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("ENERGY_WORKSPACE_HELPER") != "1" {
+		return
+	}
+	fmt.Println("building demo")
+	fmt.Fprintln(os.Stderr, "warning demo")
+	os.WriteFile(os.Getenv(consts.EnergyResultFileKey), []byte(`{"command":"build","success":true,"code":0,"result":{"output":"demo"}}`), 0644)
+	os.Exit(0)
+}
+
+func TestRunProject(t *testing.T) {
+	t.Setenv("ENERGY_WORKSPACE_HELPER", "1")
+	var buf bytes.Buffer
+	p := &project.WorkspaceProject{Name: "demo", Path: t.TempDir()}
+	result := runProject(os.Args[0], []string{"-test.run=^TestHelperProcess$", "--"}, p, &output{w: &buf})
+	if !result.Success || string(result.Result) != `{"output":"demo"}` {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	prefixed := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if strings.Contains(line, "[demo]") {
+			prefixed[line[strings.Index(line, " ")+1:]] = true
+		}
+	}
+	for _, line := range []string{"building demo", "warning demo"} {
+		if !prefixed[line] {
+			t.Errorf("missing prefixed output %q in %q", line, buf.String())
+		}
+	}
+}
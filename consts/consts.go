@@ -30,9 +30,10 @@ var (
 )
 
 const (
-	Empty           = ""
-	ENERGY_HOME_KEY = "ENERGY_HOME"
-	MemoryNetwork   = "unix"
+	Empty                 = ""
+	ENERGY_HOME_KEY       = "ENERGY_HOME"
+	ENERGY_DEV_SERVER_KEY = "ENERGY_DEV_SERVER" // 前端开发服务地址, energy dev 设置, 只在开发构建 -tags="energy_dev" 中使用
+	MemoryNetwork         = "unix"
 )
 
 func init() {
@@ -150,7 +151,7 @@ const (
 	LANGUAGE_hi     LANGUAGE = "hi"
 	LANGUAGE_hr     LANGUAGE = "hr"
 	LANGUAGE_hu     LANGUAGE = "hu"
-	LANGUAGE_id     LANGUAGE = "channelId"
+	LANGUAGE_id     LANGUAGE = "id"
 	LANGUAGE_it     LANGUAGE = "it"
 	LANGUAGE_ja     LANGUAGE = "ja"
 	LANGUAGE_kn     LANGUAGE = "kn"
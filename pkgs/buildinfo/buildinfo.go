@@ -0,0 +1,101 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 应用构建信息
+//	energy build 编译时通过 -ldflags "-X" 注入
+//		github.com/energye/energy/v2/pkgs/buildinfo.Version    energy.json info.productVersion
+//		github.com/energye/energy/v2/pkgs/buildinfo.Commit     git 提交, 有未提交修改时追加 -dirty
+//		github.com/energye/energy/v2/pkgs/buildinfo.BuildTime  RFC3339, SOURCE_DATE_EPOCH 或 git 提交时间
+//	未注入时使用 go 编译器记录的模块版本和 vcs 信息
+
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// 编译时注入
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+// Info 构建信息
+type Info struct {
+	Version   string    // 应用版本
+	Commit    string    // git 提交
+	BuildTime time.Time // 构建时间, 未知时为零值
+	GoVersion string    // go 版本
+}
+
+// Get 返回构建信息
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, GoVersion: runtime.Version()}
+	buildTime := BuildTime
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		var modified bool
+		var revision, vcsTime string
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.time":
+				vcsTime = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if info.Commit == "" && revision != "" {
+			info.Commit = revision
+			if modified {
+				info.Commit += "-dirty"
+			}
+		}
+		if buildTime == "" {
+			buildTime = vcsTime
+		}
+	}
+	if buildTime != "" {
+		if t, err := time.Parse(time.RFC3339, buildTime); err == nil {
+			info.BuildTime = t
+		}
+	}
+	return info
+}
+
+// String 版本 (提交) 构建时间
+func (m Info) String() string {
+	s := m.Version
+	if s == "" {
+		s = "unknown"
+	}
+	if m.Commit != "" {
+		commit, dirty := m.Commit, strings.HasSuffix(m.Commit, "-dirty")
+		commit = strings.TrimSuffix(commit, "-dirty")
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if dirty {
+			commit += "-dirty"
+		}
+		s += " (" + commit + ")"
+	}
+	if !m.BuildTime.IsZero() {
+		s += " " + m.BuildTime.UTC().Format(time.RFC3339)
+	}
+	return s
+}
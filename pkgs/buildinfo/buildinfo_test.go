@@ -0,0 +1,19 @@
+package buildinfo
+
+import (
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	Version, Commit, BuildTime = "1.2.0", "0123456789abcdef0123-dirty", "2023-11-14T22:13:20Z"
+	defer func() {
+		Version, Commit, BuildTime = "", "", ""
+	}()
+	info := Get()
+	if info.Version != "1.2.0" || info.BuildTime.Unix() != 1700000000 {
+		t.Fatal(info)
+	}
+	if s := info.String(); s != "1.2.0 (0123456789ab-dirty) 2023-11-14T22:13:20Z" {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,415 @@
+package decimal
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode specifies how a result is rounded to the precision of a Context.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to nearest, ties away from zero. Same as Decimal.Round and Decimal.Div.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to nearest, ties to the even neighbour (banker's rounding). Same as Decimal.RoundBank.
+	RoundHalfEven
+	// RoundHalfDown rounds to nearest, ties towards zero.
+	RoundHalfDown
+	// RoundUp rounds away from zero. Same as Decimal.RoundUp.
+	RoundUp
+	// RoundDown rounds towards zero (truncation). Same as Decimal.RoundDown.
+	RoundDown
+	// RoundCeiling rounds towards +infinity. Same as Decimal.RoundCeil.
+	RoundCeiling
+	// RoundFloor rounds towards -infinity. Same as Decimal.RoundFloor.
+	RoundFloor
+)
+
+// Condition is a set of exceptional conditions raised by a Context operation.
+type Condition uint32
+
+const (
+	// Inexact is raised when the result had to be rounded and lost non-zero digits.
+	Inexact Condition = 1 << iota
+	// Overflow is raised when the integer part of the result has more than Context.MaxIntegerDigits digits.
+	Overflow
+	// DivisionByZero is raised when dividing by zero. It is always an error.
+	DivisionByZero
+	// InvalidOperation is raised for operations without a result, e.g. Sqrt(-1) or Ln(0). It is always an error.
+	InvalidOperation
+)
+
+var conditionNames = []struct {
+	c    Condition
+	name string
+}{
+	{Inexact, "inexact"},
+	{Overflow, "overflow"},
+	{DivisionByZero, "division by zero"},
+	{InvalidOperation, "invalid operation"},
+}
+
+func (c Condition) String() string {
+	var names []string
+	for _, n := range conditionNames {
+		if c&n.c != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// ContextError is returned by Context operations when a trapped condition is raised.
+type ContextError struct {
+	Op        string    // operation name, e.g. "sqrt"
+	Condition Condition // raised conditions that are trapped
+}
+
+func (e *ContextError) Error() string {
+	return fmt.Sprintf("decimal: %s: %s", e.Op, e.Condition.String())
+}
+
+// Context holds the numerical environment of an operation: result precision,
+// rounding mode and which conditions are reported as errors.
+//
+// A Context is a plain value and is passed explicitly to each operation, so
+// goroutines can use different precisions without touching package globals
+// such as DivisionPrecision.
+//
+// Example:
+//
+//     ctx := decimal.Context{Precision: 2, Rounding: decimal.RoundHalfEven, Traps: decimal.Overflow}
+//     d, err := ctx.Div(decimal.New(10, 0), decimal.New(3, 0))
+//     d.String() // output: "3.33"
+//
+//     ctx.Traps |= decimal.Inexact
+//     _, err = ctx.Div(decimal.New(10, 0), decimal.New(3, 0))
+//     err.Error() // output: "decimal: div: inexact"
+//
+type Context struct {
+	// Precision is the number of digits after the decimal point of a result.
+	// Negative precision rounds the integer part to a multiple of 10^(-Precision).
+	Precision int32
+	// Rounding is the rounding mode applied when a result has more digits than Precision.
+	Rounding RoundingMode
+	// Traps is the set of conditions returned as errors.
+	// DivisionByZero and InvalidOperation are always returned as errors.
+	Traps Condition
+	// MaxIntegerDigits is the maximum number of digits of the integer part of a result,
+	// exceeding it raises Overflow. 0 means no limit.
+	MaxIntegerDigits int
+}
+
+// DefaultContext returns the Context matching the behaviour of Decimal.Div:
+// DivisionPrecision digits after the decimal point, rounding half away from zero, no traps.
+func DefaultContext() Context {
+	return Context{Precision: int32(DivisionPrecision), Rounding: RoundHalfUp}
+}
+
+// WithPrecision returns a copy of the context with the given precision.
+func (c Context) WithPrecision(precision int32) Context {
+	c.Precision = precision
+	return c
+}
+
+// Round rounds d to the precision of the context using its rounding mode.
+func (c Context) Round(d Decimal) (Decimal, error) {
+	return c.finish("round", d, 0)
+}
+
+// Add returns d1 + d2 rounded to the context.
+func (c Context) Add(d1, d2 Decimal) (Decimal, error) {
+	return c.finish("add", d1.Add(d2), 0)
+}
+
+// Sub returns d1 - d2 rounded to the context.
+func (c Context) Sub(d1, d2 Decimal) (Decimal, error) {
+	return c.finish("sub", d1.Sub(d2), 0)
+}
+
+// Mul returns d1 * d2 rounded to the context.
+func (c Context) Mul(d1, d2 Decimal) (Decimal, error) {
+	return c.finish("mul", d1.Mul(d2), 0)
+}
+
+// Div returns d1 / d2 rounded to the context.
+// Unlike Decimal.Div it does not panic when d2 is zero, it returns DivisionByZero.
+func (c Context) Div(d1, d2 Decimal) (Decimal, error) {
+	d2.ensureInitialized()
+	if d2.value.Sign() == 0 {
+		return Decimal{}, &ContextError{Op: "div", Condition: DivisionByZero}
+	}
+	d1.ensureInitialized()
+	q, r := d1.QuoRem(d2, c.Precision+1)
+	if r.value.Sign() != 0 {
+		q = addSticky(q, d1.value.Sign()*d2.value.Sign() < 0)
+	}
+	return c.finish("div", q, 0)
+}
+
+// Sqrt returns the square root of d rounded to the context.
+func (c Context) Sqrt(d Decimal) (Decimal, error) {
+	d.ensureInitialized()
+	if d.value.Sign() < 0 {
+		return Decimal{}, &ContextError{Op: "sqrt", Condition: InvalidOperation}
+	}
+	return c.finish("sqrt", sqrtTruncated(d, c.Precision+1), 0)
+}
+
+// Ln returns the natural logarithm of d rounded to the context.
+func (c Context) Ln(d Decimal) (Decimal, error) {
+	d.ensureInitialized()
+	if d.value.Sign() <= 0 {
+		return Decimal{}, &ContextError{Op: "ln", Condition: InvalidOperation}
+	}
+	if d.Equal(one) {
+		return c.finish("ln", Decimal{value: new(big.Int), exp: 0}, 0)
+	}
+	return c.finish("ln", ln(d, c.workPrecision(d)), Inexact)
+}
+
+// Log10 returns the base 10 logarithm of d rounded to the context.
+func (c Context) Log10(d Decimal) (Decimal, error) {
+	d.ensureInitialized()
+	if d.value.Sign() <= 0 {
+		return Decimal{}, &ContextError{Op: "log10", Condition: InvalidOperation}
+	}
+	// exact for powers of ten
+	if n, ok := powerOfTen(d); ok {
+		return c.finish("log10", New(n, 0), 0)
+	}
+	wp := c.workPrecision(d)
+	return c.finish("log10", ln(d, wp).DivRound(ln10(wp), wp), Inexact)
+}
+
+// Exp returns e to the power of d rounded to the context.
+func (c Context) Exp(d Decimal) (Decimal, error) {
+	d.ensureInitialized()
+	if d.value.Sign() == 0 {
+		return c.finish("exp", New(1, 0), 0)
+	}
+	if c.MaxIntegerDigits > 0 && c.Traps&Overflow != 0 {
+		// e^x has about x/ln(10) integer digits, fail before the series runs for a very long time
+		limit := New(int64(c.MaxIntegerDigits)*23026, -4)
+		if d.Cmp(limit) > 0 {
+			return Decimal{}, &ContextError{Op: "exp", Condition: Overflow}
+		}
+	}
+	res, err := d.ExpTaylor(c.Precision + guardDigits)
+	if err != nil {
+		return Decimal{}, &ContextError{Op: "exp", Condition: InvalidOperation}
+	}
+	return c.finish("exp", res, Inexact)
+}
+
+// Sqrt returns the square root of d with precision digits after the decimal point,
+// rounded half away from zero.
+//
+// Example:
+//
+//     NewFromInt(2).Sqrt(10).String() // output: "1.4142135624"
+//
+func (d Decimal) Sqrt(precision int32) (Decimal, error) {
+	return Context{Precision: precision}.Sqrt(d)
+}
+
+// Ln returns the natural logarithm of d with precision digits after the decimal point,
+// rounded half away from zero.
+//
+// Example:
+//
+//     NewFromInt(10).Ln(10).String() // output: "2.302585093"
+//
+func (d Decimal) Ln(precision int32) (Decimal, error) {
+	return Context{Precision: precision}.Ln(d)
+}
+
+// Log10 returns the base 10 logarithm of d with precision digits after the decimal point,
+// rounded half away from zero.
+//
+// Example:
+//
+//     NewFromInt(2).Log10(10).String() // output: "0.3010299957"
+//
+func (d Decimal) Log10(precision int32) (Decimal, error) {
+	return Context{Precision: precision}.Log10(d)
+}
+
+// guardDigits is the number of extra digits used by iterative functions.
+const guardDigits = 10
+
+var one = New(1, 0)
+
+// finish rounds d to the context and raises the conditions
+// cond: conditions already known for d, e.g. Inexact for transcendental results
+func (c Context) finish(op string, d Decimal, cond Condition) (Decimal, error) {
+	d.ensureInitialized()
+	rounded := c.roundMode(d)
+	if !rounded.Equal(d) {
+		cond |= Inexact
+	}
+	if c.MaxIntegerDigits > 0 && integerDigits(rounded) > c.MaxIntegerDigits {
+		cond |= Overflow
+	}
+	return c.raise(op, rounded, cond)
+}
+
+func (c Context) raise(op string, d Decimal, cond Condition) (Decimal, error) {
+	if trapped := cond & (c.Traps | DivisionByZero | InvalidOperation); trapped != 0 {
+		return Decimal{}, &ContextError{Op: op, Condition: trapped}
+	}
+	return d, nil
+}
+
+// roundMode rounds d to c.Precision places using c.Rounding
+func (c Context) roundMode(d Decimal) Decimal {
+	places := c.Precision
+	if d.exp >= -places {
+		return d
+	}
+	switch c.Rounding {
+	case RoundHalfEven:
+		return d.RoundBank(places)
+	case RoundHalfDown:
+		down := d.RoundDown(places)
+		if d.Sub(down).Abs().Cmp(New(5, -places-1)) <= 0 {
+			return down
+		}
+		return d.RoundUp(places)
+	case RoundUp:
+		return d.RoundUp(places)
+	case RoundDown:
+		return d.RoundDown(places)
+	case RoundCeiling:
+		return d.RoundCeil(places)
+	case RoundFloor:
+		return d.RoundFloor(places)
+	default:
+		return d.Round(places)
+	}
+}
+
+// workPrecision is the precision used for intermediate results of logarithms,
+// large arguments need more digits because ln(d) has more integer digits
+func (c Context) workPrecision(d Decimal) int32 {
+	p := c.Precision
+	if p < 0 {
+		p = 0
+	}
+	return p + guardDigits + int32(len(fmt.Sprint(abs(d.exp+int32(d.NumDigits())))))
+}
+
+// addSticky appends a non-zero digit to a truncated value, so that rounding it
+// can tell "exactly half" apart from "more than half"
+func addSticky(q Decimal, negative bool) Decimal {
+	v := new(big.Int).Mul(q.value, tenInt)
+	if negative {
+		v.Sub(v, oneInt)
+	} else {
+		v.Add(v, oneInt)
+	}
+	return Decimal{value: v, exp: q.exp - 1}
+}
+
+// sqrtTruncated returns sqrt(d) truncated to places digits after the decimal point,
+// with a sticky digit appended when the result is inexact
+func sqrtTruncated(d Decimal, places int32) Decimal {
+	// sqrt(v * 10^e) * 10^places = sqrt(v * 10^(e + 2*places))
+	shift := int64(d.exp) + 2*int64(places)
+	n := new(big.Int).Set(d.value)
+	exact := true
+	if shift >= 0 {
+		n.Mul(n, new(big.Int).Exp(tenInt, big.NewInt(shift), nil))
+	} else {
+		var rem big.Int
+		n.QuoRem(n, new(big.Int).Exp(tenInt, big.NewInt(-shift), nil), &rem)
+		exact = rem.Sign() == 0
+	}
+	r := new(big.Int).Sqrt(n)
+	if exact {
+		exact = new(big.Int).Mul(r, r).Cmp(n) == 0
+	}
+	res := Decimal{value: r, exp: -places}
+	if !exact {
+		res = addSticky(res, false)
+	}
+	return res
+}
+
+// ln returns the natural logarithm of d > 0 with about wp correct digits after the decimal point.
+//
+// d = m * 10^k with 1 <= m < 10, ln(d) = ln(m) + k*ln(10).
+// m is brought close to 1 by repeated square roots, ln(m) = 2^j * ln(m^(1/2^j)),
+// and the reduced logarithm is computed with ln(x) = 2*atanh((x-1)/(x+1)).
+func ln(d Decimal, wp int32) Decimal {
+	k := int64(d.exp) + int64(d.NumDigits()) - 1
+	m := Decimal{value: new(big.Int).Set(d.value), exp: int32(int64(d.exp) - k)}
+	res := lnReduced(m, wp)
+	if k != 0 {
+		extra := int32(len(fmt.Sprint(k)))
+		res = res.Add(ln10(wp + extra).Mul(New(k, 0))).Round(wp)
+	}
+	return res
+}
+
+// ln10 returns ln(10) with wp digits after the decimal point
+func ln10(wp int32) Decimal {
+	return lnReduced(New(10, 0), wp)
+}
+
+// lnReduced computes ln(x) for x > 0 of moderate size
+func lnReduced(x Decimal, wp int32) Decimal {
+	// every square root doubles the error of the final result, keep a few more digits
+	p := wp + guardDigits
+	bound := New(1, -1)
+	var j int
+	for x.Sub(one).Abs().Cmp(bound) > 0 {
+		x = sqrtTruncated(x, p).Round(p)
+		j++
+	}
+	// atanh series: 2 * (z + z^3/3 + z^5/5 + ...)
+	z := x.Sub(one).DivRound(x.Add(one), p)
+	z2 := z.Mul(z).Round(p)
+	sum := z
+	term := z
+	epsilon := New(1, -p)
+	for n := int64(3); ; n += 2 {
+		term = term.Mul(z2).Round(p)
+		t := term.DivRound(New(n, 0), p)
+		if t.Abs().Cmp(epsilon) < 0 {
+			break
+		}
+		sum = sum.Add(t)
+	}
+	res := sum.Mul(New(2, 0))
+	if j > 0 {
+		res = res.Mul(NewFromBigInt(new(big.Int).Lsh(oneInt, uint(j)), 0))
+	}
+	return res.Round(wp)
+}
+
+// powerOfTen reports whether d is 10^n and returns n
+func powerOfTen(d Decimal) (int64, bool) {
+	v := new(big.Int).Set(d.value)
+	n := int64(d.exp)
+	var rem big.Int
+	for v.Cmp(oneInt) > 0 {
+		var q big.Int
+		q.QuoRem(v, tenInt, &rem)
+		if rem.Sign() != 0 {
+			return 0, false
+		}
+		v = &q
+		n++
+	}
+	return n, v.Cmp(oneInt) == 0
+}
+
+// integerDigits returns the number of digits of the integer part of d
+func integerDigits(d Decimal) int {
+	if n := d.NumDigits() + int(d.exp); n > 0 && d.value.Sign() != 0 {
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package decimal
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestContextRoundingModes(t *testing.T) {
+	tests := []struct {
+		mode     RoundingMode
+		d1, d2   int64
+		expected string
+	}{
+		{RoundHalfUp, 5, 2, "3"},
+		{RoundHalfUp, -5, 2, "-3"},
+		{RoundHalfEven, 5, 2, "2"},
+		{RoundHalfEven, 7, 2, "4"},
+		{RoundHalfDown, 5, 2, "2"},
+		{RoundHalfDown, -51, 20, "-3"},
+		{RoundUp, 1, 3, "1"},
+		{RoundDown, -5, 3, "-1"},
+		{RoundCeiling, -5, 3, "-1"},
+		{RoundFloor, -1, 3, "-1"},
+	}
+	for _, test := range tests {
+		ctx := Context{Precision: 0, Rounding: test.mode}
+		got, err := ctx.Div(New(test.d1, 0), New(test.d2, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != test.expected {
+			t.Errorf("mode %d: %d/%d expected %s, got %s", test.mode, test.d1, test.d2, test.expected, got.String())
+		}
+	}
+}
+
+func TestContextTraps(t *testing.T) {
+	ctx := Context{Precision: 2}
+	if d, err := ctx.Div(New(10, 0), New(3, 0)); err != nil || d.String() != "3.33" {
+		t.Errorf("expected 3.33, got %s %v", d, err)
+	}
+	ctx.Traps = Inexact
+	if _, err := ctx.Div(New(10, 0), New(3, 0)); err == nil || err.(*ContextError).Condition != Inexact {
+		t.Errorf("expected inexact error, got %v", err)
+	}
+	if d, err := ctx.Div(New(10, 0), New(4, 0)); err != nil || d.String() != "2.5" {
+		t.Errorf("expected 2.5, got %s %v", d, err)
+	}
+	if _, err := ctx.Div(New(1, 0), Zero); err == nil || err.(*ContextError).Condition != DivisionByZero {
+		t.Errorf("expected division by zero error, got %v", err)
+	}
+	ctx = Context{Precision: 2, Traps: Overflow, MaxIntegerDigits: 3}
+	if _, err := ctx.Mul(New(100, 0), New(10, 0)); err == nil || err.(*ContextError).Condition != Overflow {
+		t.Errorf("expected overflow error, got %v", err)
+	}
+	if _, err := ctx.Exp(New(100, 0)); err == nil {
+		t.Errorf("expected overflow error")
+	}
+	if _, err := ctx.Sqrt(New(-1, 0)); err == nil || err.(*ContextError).Condition != InvalidOperation {
+		t.Errorf("expected invalid operation error, got %v", err)
+	}
+	if _, err := ctx.Ln(Zero); err == nil {
+		t.Errorf("expected invalid operation error")
+	}
+}
+
+func TestSqrtLnLog10(t *testing.T) {
+	tests := []struct {
+		value string
+		sqrt  string
+		ln    string
+		log10 string
+	}{
+		{"2", "1.41421356237309504880168872421", "0.693147180559945309417232121458", "0.301029995663981195213738894724"},
+		{"10", "3.162277660168379331998893544433", "2.302585092994045684017991454684", "1"},
+		{"0.5", "0.707106781186547524400844362105", "-0.693147180559945309417232121458", "-0.301029995663981195213738894724"},
+		{"7", "2.645751311064590590501615753639", "1.945910149055313305105352743443", "0.845098040014256830712216258593"},
+		{"1e-20", "0.0000000001", "-46.051701859880913680359829093687", "-20"},
+		{"1", "1", "0", "0"},
+	}
+	for _, test := range tests {
+		d := RequireFromString(test.value)
+		if got, err := d.Sqrt(30); err != nil || got.String() != test.sqrt {
+			t.Errorf("sqrt(%s) expected %s, got %s %v", test.value, test.sqrt, got, err)
+		}
+		if got, err := d.Ln(30); err != nil || got.String() != test.ln {
+			t.Errorf("ln(%s) expected %s, got %s %v", test.value, test.ln, got, err)
+		}
+		if got, err := d.Log10(30); err != nil || got.String() != test.log10 {
+			t.Errorf("log10(%s) expected %s, got %s %v", test.value, test.log10, got, err)
+		}
+	}
+	if got, err := (Context{Precision: 20}).Exp(New(1, 0)); err != nil || got.String() != "2.71828182845904523536" {
+		t.Errorf("exp(1) expected 2.71828182845904523536, got %s %v", got, err)
+	}
+}
+
+func TestContextConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(precision int32) {
+			defer wg.Done()
+			ctx := Context{Precision: precision}
+			d, err := ctx.Div(New(1, 0), New(3, 0))
+			if err != nil || int32(len(d.String())-2) != precision {
+				t.Errorf("precision %d: got %s %v", precision, d, err)
+			}
+			if _, err := ctx.Exp(New(int64(precision), 0)); err != nil {
+				t.Error(err)
+			}
+		}(int32(i + 2))
+	}
+	wg.Wait()
+}
@@ -25,6 +25,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // DivisionPrecision is the number of decimal places in the result when it
@@ -68,7 +69,9 @@ var fiveInt = big.NewInt(5)
 var tenInt = big.NewInt(10)
 var twentyInt = big.NewInt(20)
 
+// factorials caches n! at index n-1, shared by all goroutines and guarded by factorialsLock.
 var factorials = []Decimal{New(1, 0)}
+var factorialsLock sync.Mutex
 
 // Decimal represents a fixed-point decimal. It is immutable.
 // number = value * 10 ^ exp
@@ -792,16 +795,7 @@ func (d Decimal) ExpTaylor(precision int32) (Decimal, error) {
 		i++
 
 		// Calculate next factorial number or retrieve cached value
-		if len(factorials) >= int(i) && !factorials[i-1].IsZero() {
-			factorial = factorials[i-1]
-		} else {
-			// To avoid any race conditions, firstly the zero value is appended to a slice to create
-			// a spot for newly calculated factorial. After that, the zero value is replaced by calculated
-			// factorial using the index notation.
-			factorial = factorials[i-2].Mul(New(i, 0))
-			factorials = append(factorials, Zero)
-			factorials[i-1] = factorial
-		}
+		factorial = factorialOf(i)
 	}
 
 	if d.Sign() < 0 {
@@ -812,6 +806,16 @@ func (d Decimal) ExpTaylor(precision int32) (Decimal, error) {
 	return result, nil
 }
 
+// factorialOf returns i! (i >= 1) from the cache, extending the cache when needed.
+func factorialOf(i int64) Decimal {
+	factorialsLock.Lock()
+	defer factorialsLock.Unlock()
+	for n := int64(len(factorials)) + 1; n <= i; n++ {
+		factorials = append(factorials, factorials[n-2].Mul(New(n, 0)))
+	}
+	return factorials[i-1]
+}
+
 // NumDigits returns the number of digits of the decimal coefficient (d.Value)
 // Note: Current implementation is extremely slow for large decimals and/or decimals with large fractional part
 func (d Decimal) NumDigits() int {
@@ -0,0 +1,438 @@
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/consts"
+	"strings"
+	"unicode/utf8"
+)
+
+// Pattern names accepted by Format in place of a pattern, expanded with the
+// patterns of the locale.
+const (
+	PatternDecimal    = "decimal"    // e.g. en-US "#,##0.###"
+	PatternCurrency   = "currency"   // e.g. en-US "¤#,##0.00", de "#,##0.00 ¤"
+	PatternAccounting = "accounting" // currency pattern, negatives in parentheses: (1,234.50)
+)
+
+// NumberSymbols holds the symbols and default patterns used to format and
+// parse numbers of a locale.
+type NumberSymbols struct {
+	Decimal         string // decimal separator
+	Group           string // grouping separator
+	Minus           string // minus sign
+	Currency        string // currency symbol, replaces ¤ in patterns
+	DecimalPattern  string // pattern of PatternDecimal
+	CurrencyPattern string // pattern of PatternCurrency and PatternAccounting
+}
+
+const (
+	nbsp  = "\u00a0" // no-break space
+	nnbsp = "\u202f" // narrow no-break space
+)
+
+func symbols(dec, group, currency, currencyPattern string) NumberSymbols {
+	return NumberSymbols{Decimal: dec, Group: group, Minus: "-", Currency: currency, DecimalPattern: "#,##0.###", CurrencyPattern: currencyPattern}
+}
+
+func indianSymbols(currency string) NumberSymbols {
+	return NumberSymbols{Decimal: ".", Group: ",", Minus: "-", Currency: currency, DecimalPattern: "#,##,##0.###", CurrencyPattern: "¤#,##,##0.00"}
+}
+
+// localeSymbols number symbols of the languages supported by cef/i18n
+var localeSymbols = map[consts.LANGUAGE]NumberSymbols{
+	consts.LANGUAGE_zh_CN:  symbols(".", ",", "¥", "¤#,##0.00"),
+	consts.LANGUAGE_zh_TW:  symbols(".", ",", "$", "¤#,##0.00"),
+	consts.LANGUAGE_am:     symbols(".", ",", "ብር", "¤#,##0.00"),
+	consts.LANGUAGE_ar:     symbols(".", ",", "ج.م.", "#,##0.00 ¤"),
+	consts.LANGUAGE_bg:     symbols(",", nbsp, "лв.", "#,##0.00 ¤"),
+	consts.LANGUAGE_bn:     indianSymbols("৳"),
+	consts.LANGUAGE_ca:     symbols(",", ".", "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_cs:     symbols(",", nbsp, "Kč", "#,##0.00 ¤"),
+	consts.LANGUAGE_da:     symbols(",", ".", "kr.", "#,##0.00 ¤"),
+	consts.LANGUAGE_de:     symbols(",", ".", "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_el:     symbols(",", ".", "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_en_GB:  symbols(".", ",", "£", "¤#,##0.00"),
+	consts.LANGUAGE_en_US:  symbols(".", ",", "$", "¤#,##0.00"),
+	consts.LANGUAGE_es:     symbols(",", ".", "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_es_419: symbols(".", ",", "$", "¤#,##0.00"),
+	consts.LANGUAGE_et:     symbols(",", nbsp, "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_fa:     symbols("٫", "٬", "ریال", "¤ #,##0"),
+	consts.LANGUAGE_fi:     symbols(",", nbsp, "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_fil:    symbols(".", ",", "₱", "¤#,##0.00"),
+	consts.LANGUAGE_fr:     symbols(",", nnbsp, "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_gu:     indianSymbols("₹"),
+	consts.LANGUAGE_he:     symbols(".", ",", "₪", "#,##0.00 ¤"),
+	consts.LANGUAGE_hi:     indianSymbols("₹"),
+	consts.LANGUAGE_hr:     symbols(",", ".", "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_hu:     symbols(",", nbsp, "Ft", "#,##0.00 ¤"),
+	consts.LANGUAGE_id:     symbols(",", ".", "Rp", "¤#,##0.00"),
+	consts.LANGUAGE_it:     symbols(",", ".", "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_ja:     symbols(".", ",", "￥", "¤#,##0"),
+	consts.LANGUAGE_kn:     indianSymbols("₹"),
+	consts.LANGUAGE_ko:     symbols(".", ",", "₩", "¤#,##0"),
+	consts.LANGUAGE_lt:     symbols(",", nbsp, "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_lv:     symbols(",", nbsp, "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_ml:     indianSymbols("₹"),
+	consts.LANGUAGE_mr:     indianSymbols("₹"),
+	consts.LANGUAGE_ms:     symbols(".", ",", "RM", "¤#,##0.00"),
+	consts.LANGUAGE_nb:     symbols(",", nbsp, "kr", "¤ #,##0.00"),
+	consts.LANGUAGE_nl:     symbols(",", ".", "€", "¤ #,##0.00"),
+	consts.LANGUAGE_pl:     symbols(",", nbsp, "zł", "#,##0.00 ¤"),
+	consts.LANGUAGE_pt_BR:  symbols(",", ".", "R$", "¤ #,##0.00"),
+	consts.LANGUAGE_pt_PT:  symbols(",", nbsp, "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_ro:     symbols(",", ".", "RON", "#,##0.00 ¤"),
+	consts.LANGUAGE_ru:     symbols(",", nbsp, "₽", "#,##0.00 ¤"),
+	consts.LANGUAGE_sk:     symbols(",", nbsp, "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_sl:     symbols(",", ".", "€", "#,##0.00 ¤"),
+	consts.LANGUAGE_sr:     symbols(",", ".", "RSD", "#,##0 ¤"),
+	consts.LANGUAGE_sv:     symbols(",", nbsp, "kr", "#,##0.00 ¤"),
+	consts.LANGUAGE_sw:     symbols(".", ",", "TSh", "¤ #,##0.00"),
+	consts.LANGUAGE_ta:     indianSymbols("₹"),
+	consts.LANGUAGE_te:     indianSymbols("₹"),
+	consts.LANGUAGE_th:     symbols(".", ",", "฿", "¤#,##0.00"),
+	consts.LANGUAGE_tr:     symbols(",", ".", "₺", "¤#,##0.00"),
+	consts.LANGUAGE_uk:     symbols(",", nbsp, "₴", "#,##0.00 ¤"),
+	consts.LANGUAGE_vi:     symbols(",", ".", "₫", "#,##0 ¤"),
+}
+
+// LocaleSymbols returns the number symbols of locale.
+// Unknown regional variants fall back to the language (de-AT => de), anything else to en-US.
+func LocaleSymbols(locale consts.LANGUAGE) NumberSymbols {
+	if s, ok := localeSymbols[locale]; ok {
+		return s
+	}
+	if i := strings.IndexAny(string(locale), "-_"); i > 0 {
+		if s, ok := localeSymbols[locale[:i]]; ok {
+			return s
+		}
+	}
+	return localeSymbols[consts.LANGUAGE_en_US]
+}
+
+// Format returns d formatted for locale using pattern.
+//
+// The pattern uses the locale independent ICU subset:
+//   0 digit, # optional digit, , grouping separator, . decimal separator,
+//   ¤ currency symbol, ; separates the optional negative sub pattern,
+//   any other text is copied as is.
+// Pattern may also be PatternDecimal, PatternCurrency or PatternAccounting, or
+// empty for PatternDecimal. Digits beyond the pattern are rounded half away from zero.
+//
+// Example:
+//
+//     d := decimal.RequireFromString("-1234.5")
+//     d.Format(consts.LANGUAGE_en_US, decimal.PatternAccounting) // output: "($1,234.50)"
+//     d.Format(consts.LANGUAGE_de, decimal.PatternCurrency)      // output: "-1.234,50 €"
+//     d.Format(consts.LANGUAGE_hi, "#,##,##0.00")                // output: "-1,234.50"
+//
+func (d Decimal) Format(locale consts.LANGUAGE, pattern string) (string, error) {
+	return d.FormatSymbols(LocaleSymbols(locale), pattern)
+}
+
+// FormatSymbols is Format with explicit number symbols, e.g. a locale with another currency symbol.
+func (d Decimal) FormatSymbols(sym NumberSymbols, pattern string) (string, error) {
+	p, err := parseNumberPattern(expandPattern(sym, pattern))
+	if err != nil {
+		return "", err
+	}
+	d.ensureInitialized()
+	r := d
+	if -r.exp > int32(p.maxFrac) {
+		r = r.Round(int32(p.maxFrac))
+	}
+	negative := r.Sign() < 0
+	digits := r.Abs().StringFixed(int32(p.maxFrac))
+	intPart, fracPart := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		intPart, fracPart = digits[:i], digits[i+1:]
+	}
+	for len(fracPart) > p.minFrac && strings.HasSuffix(fracPart, "0") {
+		fracPart = fracPart[:len(fracPart)-1]
+	}
+	intPart = strings.TrimLeft(intPart, "0")
+	for len(intPart) < p.minInt {
+		intPart = "0" + intPart
+	}
+	var buf strings.Builder
+	prefix, suffix := p.prefix, p.suffix
+	if negative {
+		if p.hasNegative {
+			prefix, suffix = p.negPrefix, p.negSuffix
+		} else {
+			prefix = "-" + prefix
+		}
+	}
+	buf.WriteString(p.affix(prefix, sym))
+	buf.WriteString(groupDigits(intPart, p.grouping, p.secondaryGrouping, sym.Group))
+	if fracPart != "" {
+		buf.WriteString(sym.Decimal)
+		buf.WriteString(fracPart)
+	}
+	buf.WriteString(p.affix(suffix, sym))
+	return buf.String(), nil
+}
+
+// Parse parses a number typed or displayed in locale, the inverse of Format.
+//
+// Grouping separators, the currency symbol, a leading or trailing minus sign and
+// accounting parentheses are accepted.
+//
+// Example:
+//
+//     decimal.Parse(consts.LANGUAGE_en_US, "($1,234.50)") // -1234.5
+//     decimal.Parse(consts.LANGUAGE_de, "1.234,50 €")     // 1234.5
+//
+func Parse(locale consts.LANGUAGE, s string) (Decimal, error) {
+	return ParseSymbols(LocaleSymbols(locale), s)
+}
+
+// ParseSymbols is Parse with explicit number symbols.
+func ParseSymbols(sym NumberSymbols, s string) (Decimal, error) {
+	original := s
+	fail := func(reason string) (Decimal, error) {
+		return Decimal{}, fmt.Errorf("can't convert %q to decimal: %s", original, reason)
+	}
+	negative := false
+	s = trimSpaces(s)
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = trimSpaces(s[1 : len(s)-1])
+	}
+	// sign and currency symbol may come in any order around the number: -$1, $-1, 1 €-
+	for i := 0; i < 2; i++ {
+		if sym.Currency != "" {
+			if strings.HasPrefix(s, sym.Currency) {
+				s = trimSpaces(s[len(sym.Currency):])
+			} else if strings.HasSuffix(s, sym.Currency) {
+				s = trimSpaces(s[:len(s)-len(sym.Currency)])
+			}
+		}
+		for _, minus := range []string{sym.Minus, "-", "−"} {
+			if minus == "" {
+				continue
+			}
+			if strings.HasPrefix(s, minus) {
+				s, negative = trimSpaces(s[len(minus):]), !negative
+				break
+			} else if strings.HasSuffix(s, minus) {
+				s, negative = trimSpaces(s[:len(s)-len(minus)]), !negative
+				break
+			}
+		}
+		s = strings.TrimPrefix(s, "+")
+	}
+	if s == "" {
+		return fail("no digits")
+	}
+	var (
+		buf       strings.Builder
+		seenDot   bool
+		digits    int
+		groups    = []int{0} // digits count of each integer group
+		group     = sym.Group
+		groupLike = isSpaceGroup(group)
+	)
+	if negative {
+		buf.WriteByte('-')
+	}
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		switch {
+		case r >= '0' && r <= '9':
+			buf.WriteRune(r)
+			digits++
+			if !seenDot {
+				groups[len(groups)-1]++
+			}
+		case strings.HasPrefix(s, sym.Decimal):
+			if seenDot {
+				return fail("more than one decimal separator")
+			}
+			seenDot = true
+			buf.WriteByte('.')
+			size = len(sym.Decimal)
+		case group != "" && strings.HasPrefix(s, group), groupLike && isSpace(r):
+			if seenDot {
+				return fail("grouping separator after decimal separator")
+			}
+			if group != "" && strings.HasPrefix(s, group) {
+				size = len(group)
+			}
+			groups = append(groups, 0)
+		default:
+			return fail(fmt.Sprintf("unexpected %q", r))
+		}
+		s = s[size:]
+	}
+	if digits == 0 {
+		return fail("no digits")
+	}
+	if len(groups) > 1 && !validGroups(sym, groups) {
+		return fail("misplaced grouping separator")
+	}
+	return NewFromString(buf.String())
+}
+
+// validGroups checks the integer digit groups against the grouping of the locale,
+// so that a typo such as "1.5" in de is not read as 15.
+// Groups of the primary size only are accepted too, e.g. 1,234,567 in hi.
+func validGroups(sym NumberSymbols, groups []int) bool {
+	primary, secondary := 3, 3
+	if p, err := parseNumberPattern(sym.DecimalPattern); err == nil && p.grouping > 0 {
+		primary, secondary = p.grouping, p.grouping
+		if p.secondaryGrouping > 0 {
+			secondary = p.secondaryGrouping
+		}
+	}
+	return matchGroups(groups, primary, secondary) || matchGroups(groups, primary, primary)
+}
+
+func matchGroups(groups []int, primary, secondary int) bool {
+	last := len(groups) - 1
+	if groups[0] < 1 || groups[0] > secondary || groups[last] != primary {
+		return false
+	}
+	for _, n := range groups[1:last] {
+		if n != secondary {
+			return false
+		}
+	}
+	return true
+}
+
+// numberPattern compiled Format pattern
+type numberPattern struct {
+	prefix, suffix       string
+	negPrefix, negSuffix string
+	hasNegative          bool
+	minInt               int
+	minFrac, maxFrac     int
+	grouping             int // primary grouping size, 0 no grouping
+	secondaryGrouping    int // secondary grouping size, e.g. 2 for #,##,##0
+}
+
+func expandPattern(sym NumberSymbols, pattern string) string {
+	switch pattern {
+	case "", PatternDecimal:
+		return sym.DecimalPattern
+	case PatternCurrency:
+		return sym.CurrencyPattern
+	case PatternAccounting:
+		return sym.CurrencyPattern + ";(" + sym.CurrencyPattern + ")"
+	}
+	return pattern
+}
+
+func parseNumberPattern(pattern string) (*numberPattern, error) {
+	positive, negative := pattern, ""
+	hasNegative := false
+	if i := strings.IndexByte(pattern, ';'); i >= 0 {
+		positive, negative, hasNegative = pattern[:i], pattern[i+1:], true
+	}
+	p := &numberPattern{hasNegative: hasNegative}
+	var number string
+	var err error
+	if p.prefix, number, p.suffix, err = splitPattern(positive); err != nil {
+		return nil, err
+	}
+	if hasNegative {
+		// only the affixes of the negative sub pattern are used
+		if p.negPrefix, _, p.negSuffix, err = splitPattern(negative); err != nil {
+			return nil, err
+		}
+	}
+	intPattern, fracPattern := number, ""
+	if i := strings.IndexByte(number, '.'); i >= 0 {
+		intPattern, fracPattern = number[:i], number[i+1:]
+	}
+	if strings.ContainsAny(fracPattern, ".,") {
+		return nil, errors.New("decimal: invalid pattern " + pattern)
+	}
+	for _, c := range fracPattern {
+		if c == '0' {
+			if p.maxFrac != p.minFrac {
+				return nil, errors.New("decimal: invalid pattern " + pattern)
+			}
+			p.minFrac++
+		}
+		p.maxFrac++
+	}
+	groups := strings.Split(intPattern, ",")
+	p.minInt = strings.Count(intPattern, "0")
+	if len(groups) > 1 {
+		p.grouping = len(groups[len(groups)-1])
+		if len(groups) > 2 {
+			p.secondaryGrouping = len(groups[len(groups)-2])
+		}
+		if p.grouping == 0 || (len(groups) > 2 && p.secondaryGrouping == 0) {
+			return nil, errors.New("decimal: invalid pattern " + pattern)
+		}
+	}
+	return p, nil
+}
+
+// splitPattern splits a sub pattern into prefix, number and suffix
+func splitPattern(pattern string) (prefix, number, suffix string, err error) {
+	start := strings.IndexAny(pattern, "#0,.")
+	if start < 0 {
+		return "", "", "", errors.New("decimal: invalid pattern " + pattern)
+	}
+	end := start
+	for end < len(pattern) && strings.IndexByte("#0,.", pattern[end]) >= 0 {
+		end++
+	}
+	return pattern[:start], pattern[start:end], pattern[end:], nil
+}
+
+// affix replaces the pattern symbols of a prefix or suffix
+func (m *numberPattern) affix(s string, sym NumberSymbols) string {
+	if s == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, "¤", sym.Currency)
+	if sym.Minus != "-" {
+		s = strings.ReplaceAll(s, "-", sym.Minus)
+	}
+	return s
+}
+
+// groupDigits inserts sep into the integer digits
+func groupDigits(digits string, grouping, secondary int, sep string) string {
+	if grouping <= 0 || len(digits) <= grouping {
+		return digits
+	}
+	if secondary <= 0 {
+		secondary = grouping
+	}
+	var parts []string
+	parts = append(parts, digits[len(digits)-grouping:])
+	digits = digits[:len(digits)-grouping]
+	for len(digits) > secondary {
+		parts = append(parts, digits[len(digits)-secondary:])
+		digits = digits[:len(digits)-secondary]
+	}
+	parts = append(parts, digits)
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, sep)
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\u00a0' || r == '\u202f'
+}
+
+// isSpaceGroup reports whether the grouping separator is a kind of space,
+// users type a plain space for it
+func isSpaceGroup(group string) bool {
+	r, _ := utf8.DecodeRuneInString(group)
+	return group != "" && isSpace(r)
+}
+
+func trimSpaces(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool {
+		return isSpace(r) || r == '\t' || r == '\u200f' || r == '\u200e'
+	})
+}
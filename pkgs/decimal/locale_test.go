@@ -0,0 +1,98 @@
+package decimal
+
+import (
+	"github.com/energye/energy/v2/consts"
+	"testing"
+)
+
+func TestFormatLocale(t *testing.T) {
+	tests := []struct {
+		value    string
+		locale   consts.LANGUAGE
+		pattern  string
+		expected string
+	}{
+		{"1234.5", consts.LANGUAGE_en_US, "", "1,234.5"},
+		{"1234567.891", consts.LANGUAGE_en_US, "#,##0.00", "1,234,567.89"},
+		{"-1234.5", consts.LANGUAGE_en_US, PatternAccounting, "($1,234.50)"},
+		{"-1234.5", consts.LANGUAGE_en_US, PatternCurrency, "-$1,234.50"},
+		{"-1234.5", consts.LANGUAGE_de, PatternCurrency, "-1.234,50 €"},
+		{"1234567.5", consts.LANGUAGE_fr, "", "1 234 567,5"},
+		{"12345678.5", consts.LANGUAGE_hi, "", "1,23,45,678.5"},
+		{"1234.5", consts.LANGUAGE_ja, PatternCurrency, "￥1,235"},
+		{"0.5", consts.LANGUAGE_en_US, "0.00", "0.50"},
+		{"-0.001", consts.LANGUAGE_en_US, "0.00", "0.00"},
+		{"123456789012345678901234567890.123", "de-CH", "#,##0.###", "123.456.789.012.345.678.901.234.567.890,123"},
+		{"42", consts.LANGUAGE_pt_BR, "#,##0.00 'BRL'", "42,00 'BRL'"},
+	}
+	for _, test := range tests {
+		got, err := RequireFromString(test.value).Format(test.locale, test.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.expected {
+			t.Errorf("format %s %s %q: expected %q, got %q", test.value, test.locale, test.pattern, test.expected, got)
+		}
+	}
+	if _, err := New(1, 0).Format(consts.LANGUAGE_en_US, "#,##0.0#0"); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		locale   consts.LANGUAGE
+		input    string
+		expected string
+	}{
+		{consts.LANGUAGE_en_US, "($1,234.50)", "-1234.5"},
+		{consts.LANGUAGE_en_US, "-$1,234.50", "-1234.5"},
+		{consts.LANGUAGE_en_US, "$-1,234.50", "-1234.5"},
+		{consts.LANGUAGE_en_US, "1234", "1234"},
+		{consts.LANGUAGE_de, "1.234,50 €", "1234.5"},
+		{consts.LANGUAGE_de, "-1.234,5", "-1234.5"},
+		{consts.LANGUAGE_fr, "1 234 567,5", "1234567.5"},
+		{consts.LANGUAGE_fr, "1 234,5 €", "1234.5"},
+		{consts.LANGUAGE_sv, "−12,5 kr", "-12.5"},
+		{consts.LANGUAGE_hi, "₹1,23,45,678.50", "12345678.5"},
+	}
+	for _, test := range tests {
+		got, err := Parse(test.locale, test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != test.expected {
+			t.Errorf("parse %s %q: expected %s, got %s", test.locale, test.input, test.expected, got)
+		}
+	}
+	for _, input := range []string{"", "$", "1,2.3", "1.5", "12.34,5", "1.234,5,6", "12a", "1.2.3"} {
+		if _, err := Parse(consts.LANGUAGE_de, input); err == nil {
+			t.Errorf("parse %q: expected error", input)
+		}
+	}
+}
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	values := []string{"0", "1", "-1", "1234567.891", "-0.5", "98765432109876543210.0123456789"}
+	for locale := range localeSymbols {
+		for _, pattern := range []string{"#,##0.##########", PatternAccounting, "0.0000000000"} {
+			for _, value := range values {
+				d := RequireFromString(value)
+				if pattern == PatternAccounting {
+					d = d.Round(0)
+				}
+				s, err := d.Format(locale, pattern)
+				if err != nil {
+					t.Fatal(err)
+				}
+				back, err := Parse(locale, s)
+				if err != nil {
+					t.Fatalf("%s %q: %v", locale, s, err)
+				}
+				if !back.Equal(d) {
+					t.Errorf("%s %s %q: formatted %q, parsed %s", locale, value, pattern, s, back)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// JSON Schema validation of JSON values
+// The keywords are implemented by pkgs/json/schema, a subset of draft 2020-12:
+//   type, enum, const, required, properties, additionalProperties,
+//   items, prefixItems, minItems, maxItems, minLength, maxLength,
+//   minimum, maximum, exclusiveMinimum, exclusiveMaximum, pattern,
+//   $ref ("#" and "#/$defs/name"), $defs
+
+package json
+
+import (
+	"errors"
+	"github.com/energye/energy/v2/pkgs/json/schema"
+	"reflect"
+)
+
+// Schema
+//  Compiled JSON Schema
+type Schema struct {
+	*schema.Schema
+}
+
+// SchemaError
+//  A single validation failure
+type SchemaError = schema.Error
+
+// ValidationError
+//  All validation failures of a value
+type ValidationError = schema.ValidationError
+
+// NewSchema
+//  Compile JSON Schema
+//  value:
+//    []byte("{...}")
+//    string("{...}")
+//    JSONObject
+//    map[string]any
+func NewSchema(value any) (*Schema, error) {
+	if v, ok := value.(JSONObject); ok {
+		data, ok := v.JsonData().ConvertToData().(map[string]any)
+		if !ok {
+			return nil, errors.New("json schema: schema must be an object")
+		}
+		value = data
+	}
+	s, err := schema.New(value)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{Schema: s}, nil
+}
+
+// MustSchema
+//  Compile JSON Schema, panic if it is invalid
+func MustSchema(value any) *Schema {
+	s, err := NewSchema(value)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Validate
+//  Validate value, returns *ValidationError if value does not match the schema
+//  value: nil is treated as null
+func (m *Schema) Validate(value JSON) error {
+	if m == nil || m.Schema == nil {
+		return nil
+	}
+	return m.Schema.Validate(schemaData(value))
+}
+
+// schemaData returns the decoded value
+func schemaData(value JSON) any {
+	if value == nil || reflect.ValueOf(value).IsNil() {
+		return nil
+	}
+	if value.IsObject() || value.IsArray() {
+		return value.JsonData().ConvertToData()
+	}
+	return value.Data()
+}
@@ -0,0 +1,583 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// Package schema JSON Schema validation of decoded JSON values
+// (map[string]any, []any, string, float64, json.Number, bool, nil),
+// used by pkgs/json and the energy command line, without other dependencies.
+// A subset of draft 2020-12:
+//   type, enum, const, required, properties, additionalProperties,
+//   items, prefixItems, minItems, maxItems, minLength, maxLength,
+//   minimum, maximum, exclusiveMinimum, exclusiveMaximum, pattern,
+//   $ref ("#" and "#/$defs/name"), $defs
+// Errors report the JSON Pointer of the invalid value, used to find its position in the source
+
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema
+//  Compiled JSON Schema
+type Schema struct {
+	Types                []string           // type, string or array of string
+	Enum                 []any              // enum
+	Const                any                // const
+	HasConst             bool               // const keyword present
+	Required             []string           // required object keys
+	Properties           map[string]*Schema // properties
+	AdditionalProperties *Schema            // additionalProperties, schema form
+	DenyAdditional       bool               // additionalProperties: false
+	Items                *Schema            // items
+	PrefixItems          []*Schema          // prefixItems, positional items
+	MinItems             *int               // minItems
+	MaxItems             *int               // maxItems
+	MinLength            *int               // minLength, counted in characters
+	MaxLength            *int               // maxLength, counted in characters
+	Minimum              *float64           // minimum
+	Maximum              *float64           // maximum
+	ExclusiveMinimum     *float64           // exclusiveMinimum
+	ExclusiveMaximum     *float64           // exclusiveMaximum
+	Pattern              *regexp.Regexp     // pattern
+	Ref                  string             // $ref, "#" or "#/$defs/name"
+	Defs                 map[string]*Schema // $defs, root schema only
+	root                 *Schema
+}
+
+// Error
+//  A single validation failure
+type Error struct {
+	Path    string `json:"path"`    // path of the invalid value, $ is root. e.g. $[0].name
+	Pointer string `json:"pointer"` // JSON Pointer of the invalid value, "" is root. e.g. /0/name
+	Keyword string `json:"keyword"` // schema keyword that failed
+	Message string `json:"message"` // message
+}
+
+// ValidationError
+//  All validation failures of a value
+type ValidationError struct {
+	Errors []Error `json:"errors"`
+}
+
+func (m Error) Error() string {
+	return m.Path + ": " + m.Message
+}
+
+func (m *ValidationError) Error() string {
+	if len(m.Errors) == 0 {
+		return "json schema: validation failed"
+	}
+	var msg = make([]string, len(m.Errors))
+	for i, e := range m.Errors {
+		msg[i] = e.Error()
+	}
+	return strings.Join(msg, "; ")
+}
+
+// New
+//  Compile JSON Schema
+//  value:
+//    []byte("{...}")
+//    string("{...}")
+//    map[string]any
+func New(value any) (*Schema, error) {
+	var data map[string]any
+	switch value.(type) {
+	case []byte:
+		if err := json.Unmarshal(value.([]byte), &data); err != nil {
+			return nil, err
+		}
+	case string:
+		if err := json.Unmarshal([]byte(value.(string)), &data); err != nil {
+			return nil, err
+		}
+	case map[string]any:
+		data = value.(map[string]any)
+	}
+	if data == nil {
+		return nil, errors.New("json schema: schema must be an object")
+	}
+	m, err := compileSchema(data, "#", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.checkRefs(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Must
+//  Compile JSON Schema, panic if it is invalid
+func Must(value any) *Schema {
+	s, err := New(value)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// compileSchema root is nil for the root schema
+func compileSchema(data map[string]any, path string, root *Schema) (*Schema, error) {
+	m := &Schema{root: root}
+	if root == nil {
+		m.root = m
+	}
+	var err error
+	for key, value := range data {
+		kp := path + "/" + key
+		switch key {
+		case "type":
+			switch value.(type) {
+			case string:
+				m.Types = []string{value.(string)}
+			case []any:
+				for _, t := range value.([]any) {
+					if s, ok := t.(string); ok {
+						m.Types = append(m.Types, s)
+					} else {
+						return nil, fmt.Errorf("json schema: %s must be a string or an array of strings", kp)
+					}
+				}
+			default:
+				return nil, fmt.Errorf("json schema: %s must be a string or an array of strings", kp)
+			}
+			for _, t := range m.Types {
+				switch t {
+				case "null", "boolean", "object", "array", "number", "integer", "string":
+				default:
+					return nil, fmt.Errorf("json schema: %s unknown type %q", kp, t)
+				}
+			}
+		case "enum":
+			if v, ok := value.([]any); ok {
+				m.Enum = v
+			} else {
+				return nil, fmt.Errorf("json schema: %s must be an array", kp)
+			}
+		case "const":
+			m.Const = value
+			m.HasConst = true
+		case "required":
+			if v, ok := value.([]any); ok {
+				for _, r := range v {
+					if s, ok := r.(string); ok {
+						m.Required = append(m.Required, s)
+					} else {
+						return nil, fmt.Errorf("json schema: %s must be an array of strings", kp)
+					}
+				}
+			} else {
+				return nil, fmt.Errorf("json schema: %s must be an array of strings", kp)
+			}
+		case "properties":
+			if v, ok := value.(map[string]any); ok {
+				m.Properties = make(map[string]*Schema, len(v))
+				for name, prop := range v {
+					if p, ok := prop.(map[string]any); ok {
+						if m.Properties[name], err = compileSchema(p, kp+"/"+name, m.root); err != nil {
+							return nil, err
+						}
+					} else {
+						return nil, fmt.Errorf("json schema: %s/%s must be an object", kp, name)
+					}
+				}
+			} else {
+				return nil, fmt.Errorf("json schema: %s must be an object", kp)
+			}
+		case "additionalProperties":
+			switch value.(type) {
+			case bool:
+				m.DenyAdditional = !value.(bool)
+			case map[string]any:
+				if m.AdditionalProperties, err = compileSchema(value.(map[string]any), kp, m.root); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("json schema: %s must be a boolean or an object", kp)
+			}
+		case "items":
+			if v, ok := value.(map[string]any); ok {
+				if m.Items, err = compileSchema(v, kp, m.root); err != nil {
+					return nil, err
+				}
+			} else {
+				return nil, fmt.Errorf("json schema: %s must be an object", kp)
+			}
+		case "prefixItems":
+			if v, ok := value.([]any); ok {
+				for i, item := range v {
+					if p, ok := item.(map[string]any); ok {
+						var s *Schema
+						if s, err = compileSchema(p, kp+"/"+strconv.Itoa(i), m.root); err != nil {
+							return nil, err
+						}
+						m.PrefixItems = append(m.PrefixItems, s)
+					} else {
+						return nil, fmt.Errorf("json schema: %s/%d must be an object", kp, i)
+					}
+				}
+			} else {
+				return nil, fmt.Errorf("json schema: %s must be an array", kp)
+			}
+		case "minItems", "maxItems", "minLength", "maxLength":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 || v != math.Trunc(v) {
+				return nil, fmt.Errorf("json schema: %s must be a non-negative integer", kp)
+			}
+			n := int(v)
+			switch key {
+			case "minItems":
+				m.MinItems = &n
+			case "maxItems":
+				m.MaxItems = &n
+			case "minLength":
+				m.MinLength = &n
+			case "maxLength":
+				m.MaxLength = &n
+			}
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			v, ok := toFloat64(value)
+			if !ok {
+				return nil, fmt.Errorf("json schema: %s must be a number", kp)
+			}
+			switch key {
+			case "minimum":
+				m.Minimum = &v
+			case "maximum":
+				m.Maximum = &v
+			case "exclusiveMinimum":
+				m.ExclusiveMinimum = &v
+			case "exclusiveMaximum":
+				m.ExclusiveMaximum = &v
+			}
+		case "$ref":
+			if v, ok := value.(string); ok {
+				m.Ref = v
+			} else {
+				return nil, fmt.Errorf("json schema: %s must be a string", kp)
+			}
+		case "$defs":
+			if root != nil {
+				return nil, fmt.Errorf("json schema: %s only allowed in the root schema", kp)
+			}
+			if v, ok := value.(map[string]any); ok {
+				m.Defs = make(map[string]*Schema, len(v))
+				for name, def := range v {
+					if p, ok := def.(map[string]any); ok {
+						if m.Defs[name], err = compileSchema(p, kp+"/"+name, m); err != nil {
+							return nil, err
+						}
+					} else {
+						return nil, fmt.Errorf("json schema: %s/%s must be an object", kp, name)
+					}
+				}
+			} else {
+				return nil, fmt.Errorf("json schema: %s must be an object", kp)
+			}
+		case "pattern":
+			if v, ok := value.(string); ok {
+				if m.Pattern, err = regexp.Compile(v); err != nil {
+					return nil, fmt.Errorf("json schema: %s %s", kp, err.Error())
+				}
+			} else {
+				return nil, fmt.Errorf("json schema: %s must be a string", kp)
+			}
+		}
+		// other keywords ($schema, $id, title, description, default ...) are ignored
+	}
+	return m, nil
+}
+
+// Resolve
+//  Returns the schema referenced by $ref, nil if there is no $ref
+func (m *Schema) Resolve() *Schema {
+	switch {
+	case m.Ref == "#":
+		return m.root
+	case strings.HasPrefix(m.Ref, "#/$defs/"):
+		return m.root.Defs[strings.TrimPrefix(m.Ref, "#/$defs/")]
+	}
+	return nil
+}
+
+// checkRefs every $ref must be resolved
+func (m *Schema) checkRefs() error {
+	if m.Ref != "" && m.Resolve() == nil {
+		return fmt.Errorf("json schema: unresolved $ref %q", m.Ref)
+	}
+	var schemas []*Schema
+	for _, s := range m.Properties {
+		schemas = append(schemas, s)
+	}
+	for _, s := range m.Defs {
+		schemas = append(schemas, s)
+	}
+	schemas = append(schemas, m.PrefixItems...)
+	schemas = append(schemas, m.AdditionalProperties, m.Items)
+	for _, s := range schemas {
+		if s != nil {
+			if err := s.checkRefs(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate
+//  Validate a decoded JSON value, returns *ValidationError if value does not match the schema
+//  value: map[string]any, []any, string, numbers, json.Number, bool or nil
+func (m *Schema) Validate(value any) error {
+	if m == nil {
+		return nil
+	}
+	var errs []Error
+	m.validate(value, "$", "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (m *Schema) validate(value any, path, pointer string, errs *[]Error) {
+	// $ref applies together with the other keywords
+	if ref := m.Resolve(); ref != nil && ref != m {
+		ref.validate(value, path, pointer, errs)
+	}
+	addErr := func(keyword, format string, args ...any) {
+		*errs = append(*errs, Error{Path: path, Pointer: pointer, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+	typ := schemaType(value)
+	if len(m.Types) > 0 {
+		var match bool
+		for _, t := range m.Types {
+			if t == typ || (t == "number" && typ == "integer") {
+				match = true
+				break
+			}
+		}
+		if !match {
+			addErr("type", "expected %s, got %s", strings.Join(m.Types, " or "), typ)
+			return
+		}
+	}
+	if m.HasConst && !schemaEqual(m.Const, value) {
+		addErr("const", "must be equal to constant %s", schemaString(m.Const))
+	}
+	if m.Enum != nil {
+		var match bool
+		for _, e := range m.Enum {
+			if schemaEqual(e, value) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			addErr("enum", "must be one of %s", schemaString(m.Enum))
+		}
+	}
+	switch typ {
+	case "string":
+		s := value.(string)
+		n := utf8.RuneCountInString(s)
+		if m.MinLength != nil && n < *m.MinLength {
+			addErr("minLength", "length must be >= %d, got %d", *m.MinLength, n)
+		}
+		if m.MaxLength != nil && n > *m.MaxLength {
+			addErr("maxLength", "length must be <= %d, got %d", *m.MaxLength, n)
+		}
+		if m.Pattern != nil && !m.Pattern.MatchString(s) {
+			addErr("pattern", "does not match pattern %q", m.Pattern.String())
+		}
+	case "number", "integer":
+		n, _ := toFloat64(value)
+		if m.Minimum != nil && n < *m.Minimum {
+			addErr("minimum", "must be >= %v, got %v", *m.Minimum, n)
+		}
+		if m.Maximum != nil && n > *m.Maximum {
+			addErr("maximum", "must be <= %v, got %v", *m.Maximum, n)
+		}
+		if m.ExclusiveMinimum != nil && n <= *m.ExclusiveMinimum {
+			addErr("exclusiveMinimum", "must be > %v, got %v", *m.ExclusiveMinimum, n)
+		}
+		if m.ExclusiveMaximum != nil && n >= *m.ExclusiveMaximum {
+			addErr("exclusiveMaximum", "must be < %v, got %v", *m.ExclusiveMaximum, n)
+		}
+	case "array":
+		items := value.([]any)
+		size := len(items)
+		if m.MinItems != nil && size < *m.MinItems {
+			addErr("minItems", "must have at least %d items, got %d", *m.MinItems, size)
+		}
+		if m.MaxItems != nil && size > *m.MaxItems {
+			addErr("maxItems", "must have at most %d items, got %d", *m.MaxItems, size)
+		}
+		for i := 0; i < size; i++ {
+			var itemSchema *Schema
+			if i < len(m.PrefixItems) {
+				itemSchema = m.PrefixItems[i]
+			} else {
+				itemSchema = m.Items
+			}
+			if itemSchema != nil {
+				itemSchema.validate(items[i], path+"["+strconv.Itoa(i)+"]", pointer+"/"+strconv.Itoa(i), errs)
+			}
+		}
+	case "object":
+		object := value.(map[string]any)
+		for _, key := range m.Required {
+			if _, ok := object[key]; !ok {
+				*errs = append(*errs, Error{Path: schemaKeyPath(path, key), Pointer: schemaKeyPointer(pointer, key), Keyword: "required", Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath, keyPointer := schemaKeyPath(path, key), schemaKeyPointer(pointer, key)
+			if prop, ok := m.Properties[key]; ok {
+				prop.validate(object[key], keyPath, keyPointer, errs)
+			} else if m.AdditionalProperties != nil {
+				m.AdditionalProperties.validate(object[key], keyPath, keyPointer, errs)
+			} else if m.DenyAdditional {
+				*errs = append(*errs, Error{Path: keyPath, Pointer: keyPointer, Keyword: "additionalProperties", Message: "is not allowed"})
+			}
+		}
+	}
+}
+
+// schemaType returns the JSON Schema type name of value
+func schemaType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+	}
+	if f, ok := toFloat64(value); ok {
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "unknown"
+}
+
+// schemaEqual compares two JSON values, numbers are compared by value
+func schemaEqual(a, b any) bool {
+	if af, ok := toFloat64(a); ok {
+		bf, ok := toFloat64(b)
+		return ok && af == bf
+	}
+	switch a.(type) {
+	case []any:
+		bv, ok := b.([]any)
+		av := a.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !schemaEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		av := a.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			if w, ok := bv[k]; !ok || !schemaEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func schemaString(v any) string {
+	if r, err := json.Marshal(v); err == nil {
+		return string(r)
+	}
+	return fmt.Sprint(v)
+}
+
+var schemaIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// schemaKeyPointer JSON Pointer of key, ~ and / are escaped as ~0 and ~1
+func schemaKeyPointer(pointer, key string) string {
+	return pointer + "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// schemaKeyPath $.key or $["the key"]
+func schemaKeyPath(path, key string) string {
+	if schemaIdentifier.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// toFloat64 numbers and json.Number
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSchemaRef(t *testing.T) {
+	schema, err := New(`{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"tags": {"$ref": "#/$defs/tags"},
+			"a/b": {"type": "string"},
+			"linux": {"$ref": "#", "type": "object"},
+			"n": {"type": "integer"}
+		},
+		"$defs": {
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 数字为 json.Number
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(`{"tags": ["a", 1], "a/b": 1, "linux": {"tags": [2], "x": 1}, "n": 1.5}`))
+	decoder.UseNumber()
+	if err = decoder.Decode(&value); err != nil {
+		t.Fatal(err)
+	}
+	err = schema.Validate(value)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	expected := map[string]string{
+		"/tags/1":       "type",
+		"/a~1b":         "type",
+		"/linux/tags/0": "type",
+		"/linux/x":      "additionalProperties",
+		"/n":            "type",
+	}
+	ve := err.(*ValidationError)
+	if len(ve.Errors) != len(expected) {
+		t.Fatal("unexpected errors:", ve.Error())
+	}
+	for _, e := range ve.Errors {
+		if expected[e.Pointer] != e.Keyword {
+			t.Fatal("unexpected error:", e.Pointer, e.Keyword, e.Message)
+		}
+	}
+	if _, err = New(`{"properties": {"a": {"$ref": "#/$defs/none"}}}`); err == nil {
+		t.Fatal("expected unresolved $ref error")
+	}
+}
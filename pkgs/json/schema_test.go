@@ -0,0 +1,76 @@
+package json
+
+import (
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := NewSchema(`{
+		"type": "array",
+		"minItems": 2,
+		"prefixItems": [
+			{"type": "string", "minLength": 1, "pattern": "^[a-z]+$"},
+			{
+				"type": "object",
+				"required": ["name", "age"],
+				"additionalProperties": false,
+				"properties": {
+					"name": {"type": "string", "maxLength": 4},
+					"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+					"role": {"enum": ["admin", "user"]},
+					"tags": {"type": "array", "items": {"type": "string"}}
+				}
+			}
+		]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := NewJSONArray(`["abc", {"name": "tom", "age": 20, "role": "user", "tags": ["a"]}]`)
+	if err := schema.Validate(valid.JsonData()); err != nil {
+		t.Fatal("expected valid:", err)
+	}
+	invalid := NewJSONArray(`["ABC", {"name": "jerry", "age": 20.5, "role": "root", "tags": ["a", 1], "x": 1}]`)
+	err = schema.Validate(invalid.JsonData())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	ve := err.(*ValidationError)
+	expected := map[string]string{
+		"$[0]":         "pattern",
+		"$[1].name":    "maxLength",
+		"$[1].age":     "type",
+		"$[1].role":    "enum",
+		"$[1].tags[1]": "type",
+		"$[1].x":       "additionalProperties",
+	}
+	if len(ve.Errors) != len(expected) {
+		t.Fatal("unexpected errors:", ve.Error())
+	}
+	for _, e := range ve.Errors {
+		if expected[e.Path] != e.Keyword {
+			t.Fatal("unexpected error:", e.Path, e.Keyword, e.Message)
+		}
+	}
+	missing := NewJSONArray(`["abc", {}]`)
+	err = schema.Validate(missing.JsonData())
+	if err == nil || len(err.(*ValidationError).Errors) != 2 {
+		t.Fatal("expected required errors:", err)
+	}
+	short := NewJSONArray(`["abc"]`)
+	if err = schema.Validate(short.JsonData()); err == nil || err.(*ValidationError).Errors[0].Keyword != "minItems" {
+		t.Fatal("expected minItems error:", err)
+	}
+}
+
+func TestSchemaCompileError(t *testing.T) {
+	if _, err := NewSchema(`{"type": "bogus"}`); err == nil {
+		t.Fatal("expected unknown type error")
+	}
+	if _, err := NewSchema(`{"pattern": "("}`); err == nil {
+		t.Fatal("expected pattern error")
+	}
+	if _, err := NewSchema(`[]`); err == nil {
+		t.Fatal("expected object error")
+	}
+}
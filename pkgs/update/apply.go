@@ -0,0 +1,188 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package update
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// 替换时无法删除的旧文件, 例如 windows 运行中的执行文件, 下次启动时删除
+const cleanupFile = "cleanup.json"
+
+// 替换的文件, old 为重命名的原文件, 原文件不存在时为空
+type replacement struct {
+	dst, old string
+}
+
+// 是否为 CEF 子进程
+func isSubprocess() bool {
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "--type=") {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply 应用已暂存的更新, 在程序启动时调用, 返回 true 时调用 Restart 启动新版本
+//
+//	CEF 子进程不应用
+//	暂存的版本不大于当前版本时删除暂存的更新
+//	文件先复制到安装目录再重命名替换, 原文件重命名为 .old, 失败时恢复所有已替换的文件
+//	运行中的执行文件不能覆盖 (windows), 但可以重命名
+func (m *Updater) Apply() (bool, error) {
+	if isSubprocess() {
+		return false, nil
+	}
+	m.cleanup()
+	staged, err := m.Pending()
+	if staged == nil || err != nil {
+		return false, err
+	}
+	if c, err := CompareVersion(staged.Version, m.config.Version); err != nil || c <= 0 {
+		os.RemoveAll(m.config.StagingDir)
+		return false, err
+	}
+	var replaced []*replacement
+	for _, name := range staged.Files {
+		src, err := safeJoin(filepath.Join(m.config.StagingDir, filesDir), name)
+		if err != nil {
+			rollback(replaced)
+			return false, err
+		}
+		dst, err := safeJoin(m.config.InstallDir, name)
+		if err != nil {
+			rollback(replaced)
+			return false, err
+		}
+		r, err := replaceFile(src, dst)
+		if err != nil {
+			rollback(replaced)
+			return false, err
+		}
+		replaced = append(replaced, r)
+	}
+	var remain []string
+	for _, r := range replaced {
+		if r.old != "" && os.Remove(r.old) != nil {
+			remain = append(remain, r.old)
+		}
+	}
+	if err = os.RemoveAll(m.config.StagingDir); err != nil {
+		return true, err
+	}
+	if len(remain) > 0 {
+		if err = os.MkdirAll(m.config.StagingDir, os.ModePerm); err == nil {
+			err = writeJSON(filepath.Join(m.config.StagingDir, cleanupFile), remain)
+		}
+	}
+	return true, err
+}
+
+// 删除上次更新留下的旧文件
+func (m *Updater) cleanup() {
+	file := filepath.Join(m.config.StagingDir, cleanupFile)
+	var remain []string
+	if err := readJSON(file, &remain); err != nil {
+		return
+	}
+	var failed []string
+	for _, name := range remain {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) == 0 {
+		os.Remove(file)
+	} else {
+		writeJSON(file, failed)
+	}
+}
+
+// 复制暂存的文件到目标目录, 重命名替换目标文件
+func replaceFile(src, dst string) (*replacement, error) {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return nil, err
+	}
+	tmp := dst + ".update"
+	os.Remove(tmp)
+	if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	r := &replacement{dst: dst}
+	if _, err := os.Lstat(dst); err == nil {
+		r.old = dst + ".old"
+		os.Remove(r.old)
+		if err = os.Rename(dst, r.old); err != nil {
+			os.Remove(tmp)
+			return nil, err
+		}
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		if r.old != "" {
+			os.Rename(r.old, dst)
+		}
+		os.Remove(tmp)
+		return nil, err
+	}
+	return r, nil
+}
+
+// 恢复已替换的文件
+func rollback(replaced []*replacement) {
+	for i := len(replaced) - 1; i >= 0; i-- {
+		r := replaced[i]
+		os.Remove(r.dst)
+		if r.old != "" {
+			os.Rename(r.old, r.dst)
+		}
+	}
+}
+
+// 复制文件或符号链接, 保留权限
+func copyFile(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, dst)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeFile(dst, in, info.Mode().Perm())
+}
+
+// Restart 使用相同的参数启动程序, 然后退出当前程序
+func Restart() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
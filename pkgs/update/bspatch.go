@@ -0,0 +1,86 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package update
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// 差量更新包, bsdiff 4.x 格式 (BSDIFF40), 使用 bsdiff 工具生成: bsdiff old new patch
+//
+//	头 32 字节: "BSDIFF40", 控制块长度, 差异块长度, 新文件长度
+//	控制块, 差异块, 额外块分别使用 bzip2 压缩
+//	控制块为 (x, y, z) 三元组: 差异块 x 字节与旧文件相加, 复制额外块 y 字节, 旧文件位置移动 z
+const bsdiffMagic = "BSDIFF40"
+
+// ErrCorruptPatch 差量更新包格式错误
+var ErrCorruptPatch = errors.New("update: corrupt patch")
+
+// bsdiff 整数, 小端, 最高位为符号位
+func offtin(b []byte) int64 {
+	v := int64(binary.LittleEndian.Uint64(b) &^ (1 << 63))
+	if b[7]&0x80 != 0 {
+		v = -v
+	}
+	return v
+}
+
+// Patch 应用差量更新包, 返回新文件
+func Patch(old, patch []byte) ([]byte, error) {
+	if len(patch) < 32 || string(patch[:8]) != bsdiffMagic {
+		return nil, ErrCorruptPatch
+	}
+	ctrlLen, diffLen, newSize := offtin(patch[8:]), offtin(patch[16:]), offtin(patch[24:])
+	if ctrlLen < 0 || diffLen < 0 || newSize < 0 || 32+ctrlLen+diffLen > int64(len(patch)) {
+		return nil, ErrCorruptPatch
+	}
+	ctrl := bzip2.NewReader(bytes.NewReader(patch[32 : 32+ctrlLen]))
+	diff := bzip2.NewReader(bytes.NewReader(patch[32+ctrlLen : 32+ctrlLen+diffLen]))
+	extra := bzip2.NewReader(bytes.NewReader(patch[32+ctrlLen+diffLen:]))
+	result := make([]byte, newSize)
+	var (
+		buf            [24]byte
+		oldPos, newPos int64
+		oldSize        = int64(len(old))
+	)
+	for newPos < newSize {
+		if _, err := io.ReadFull(ctrl, buf[:]); err != nil {
+			return nil, ErrCorruptPatch
+		}
+		x, y, z := offtin(buf[0:]), offtin(buf[8:]), offtin(buf[16:])
+		if x < 0 || y < 0 || newPos+x > newSize {
+			return nil, ErrCorruptPatch
+		}
+		if _, err := io.ReadFull(diff, result[newPos:newPos+x]); err != nil {
+			return nil, ErrCorruptPatch
+		}
+		for i := int64(0); i < x; i++ {
+			if oldPos+i >= 0 && oldPos+i < oldSize {
+				result[newPos+i] += old[oldPos+i]
+			}
+		}
+		newPos += x
+		oldPos += x
+		if newPos+y > newSize {
+			return nil, ErrCorruptPatch
+		}
+		if _, err := io.ReadFull(extra, result[newPos:newPos+y]); err != nil {
+			return nil, ErrCorruptPatch
+		}
+		newPos += y
+		oldPos += z
+	}
+	return result, nil
+}
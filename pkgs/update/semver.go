@@ -0,0 +1,113 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package update
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 语义化版本 https://semver.org
+//
+//	v 前缀可选, 缺少的 minor, patch 为 0, 例如 1.2 => 1.2.0
+//	构建元数据 (+ 之后) 不参与比较
+type semver struct {
+	major, minor, patch uint64
+	pre                 []string
+}
+
+func parseVersion(version string) (*semver, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	v := &semver{}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.pre = strings.Split(s[i+1:], ".")
+		s = s[:i]
+		for _, id := range v.pre {
+			if id == "" {
+				return nil, fmt.Errorf("invalid version %q", version)
+			}
+		}
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+	nums := []*uint64{&v.major, &v.minor, &v.patch}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+func compareUint(a, b uint64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// 预发布版本比较, 没有预发布版本的更大, 数字标识小于字母标识
+func comparePre(a, b []string) int {
+	if len(a) == 0 || len(b) == 0 {
+		return -compareUint(uint64(len(a)), uint64(len(b)))
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.ParseUint(a[i], 10, 64)
+		bn, bErr := strconv.ParseUint(b[i], 10, 64)
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = compareUint(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(a)), uint64(len(b)))
+}
+
+// CompareVersion 比较语义化版本, a < b 返回 -1, a == b 返回 0, a > b 返回 1
+func CompareVersion(a, b string) (int, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	if c := compareUint(va.major, vb.major); c != 0 {
+		return c, nil
+	}
+	if c := compareUint(va.minor, vb.minor); c != 0 {
+		return c, nil
+	}
+	if c := compareUint(va.patch, vb.patch); c != 0 {
+		return c, nil
+	}
+	return comparePre(va.pre, vb.pre), nil
+}
@@ -0,0 +1,77 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package update
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 签名内容, 每行 key=value, 顺序固定, 包含清单名称, 版本和系统
+//
+//	旧版本的签名更新包不能在新版本清单或其它系统中使用
+//		energy-update
+//		name=[manifest.name]
+//		version=[manifest.version]
+//		platform=[os/arch]
+//		kind=full 或 delta
+//		prefix=[platform.prefix]      完整包, 安装目录对应的目录
+//		from=[delta.from]             差量包
+//		file=[delta.file]             差量包
+//		fileSha256=[delta.fileSha256] 差量包
+//		sha256=[payload.sha256]
+//
+//	delta 为 nil 时签名完整包 p.Payload, 否则签名差量包 delta.Payload
+func signedData(name, version, platform string, p *Platform, delta *Delta) []byte {
+	var b strings.Builder
+	b.WriteString("energy-update\n")
+	fmt.Fprintf(&b, "name=%s\nversion=%s\nplatform=%s\n", name, version, platform)
+	payload := &p.Payload
+	if delta == nil {
+		fmt.Fprintf(&b, "kind=full\nprefix=%s\n", strings.Trim(p.Prefix, "/"))
+	} else {
+		payload = &delta.Payload
+		fmt.Fprintf(&b, "kind=delta\nfrom=%s\nfile=%s\nfileSha256=%s\n", delta.From, delta.File, strings.ToLower(delta.FileSHA256))
+	}
+	fmt.Fprintf(&b, "sha256=%s\n", strings.ToLower(payload.SHA256))
+	return []byte(b.String())
+}
+
+// Sign 发布时签名清单中的所有更新包, 设置 signature
+//
+//	更新包的 sha256 必须已设置, 清单的 name, version, 系统和 prefix 修改后需要重新签名
+func Sign(manifest *Manifest, privateKey ed25519.PrivateKey) error {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return errors.New("update: invalid private key")
+	}
+	sign := func(name string, platform *Platform, payload *Payload, delta *Delta) error {
+		if payload.SHA256 == "" {
+			return fmt.Errorf("update: %s: %s sha256 is empty", name, payload.URL)
+		}
+		data := signedData(manifest.Name, manifest.Version, name, platform, delta)
+		payload.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data))
+		return nil
+	}
+	for name, platform := range manifest.Platforms {
+		if err := sign(name, platform, &platform.Payload, nil); err != nil {
+			return err
+		}
+		for _, delta := range platform.Deltas {
+			if err := sign(name, platform, &delta.Payload, delta); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
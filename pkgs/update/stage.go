@@ -0,0 +1,427 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// 暂存目录
+//
+//	staged.json  已暂存的更新, 所有文件暂存后写入
+//	files/       更新的文件, 相对安装目录
+//	download/    下载的更新包
+const (
+	stagedFile  = "staged.json"
+	filesDir    = "files"
+	downloadDir = "download"
+)
+
+var (
+	// ErrPublicKey 未设置或无效的签名公钥
+	ErrPublicKey = errors.New("update: invalid ed25519 public key")
+	// ErrVerify 更新包 sha256 或签名校验失败
+	ErrVerify = errors.New("update: verification failed")
+)
+
+// Staged 已暂存的更新
+type Staged struct {
+	Version string   `json:"version"`
+	Delta   bool     `json:"delta"` // 使用差量包
+	Files   []string `json:"files"` // 相对安装目录, / 分隔
+}
+
+// 下载进度
+type progressReader struct {
+	r        io.Reader
+	total, n int64
+	progress func(total, n int64)
+}
+
+func (m *progressReader) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	m.n += int64(n)
+	if m.progress != nil && n > 0 {
+		m.progress(m.total, m.n)
+	}
+	return n, err
+}
+
+// 校验 sha256 和签名, signed 为签名内容, 见 signedData
+func (m *Updater) verify(payload *Payload, signed, sum []byte) error {
+	if !strings.EqualFold(hex.EncodeToString(sum), payload.SHA256) {
+		return fmt.Errorf("%w: %s sha256 mismatch", ErrVerify, payload.URL)
+	}
+	signature, err := base64.StdEncoding.DecodeString(payload.Signature)
+	if err != nil || !ed25519.Verify(m.config.PublicKey, signed, signature) {
+		return fmt.Errorf("%w: %s invalid signature", ErrVerify, payload.URL)
+	}
+	return nil
+}
+
+// 下载并校验更新包, 返回文件路径
+func (m *Updater) download(payload *Payload, signed []byte, progress func(total, n int64)) (string, error) {
+	location, err := m.resolve(payload.URL)
+	if err != nil {
+		return "", err
+	}
+	r, size, err := m.open(location)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	if size < 0 {
+		size = payload.Size
+	}
+	dir := filepath.Join(m.config.StagingDir, downloadDir)
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, path.Base(filepath.ToSlash(payload.URL)))
+	f, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), &progressReader{r: r, total: size, progress: progress})
+	if ec := f.Close(); err == nil {
+		err = ec
+	}
+	if err != nil {
+		return "", err
+	}
+	if payload.Size > 0 && n != payload.Size {
+		return "", fmt.Errorf("update: %s: incomplete, %d of %d bytes", payload.URL, n, payload.Size)
+	}
+	if err = m.verify(payload, signed, h.Sum(nil)); err != nil {
+		os.Remove(file)
+		return "", err
+	}
+	return file, nil
+}
+
+// 当前版本的差量包
+func (m *Updater) delta(release *Release) *Delta {
+	for _, delta := range release.Platform.Deltas {
+		if c, err := CompareVersion(delta.From, m.config.Version); err == nil && c == 0 && delta.File != "" {
+			return delta
+		}
+	}
+	return nil
+}
+
+// Download 下载更新包并暂存, 下次启动时 Apply 应用
+//
+//	优先使用当前版本的差量包, 失败时使用完整包
+//	progress 下载进度, total 未知时为 -1
+func (m *Updater) Download(release *Release, progress func(total, n int64)) error {
+	if release.Manifest == nil || release.Platform == nil {
+		return fmt.Errorf("update: no update for %s", m.config.Platform)
+	}
+	if len(m.config.PublicKey) != ed25519.PublicKeySize {
+		return ErrPublicKey
+	}
+	m.cleanup()
+	if err := os.RemoveAll(m.config.StagingDir); err != nil {
+		return err
+	}
+	var (
+		staged *Staged
+		err    error
+	)
+	name, version := release.Manifest.Name, release.Manifest.Version
+	if delta := m.delta(release); delta != nil {
+		signed := signedData(name, version, m.config.Platform, release.Platform, delta)
+		if err = m.stageDelta(delta, signed, progress); err == nil {
+			staged = &Staged{Version: version, Delta: true, Files: []string{path.Clean(delta.File)}}
+		} else {
+			os.RemoveAll(filepath.Join(m.config.StagingDir, filesDir))
+		}
+	}
+	if staged == nil {
+		var files []string
+		signed := signedData(name, version, m.config.Platform, release.Platform, nil)
+		if files, err = m.stageFull(release.Platform, signed, progress); err != nil {
+			os.RemoveAll(m.config.StagingDir)
+			return err
+		}
+		staged = &Staged{Version: version, Files: files}
+	}
+	os.RemoveAll(filepath.Join(m.config.StagingDir, downloadDir))
+	return writeJSON(filepath.Join(m.config.StagingDir, stagedFile), staged)
+}
+
+// 先写临时文件再重命名, 不会读取到不完整的文件
+func writeJSON(file string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(file+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+func readJSON(file string, v any) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Pending 已暂存的更新, 没有时返回 nil
+func (m *Updater) Pending() (*Staged, error) {
+	staged := &Staged{}
+	if err := readJSON(filepath.Join(m.config.StagingDir, stagedFile), staged); os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+// 暂存差量更新, 使用安装目录中的文件生成新文件
+func (m *Updater) stageDelta(delta *Delta, signed []byte, progress func(total, n int64)) error {
+	name, err := safeJoin(m.config.InstallDir, delta.File)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	old, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	patchFile, err := m.download(&delta.Payload, signed, progress)
+	if err != nil {
+		return err
+	}
+	patch, err := os.ReadFile(patchFile)
+	if err != nil {
+		return err
+	}
+	data, err := Patch(old, patch)
+	if err != nil {
+		return err
+	}
+	if sum := sha256.Sum256(data); !strings.EqualFold(hex.EncodeToString(sum[:]), delta.FileSHA256) {
+		return fmt.Errorf("%w: %s patched file sha256 mismatch", ErrVerify, delta.File)
+	}
+	target, err := stagePath(filepath.Join(m.config.StagingDir, filesDir), delta.File)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, info.Mode().Perm())
+}
+
+// 暂存完整更新, 解压 zip 或 tar.gz, 返回文件列表
+func (m *Updater) stageFull(platform *Platform, signed []byte, progress func(total, n int64)) ([]string, error) {
+	file, err := m.download(&platform.Payload, signed, progress)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(m.config.StagingDir, filesDir)
+	prefix := strings.Trim(platform.Prefix, "/")
+	var files []string
+	if strings.HasSuffix(strings.ToLower(platform.URL), ".zip") {
+		files, err = extractZip(file, dir, prefix)
+	} else {
+		files, err = extractTarGz(file, dir, prefix)
+	}
+	if err == nil && len(files) == 0 {
+		err = fmt.Errorf("update: %s: no files in %q", platform.URL, prefix)
+	}
+	return files, err
+}
+
+// 去掉压缩包中的前缀目录, 不在前缀目录中时返回 false
+func trimPrefix(name, prefix string) (string, bool) {
+	name = strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "./")
+	if prefix == "" {
+		return name, true
+	}
+	if !strings.HasPrefix(name, prefix+"/") {
+		return "", false
+	}
+	return name[len(prefix)+1:], true
+}
+
+// 压缩包内文件路径拼接目标目录, 拒绝跳出目标目录的路径
+func safeJoin(dir, name string) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(name, "\\", "/")))
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("update: illegal file path %s", name)
+	}
+	return target, nil
+}
+
+// 暂存文件路径, 拒绝跳出暂存目录的路径和通过符号链接写入
+//
+//	上级目录不能是符号链接, 已存在的文件或符号链接先删除
+func stagePath(dir, name string) (string, error) {
+	target, err := safeJoin(dir, name)
+	if err != nil {
+		return "", err
+	}
+	rel, _ := filepath.Rel(dir, filepath.Dir(target))
+	parent := dir
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if part == "." {
+			continue
+		}
+		parent = filepath.Join(parent, part)
+		info, err := os.Lstat(parent)
+		if os.IsNotExist(err) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("update: illegal file path %s, %s is a symlink", name, filepath.ToSlash(rel))
+		}
+	}
+	if err = os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return target, nil
+}
+
+// 符号链接目标不能跳出暂存目录, 不能是绝对路径
+func checkLink(dir, target, link string) error {
+	if link == "" || filepath.IsAbs(link) || path.IsAbs(filepath.ToSlash(link)) {
+		return fmt.Errorf("update: illegal symlink %s -> %s", filepath.Base(target), link)
+	}
+	resolved := filepath.Join(filepath.Dir(target), filepath.FromSlash(link))
+	rel, err := filepath.Rel(dir, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("update: illegal symlink %s -> %s", filepath.Base(target), link)
+	}
+	return nil
+}
+
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if mode == 0 {
+		// windows 创建的 zip 没有权限信息
+		mode = 0644
+	}
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func extractTarGz(file, dir, prefix string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	var files []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		name, ok := trimPrefix(header.Name, prefix)
+		if !ok || header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeSymlink {
+			continue
+		}
+		target, err := stagePath(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeSymlink {
+			if err = checkLink(dir, target, header.Linkname); err != nil {
+				return nil, err
+			}
+			if err = os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+				return nil, err
+			}
+			if err = os.Symlink(header.Linkname, target); err != nil {
+				return nil, err
+			}
+		} else if err = writeFile(target, tr, os.FileMode(header.Mode).Perm()); err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(dir, target)
+		files = append(files, filepath.ToSlash(rel))
+	}
+	return files, nil
+}
+
+func extractZip(file, dir, prefix string) ([]string, error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var files []string
+	for _, f := range zr.File {
+		name, ok := trimPrefix(f.Name, prefix)
+		if !ok || f.FileInfo().IsDir() {
+			continue
+		}
+		target, err := stagePath(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		err = writeFile(target, r, f.Mode().Perm())
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(dir, target)
+		files = append(files, filepath.ToSlash(rel))
+	}
+	return files, nil
+}
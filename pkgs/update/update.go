@@ -0,0 +1,275 @@
+//----------------------------------------
+//
+// Copyright © yanghy. All Rights Reserved.
+//
+// Licensed under Apache License Version 2.0, January 2004
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+//
+//----------------------------------------
+
+// 应用自动更新
+//
+//	读取发布清单 (manifest.json), 比较当前版本 (buildinfo.Version), 下载更新包并暂存, 下次启动时应用
+//	更新包: 完整包 (tar.gz, zip) 或差量包 (bsdiff), 必须有 sha256 和 ed25519 签名
+//	签名内容包含清单 name, version, 系统和更新包 sha256, 发布时使用 Sign 签名, 下载后暂存前校验
+//	完整包中的文件相对安装目录, 和 energy package 的安装目录一致
+//		linux:   /opt/[company]/[product]
+//		windows: NSIS 安装目录 $INSTDIR
+//		macos:   [product].app, 文件路径 Contents/...
+//	linux 可以直接使用 energy package --format tar.gz 生成的压缩包, prefix 设置为压缩包中的根目录
+//	差量包只更新安装目录中的一个文件, 通常是执行文件, 失败时使用完整包
+//	完整包中的符号链接不能指向安装目录之外, 不能通过符号链接写入文件
+//	安装目录需要写权限, /opt 和 Program Files 通常需要管理员权限
+//
+//	使用
+//		updater, err := update.New(update.Config{Source: "https://example.com/app", PublicKey: publicKey})
+//		// 启动时应用已暂存的更新, 在 cef.GlobalInit 之前
+//		if applied, err := updater.Apply(); applied && err == nil {
+//			update.Restart()
+//		}
+//		// 检查并下载更新, 下次启动时应用
+//		if release, err := updater.Check(); err == nil && release.Newer {
+//			updater.Download(release, nil)
+//		}
+//
+//	manifest.json
+//		{
+//		  "name": "demo",
+//		  "version": "1.2.0",
+//		  "notes": "更新说明",
+//		  "platforms": {
+//		    "linux/amd64": {
+//		      "url": "demo-1.2.0-linux-amd64.tar.gz", "prefix": "demo-1.2.0-linux-amd64", "size": 1024, "sha256": "hex", "signature": "base64",
+//		      "deltas": [{"from": "1.1.0", "file": "demo", "fileSha256": "hex", "url": "demo-1.1.0-1.2.0-linux-amd64.patch", "sha256": "hex", "signature": "base64"}]
+//		    }
+//		  }
+//		}
+//	url 为相对地址时相对 manifest.json 所在位置
+package update
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/energye/energy/v2/pkgs/buildinfo"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ManifestFile 发布清单文件名
+const ManifestFile = "manifest.json"
+
+// Config 更新配置
+type Config struct {
+	Name       string            // 应用名称, 设置时清单的 name 必须一致
+	Source     string            // 发布地址, http(s) 地址或本地目录 (测试), 不以 .json 结尾时读取其中的 manifest.json
+	PublicKey  ed25519.PublicKey // 更新包签名公钥, 下载时必须设置
+	Version    string            // 当前版本, 默认 buildinfo.Get().Version
+	Platform   string            // os/arch, 默认当前系统
+	InstallDir string            // 安装目录, 默认 InstallDir()
+	StagingDir string            // 暂存目录, 默认 用户缓存目录/[执行文件名]/update
+	Client     *http.Client      // http 客户端, 默认 http.DefaultClient
+}
+
+// Manifest 发布清单
+type Manifest struct {
+	Name      string               `json:"name"`
+	Version   string               `json:"version"`
+	Notes     string               `json:"notes"`
+	Date      string               `json:"date"`
+	Platforms map[string]*Platform `json:"platforms"` // os/arch => 更新包
+}
+
+// Payload 更新包
+type Payload struct {
+	URL       string `json:"url"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`    // 更新包 sha256
+	Signature string `json:"signature"` // 签名内容的 ed25519 签名, base64, 见 Sign
+}
+
+// Platform 系统的完整包和差量包
+type Platform struct {
+	Payload
+	Prefix string   `json:"prefix"` // 完整包中对应安装目录的目录, 例如 energy package 生成的 tar.gz: [name]-[version]-linux-[arch]
+	Deltas []*Delta `json:"deltas"`
+}
+
+// Delta 差量包, 从 From 版本更新安装目录中的 File
+type Delta struct {
+	Payload
+	From       string `json:"from"`
+	File       string `json:"file"`       // 相对安装目录, / 分隔
+	FileSHA256 string `json:"fileSha256"` // 更新后的文件 sha256
+}
+
+// Release 检查结果
+type Release struct {
+	Manifest *Manifest
+	Version  string
+	Notes    string
+	Platform *Platform // 当前系统的更新包, 没有时为 nil
+	Newer    bool      // 版本大于当前版本
+}
+
+// Updater 自动更新
+type Updater struct {
+	config Config
+}
+
+// New 创建自动更新, 设置默认值
+func New(config Config) (*Updater, error) {
+	if config.Source == "" {
+		return nil, errors.New("update: source is empty")
+	}
+	if config.Version == "" {
+		config.Version = buildinfo.Get().Version
+	}
+	if config.Platform == "" {
+		config.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	}
+	if config.InstallDir == "" {
+		dir, err := InstallDir()
+		if err != nil {
+			return nil, err
+		}
+		config.InstallDir = dir
+	}
+	if config.StagingDir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(exe), ".exe")
+		config.StagingDir = filepath.Join(cache, name, "update")
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &Updater{config: config}, nil
+}
+
+// InstallDir 安装目录
+//
+//	macos: 执行文件所在的 .app 目录, 其它: 执行文件所在目录
+func InstallDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", err
+	}
+	dir := filepath.Dir(exe)
+	if runtime.GOOS == "darwin" {
+		// [product].app/Contents/MacOS/[exe]
+		if app := filepath.Dir(filepath.Dir(dir)); strings.HasSuffix(app, ".app") {
+			return app, nil
+		}
+	}
+	return dir, nil
+}
+
+// 清单地址
+func (m *Updater) manifestLocation() string {
+	if strings.HasSuffix(strings.ToLower(m.config.Source), ".json") {
+		return m.config.Source
+	}
+	if isRemote(m.config.Source) {
+		return strings.TrimSuffix(m.config.Source, "/") + "/" + ManifestFile
+	}
+	return filepath.Join(m.config.Source, ManifestFile)
+}
+
+func isRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// 更新包地址, 相对地址相对清单所在位置
+func (m *Updater) resolve(location string) (string, error) {
+	if isRemote(location) || filepath.IsAbs(location) {
+		return location, nil
+	}
+	base := m.manifestLocation()
+	if !isRemote(base) {
+		return filepath.Join(filepath.Dir(base), filepath.FromSlash(location)), nil
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
+
+// 打开 http 地址或本地文件, 返回内容和大小 (未知时 -1)
+func (m *Updater) open(location string) (io.ReadCloser, int64, error) {
+	if !isRemote(location) {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, 0, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		return f, info.Size(), nil
+	}
+	resp, err := m.config.Client.Get(location)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("update: %s: %s", location, resp.Status)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// Check 读取发布清单, 比较版本
+func (m *Updater) Check() (*Release, error) {
+	r, _, err := m.open(m.manifestLocation())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	manifest := &Manifest{}
+	if err = json.NewDecoder(r).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("update: %s: %v", ManifestFile, err)
+	}
+	if manifest.Version == "" {
+		return nil, fmt.Errorf("update: %s: version is empty", ManifestFile)
+	}
+	if m.config.Name != "" && manifest.Name != m.config.Name {
+		return nil, fmt.Errorf("update: %s: name %q, expected %q", ManifestFile, manifest.Name, m.config.Name)
+	}
+	if m.config.Version == "" {
+		return nil, errors.New("update: current version unknown, build with energy build or set Config.Version")
+	}
+	c, err := CompareVersion(manifest.Version, m.config.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &Release{
+		Manifest: manifest,
+		Version:  manifest.Version,
+		Notes:    manifest.Notes,
+		Platform: manifest.Platforms[m.config.Platform],
+		Newer:    c > 0,
+	}, nil
+}
@@ -0,0 +1,237 @@
+package update
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// bsdiff "hello energy 1.1.0 binary" => "hello energy 1.2.0 binary!!"
+const testPatch = "QlNESUZGNDArAAAAAAAAACkAAAAAAAAAGwAAAAAAAABCWmg5MUFZJlNZzFEGtgAABeAAWAgAICAAMM0AkBpBVm4u5IpwoSGYog1sQlpoOTFBWSZTWXHgU/AAAADAAGIIIAAwzTQSaDaTJxdyRThQkHHgU/BCWmg5MUFZJlNZkRDHLwAAAJAAIAAgACEYRsLuSKcKEhIiGOXg"
+
+func TestCompareVersion(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "v1.2.0+build.5", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-rc.11", "1.0.0-rc.2", 1},
+	} {
+		if got, err := CompareVersion(tc.a, tc.b); err != nil || got != tc.want {
+			t.Errorf("CompareVersion(%s, %s) = %d, %v, want %d", tc.a, tc.b, got, err, tc.want)
+		}
+	}
+	if _, err := CompareVersion("1.x", "1.0"); err == nil {
+		t.Error("expected invalid version error")
+	}
+}
+
+func TestPatch(t *testing.T) {
+	patch, _ := base64.StdEncoding.DecodeString(testPatch)
+	data, err := Patch([]byte("hello energy 1.1.0 binary"), patch)
+	if err != nil || string(data) != "hello energy 1.2.0 binary!!" {
+		t.Fatalf("Patch = %q, %v", data, err)
+	}
+	if _, err = Patch(nil, patch[:40]); err != ErrCorruptPatch {
+		t.Fatalf("expected ErrCorruptPatch, got %v", err)
+	}
+}
+
+// 更新包, 写入清单时签名
+func payload(t *testing.T, dir, name string, data []byte) Payload {
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	return Payload{
+		URL:    name,
+		Size:   int64(len(data)),
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	return tarGzEntries(t, files, nil)
+}
+
+// 按顺序写入符号链接和文件, 符号链接在前
+func tarGzEntries(t *testing.T, files map[string]string, links [][2]string) []byte {
+	file := filepath.Join(t.TempDir(), "payload.tar.gz")
+	f, _ := os.Create(file)
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, link := range links {
+		tw.WriteHeader(&tar.Header{Name: link[0], Linkname: link[1], Mode: 0777, Typeflag: tar.TypeSymlink})
+	}
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gw.Close()
+	f.Close()
+	data, _ := os.ReadFile(file)
+	return data
+}
+
+func TestUpdater(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+	dir := t.TempDir()
+	source := filepath.Join(dir, "release")
+	install := filepath.Join(dir, "opt", "company", "demo")
+	os.MkdirAll(source, 0755)
+	os.MkdirAll(install, 0755)
+	os.WriteFile(filepath.Join(install, "demo"), []byte("hello energy 1.1.0 binary"), 0755)
+	os.WriteFile(filepath.Join(install, "libcef.so"), []byte("cef"), 0644)
+
+	patch, _ := base64.StdEncoding.DecodeString(testPatch)
+	newSum := sha256.Sum256([]byte("hello energy 1.2.0 binary!!"))
+	platform := &Platform{
+		Payload: payload(t, source, "demo-1.2.0.tar.gz", tarGz(t, map[string]string{"demo-1.2.0/demo": "full 1.2.0", "demo-1.2.0/locales/en-US.pak": "en"})),
+		Prefix:  "demo-1.2.0",
+		Deltas: []*Delta{{
+			Payload:    payload(t, source, "demo-1.1.0-1.2.0.patch", patch),
+			From:       "1.1.0",
+			File:       "demo",
+			FileSHA256: hex.EncodeToString(newSum[:]),
+		}},
+	}
+	manifest := &Manifest{Name: "demo", Version: "1.2.0", Platforms: map[string]*Platform{"linux/amd64": platform}}
+	if err := Sign(manifest, privateKey); err != nil {
+		t.Fatal(err)
+	}
+	writeManifest := func() {
+		data, _ := json.Marshal(manifest)
+		os.WriteFile(filepath.Join(source, ManifestFile), data, 0644)
+	}
+	writeManifest()
+	newUpdater := func(version string) *Updater {
+		updater, err := New(Config{Source: source, PublicKey: publicKey, Version: version, Platform: "linux/amd64",
+			InstallDir: install, StagingDir: filepath.Join(dir, "staging")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return updater
+	}
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(install, filepath.FromSlash(name)))
+		return string(data)
+	}
+
+	// 差量更新
+	updater := newUpdater("1.1.0")
+	release, err := updater.Check()
+	if err != nil || !release.Newer || release.Platform == nil {
+		t.Fatal("unexpected release", release, err)
+	}
+	if err = updater.Download(release, nil); err != nil {
+		t.Fatal(err)
+	}
+	if staged, _ := updater.Pending(); staged == nil || !staged.Delta {
+		t.Fatal("expected staged delta update", staged)
+	}
+	if read("demo") != "hello energy 1.1.0 binary" {
+		t.Fatal("installed file changed before apply")
+	}
+	if applied, err := updater.Apply(); !applied || err != nil {
+		t.Fatal("apply", applied, err)
+	}
+	if read("demo") != "hello energy 1.2.0 binary!!" || read("libcef.so") != "cef" {
+		t.Fatal("unexpected delta update", read("demo"))
+	}
+	if info, _ := os.Stat(filepath.Join(install, "demo")); info.Mode().Perm() != 0755 {
+		t.Fatal("mode not preserved", info.Mode())
+	}
+	if applied, _ := newUpdater("1.2.0").Apply(); applied {
+		t.Fatal("applied twice")
+	}
+
+	// 没有当前版本的差量包时使用完整包
+	updater = newUpdater("1.0.0")
+	release, _ = updater.Check()
+	if err = updater.Download(release, nil); err != nil {
+		t.Fatal(err)
+	}
+	if applied, err := updater.Apply(); !applied || err != nil {
+		t.Fatal("apply", applied, err)
+	}
+	if read("demo") != "full 1.2.0" || read("locales/en-US.pak") != "en" {
+		t.Fatal("unexpected full update", read("demo"))
+	}
+
+	// 签名错误
+	expectVerifyError := func(version string) {
+		t.Helper()
+		writeManifest()
+		updater := newUpdater(version)
+		release, _ := updater.Check()
+		if err := updater.Download(release, nil); !errors.Is(err, ErrVerify) {
+			t.Fatal("expected ErrVerify", err)
+		}
+		if staged, _ := updater.Pending(); staged != nil {
+			t.Fatal("staged after verification error")
+		}
+	}
+	// 1.2.0 的签名更新包在 1.3.0 清单中重放
+	manifest.Version = "1.3.0"
+	expectVerifyError("1.2.0")
+	expectVerifyError("1.1.0")
+	// 其它系统的签名更新包
+	manifest.Version = "1.2.0"
+	manifest.Platforms = map[string]*Platform{"linux/arm64": platform}
+	Sign(manifest, privateKey)
+	manifest.Platforms = map[string]*Platform{"linux/amd64": platform}
+	expectVerifyError("1.1.0")
+	// 差量包的签名用于完整包
+	Sign(manifest, privateKey)
+	platform.Signature = platform.Deltas[0].Signature
+	expectVerifyError("1.0.0")
+	// 修改 prefix 安装签名完整包中的其它目录
+	Sign(manifest, privateKey)
+	platform.Prefix = "demo-1.2.0/locales"
+	expectVerifyError("1.0.0")
+}
+
+func TestExtractSymlink(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(dir, "outside")
+	os.MkdirAll(outside, 0755)
+	extract := func(links [][2]string, files map[string]string) ([]string, error) {
+		file := filepath.Join(t.TempDir(), "payload.tar.gz")
+		os.WriteFile(file, tarGzEntries(t, files, links), 0644)
+		staging := filepath.Join(dir, "staging")
+		os.RemoveAll(staging)
+		return extractTarGz(file, staging, "")
+	}
+	for _, link := range []string{outside, "../outside", "sub/../../outside"} {
+		if _, err := extract([][2]string{{"lib", link}}, map[string]string{"lib/x": "x"}); err == nil {
+			t.Errorf("symlink %s: expected error", link)
+		}
+		if _, err := os.Stat(filepath.Join(outside, "x")); err == nil {
+			t.Fatalf("symlink %s: file written outside staging directory", link)
+		}
+	}
+	// 不能通过暂存目录中的符号链接写入
+	if _, err := extract([][2]string{{"lib", "real"}}, map[string]string{"lib/x": "x"}); err == nil {
+		t.Error("expected error writing through symlink")
+	}
+	files, err := extract([][2]string{{"lib/libcef.so", "libcef.so.1"}}, map[string]string{"lib/libcef.so.1": "cef"})
+	if err != nil || len(files) != 2 {
+		t.Fatal(files, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "staging", "lib", "libcef.so")); string(data) != "cef" {
+		t.Fatal("unexpected symlink target", string(data))
+	}
+}
@@ -0,0 +1,264 @@
+package winicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sort"
+
+	"github.com/energye/energy/v2/pkgs/winicon/internal/winicon"
+)
+
+// RequiredSizes are the icon sizes the Windows shell uses for
+// small/large icons, list views and jumbo thumbnails.
+var RequiredSizes = []int{16, 32, 48, 256}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Decode reads a .ico file and decodes every contained image.
+// Both PNG and BMP (DIB) encoded entries are supported.
+func Decode(r io.Reader) ([]image.Image, error) {
+	icons, err := readIcons(r)
+	if err != nil {
+		return nil, err
+	}
+	images := make([]image.Image, len(icons))
+	for i, icon := range icons {
+		if images[i], err = icon.Decode(); err != nil {
+			return nil, fmt.Errorf("icon %d (%dx%d): %w", i, icon.Width, icon.Height, err)
+		}
+	}
+	return images, nil
+}
+
+// Decode decodes the image data of a single icon entry.
+func (m *Icon) Decode() (image.Image, error) {
+	if bytes.HasPrefix(m.Data, pngSignature) {
+		return png.Decode(bytes.NewReader(m.Data))
+	}
+	return decodeDIB(m.Data)
+}
+
+// Validate reads a .ico file and returns warnings for problems that
+// do not prevent embedding it: missing sizes required by the Windows
+// shell, non square entries and low colour depths. An error is returned
+// when the file or one of its images cannot be decoded.
+func Validate(r io.Reader) ([]string, error) {
+	icons, err := readIcons(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		warnings []string
+		sizes    = make(map[int]bool)
+	)
+	for i, icon := range icons {
+		img, err := icon.Decode()
+		if err != nil {
+			return nil, fmt.Errorf("icon %d (%dx%d): %w", i, icon.Width, icon.Height, err)
+		}
+		b := img.Bounds()
+		if b.Dx() != b.Dy() {
+			warnings = append(warnings, fmt.Sprintf("icon %d is not square (%dx%d)", i, b.Dx(), b.Dy()))
+		}
+		if b.Dx() != int(icon.Width) || b.Dy() != int(icon.Height) {
+			warnings = append(warnings, fmt.Sprintf("icon %d header says %dx%d, image is %dx%d", i, icon.Width, icon.Height, b.Dx(), b.Dy()))
+		}
+		if icon.Format == "BMP" && icon.BitsPerPixel > 0 && icon.BitsPerPixel < 32 {
+			warnings = append(warnings, fmt.Sprintf("icon %d (%dx%d) uses %d bits per pixel, 32 bit is recommended", i, b.Dx(), b.Dy(), icon.BitsPerPixel))
+		}
+		sizes[b.Dx()] = true
+	}
+	var missing []int
+	for _, size := range RequiredSizes {
+		if !sizes[size] {
+			missing = append(missing, size)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Ints(missing)
+		warnings = append(warnings, fmt.Sprintf("missing sizes %v required by the Windows shell", missing))
+	}
+	return warnings, nil
+}
+
+// readIcons reads the whole .ico file and slices every entry's data
+// using the offsets in the icon headers.
+func readIcons(r io.Reader) ([]*Icon, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var header winicon.IconFileHeader
+	if err = binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.ImageType != 1 {
+		return nil, errors.New("not an icon file")
+	}
+	if header.ImageCount == 0 {
+		return nil, errors.New("icon file contains no images")
+	}
+	var result []*Icon
+	headers := bytes.NewReader(data[6:])
+	for index := 0; index < int(header.ImageCount); index++ {
+		var iconHeader winicon.IconHeader
+		if err = binary.Read(headers, binary.LittleEndian, &iconHeader); err != nil {
+			return nil, err
+		}
+		end := uint64(iconHeader.Offset) + uint64(iconHeader.Size)
+		if end > uint64(len(data)) {
+			return nil, fmt.Errorf("icon %d data out of range", index)
+		}
+		icon := &Icon{
+			Width:        uint16(iconHeader.Width),
+			Height:       uint16(iconHeader.Height),
+			Colours:      iconHeader.Colours,
+			BitsPerPixel: iconHeader.BitsPerPixel,
+			Planes:       iconHeader.Planes,
+			Offset:       iconHeader.Offset,
+			size:         iconHeader.Size,
+			Data:         data[iconHeader.Offset:end],
+			Format:       "BMP",
+		}
+		// Width/Height of 256 is encoded as 0 in the icon header
+		if icon.Width == 0 {
+			icon.Width = 256
+		}
+		if icon.Height == 0 {
+			icon.Height = 256
+		}
+		if bytes.HasPrefix(icon.Data, pngSignature) {
+			icon.Format = "PNG"
+		} else if len(icon.Data) >= 16 && icon.BitsPerPixel == 0 {
+			icon.BitsPerPixel = binary.LittleEndian.Uint16(icon.Data[14:])
+		}
+		result = append(result, icon)
+	}
+	return result, nil
+}
+
+// bitmapInfoHeader is the BITMAPINFOHEADER that starts a DIB icon entry.
+type bitmapInfoHeader struct {
+	Size          uint32
+	Width         int32
+	Height        int32 // XOR and AND mask, twice the icon height
+	Planes        uint16
+	BitCount      uint16
+	Compression   uint32
+	SizeImage     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+}
+
+const (
+	biRGB       = 0
+	biBitfields = 3
+)
+
+// decodeDIB decodes a BMP encoded icon entry: a BITMAPINFOHEADER, an
+// optional palette, the bottom-up XOR bitmap and the 1 bit AND mask.
+func decodeDIB(data []byte) (image.Image, error) {
+	var h bitmapInfoHeader
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &h); err != nil {
+		return nil, err
+	}
+	if h.Size < 40 || int(h.Size) > len(data) {
+		return nil, fmt.Errorf("unsupported bitmap header size %d", h.Size)
+	}
+	if h.Compression != biRGB && !(h.Compression == biBitfields && h.BitCount == 32) {
+		return nil, fmt.Errorf("unsupported bitmap compression %d", h.Compression)
+	}
+	width, height := int(h.Width), int(h.Height)/2
+	if width <= 0 || height <= 0 || width > 1024 || height > 1024 {
+		return nil, fmt.Errorf("invalid bitmap size %dx%d", width, height)
+	}
+	offset := int(h.Size)
+	if h.Compression == biBitfields && h.Size == 40 {
+		offset += 12 // colour masks follow the header
+	}
+	var palette []color.RGBA
+	switch h.BitCount {
+	case 1, 4, 8:
+		colours := int(h.ClrUsed)
+		if colours == 0 {
+			colours = 1 << h.BitCount
+		}
+		if offset+colours*4 > len(data) {
+			return nil, errors.New("bitmap palette out of range")
+		}
+		for i := 0; i < colours; i++ {
+			p := data[offset+i*4:]
+			palette = append(palette, color.RGBA{R: p[2], G: p[1], B: p[0], A: 255})
+		}
+		offset += colours * 4
+	case 24, 32:
+	default:
+		return nil, fmt.Errorf("unsupported bit count %d", h.BitCount)
+	}
+	xorStride := (width*int(h.BitCount) + 31) / 32 * 4
+	andStride := (width + 31) / 32 * 4
+	if offset+xorStride*height > len(data) {
+		return nil, errors.New("bitmap data out of range")
+	}
+	xor := data[offset : offset+xorStride*height]
+	var and []byte
+	if end := offset + xorStride*height + andStride*height; end <= len(data) {
+		and = data[offset+xorStride*height : end]
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	var hasAlpha bool
+	for y := 0; y < height; y++ {
+		row := xor[(height-1-y)*xorStride:]
+		for x := 0; x < width; x++ {
+			var c color.RGBA
+			switch h.BitCount {
+			case 32:
+				p := row[x*4:]
+				c = color.RGBA{R: p[2], G: p[1], B: p[0], A: p[3]}
+				hasAlpha = hasAlpha || p[3] != 0
+			case 24:
+				p := row[x*3:]
+				c = color.RGBA{R: p[2], G: p[1], B: p[0], A: 255}
+			default:
+				bits := int(h.BitCount)
+				bit := x * bits
+				index := int(row[bit/8]>>(8-bits-bit%8)) & (1<<bits - 1)
+				if index < len(palette) {
+					c = palette[index]
+				}
+			}
+			img.SetNRGBA(x, y, color.NRGBA(c))
+		}
+	}
+	// 32 bit entries carry alpha, the AND mask is only used when all
+	// alpha values are zero
+	if h.BitCount == 32 && !hasAlpha && and == nil {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 255
+		}
+	}
+	if and != nil && (h.BitCount != 32 || !hasAlpha) {
+		for y := 0; y < height; y++ {
+			row := and[(height-1-y)*andStride:]
+			for x := 0; x < width; x++ {
+				c := img.NRGBAAt(x, y)
+				if row[x/8]&(0x80>>(x%8)) != 0 {
+					c.A = 0
+				} else {
+					c.A = 255
+				}
+				img.SetNRGBA(x, y, c)
+			}
+		}
+	}
+	return img, nil
+}
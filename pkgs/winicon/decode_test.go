@@ -0,0 +1,128 @@
+package winicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestDecodeValidate(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var ico bytes.Buffer
+	if err := EncodeIcon(&ico, src, []int{32, 16}); err != nil {
+		t.Fatal(err)
+	}
+	images, err := Decode(bytes.NewReader(ico.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 2 || images[0].Bounds().Dx() != 32 || images[1].Bounds().Dx() != 16 {
+		t.Fatalf("decoded %d images", len(images))
+	}
+	warnings, err := Validate(bytes.NewReader(ico.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "[48 256]") {
+		t.Errorf("warnings %q", warnings)
+	}
+	if _, err = Decode(strings.NewReader("not an icon")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// bmpIcon builds an .ico file holding a single BMP entry
+func bmpIcon(width, height, bitCount int, palette, xor, and []byte) []byte {
+	var dib bytes.Buffer
+	binary.Write(&dib, binary.LittleEndian, bitmapInfoHeader{
+		Size: 40, Width: int32(width), Height: int32(height * 2), Planes: 1, BitCount: uint16(bitCount),
+	})
+	dib.Write(palette)
+	dib.Write(xor)
+	dib.Write(and)
+	var ico bytes.Buffer
+	binary.Write(&ico, binary.LittleEndian, []uint16{0, 1, 1})
+	ico.Write([]byte{byte(width), byte(height), 0, 0})
+	binary.Write(&ico, binary.LittleEndian, []uint16{1, uint16(bitCount)})
+	binary.Write(&ico, binary.LittleEndian, []uint32{uint32(dib.Len()), 22})
+	ico.Write(dib.Bytes())
+	return ico.Bytes()
+}
+
+func TestDecodeBMP(t *testing.T) {
+	// 2x2 32 bit, bottom-up rows of BGRA
+	xor32 := []byte{
+		0, 0, 255, 255, 0, 255, 0, 128, // bottom row: red, half transparent green
+		255, 0, 0, 255, 0, 0, 0, 0, // top row: blue, transparent
+	}
+	and := make([]byte, 8)
+	images, err := Decode(bytes.NewReader(bmpIcon(2, 2, 32, nil, xor32, and)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := images[0].(*image.NRGBA)
+	for _, c := range []struct {
+		x, y int
+		want color.NRGBA
+	}{
+		{0, 0, color.NRGBA{B: 255, A: 255}},
+		{1, 0, color.NRGBA{}},
+		{0, 1, color.NRGBA{R: 255, A: 255}},
+		{1, 1, color.NRGBA{G: 255, A: 128}},
+	} {
+		if got := img.NRGBAAt(c.x, c.y); got != c.want {
+			t.Errorf("32 bit (%d,%d) = %v, want %v", c.x, c.y, got, c.want)
+		}
+	}
+
+	// 2x2 1 bit palette, the AND mask hides the second pixel of the top row
+	palette := []byte{0, 0, 0, 0, 255, 255, 255, 0}
+	xor1 := []byte{0x40, 0, 0, 0, 0x80, 0, 0, 0} // bottom row: black white, top row: white black
+	and1 := []byte{0, 0, 0, 0, 0x40, 0, 0, 0}
+	data := bmpIcon(2, 2, 1, palette, xor1, and1)
+	images, err = Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = images[0].(*image.NRGBA)
+	if got := img.NRGBAAt(0, 0); got != (color.NRGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("1 bit (0,0) = %v", got)
+	}
+	if got := img.NRGBAAt(1, 0); got.A != 0 {
+		t.Errorf("1 bit (1,0) = %v, want transparent", got)
+	}
+	if got := img.NRGBAAt(1, 1); got != (color.NRGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("1 bit (1,1) = %v", got)
+	}
+	warnings, err := Validate(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "1 bits per pixel") {
+		t.Errorf("warnings %q", warnings)
+	}
+}
+
+func TestDecodeICNS(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	var icns bytes.Buffer
+	if err := EncodeICNS(&icns, src); err != nil {
+		t.Fatal(err)
+	}
+	images, err := DecodeICNS(&icns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != len(icnsTypes) {
+		t.Fatalf("decoded %d images, want %d", len(images), len(icnsTypes))
+	}
+	for i, img := range images {
+		if img.Bounds().Dx() != icnsTypes[i].Size {
+			t.Errorf("image %d size %d, want %d", i, img.Bounds().Dx(), icnsTypes[i].Size)
+		}
+	}
+}
@@ -17,6 +17,17 @@ import (
 // a .ico file that is written to the given writer. The .ico file will include
 // a number of icons at the sizes given.
 func GenerateIcon(r io.Reader, w io.Writer, sizes []int) error {
+	// Decode to internal image
+	imagedata, _, err := image.Decode(r)
+	if err != nil {
+		return err
+	}
+	return EncodeIcon(w, imagedata, sizes)
+}
+
+// EncodeIcon writes img as a .ico file to the given writer. The .ico file
+// will include a number of icons at the sizes given.
+func EncodeIcon(w io.Writer, imagedata image.Image, sizes []int) error {
 	header := &winicon.IconFileHeader{
 		ImageType:  1,
 		ImageCount: uint16(len(sizes)),
@@ -26,12 +37,6 @@ func GenerateIcon(r io.Reader, w io.Writer, sizes []int) error {
 
 	var imageData bytes.Buffer
 
-	// Decode to internal image
-	imagedata, _, err := image.Decode(r)
-	if err != nil {
-		return err
-	}
-
 	// Loop over sizes desired
 	for index, size := range sizes {
 
@@ -40,26 +45,14 @@ func GenerateIcon(r io.Reader, w io.Writer, sizes []int) error {
 			return fmt.Errorf("a size of 0 is not valid")
 		}
 
-		// Scale image
-		rect := image.Rect(0, 0, size, size)
-		rawdata := image.NewRGBA(rect)
-		scale := draw.CatmullRom
-		scale.Scale(rawdata, rect, imagedata, imagedata.Bounds(), draw.Over, nil)
-
-		// Convert back to PNG
-		icondata := new(bytes.Buffer)
-		writer := bufio.NewWriter(icondata)
-		err = png.Encode(writer, rawdata)
-		if err != nil {
-			return err
-		}
-		err = writer.Flush()
+		// Scale image and convert back to PNG
+		icondata, err := scalePNG(imagedata, size)
 		if err != nil {
 			return err
 		}
 
 		// Save image data
-		imageData.Write(icondata.Bytes())
+		imageData.Write(icondata)
 
 		// Save header information
 		if size >= 256 {
@@ -68,7 +61,7 @@ func GenerateIcon(r io.Reader, w io.Writer, sizes []int) error {
 		iconheaders[index].Width = (uint8)(size)
 		iconheaders[index].Height = (uint8)(size)
 		iconheaders[index].BitsPerPixel = 32
-		iconheaders[index].Size = uint32(len(icondata.Bytes()))
+		iconheaders[index].Size = uint32(len(icondata))
 	}
 
 	// Update the offsets. Start by skipping header+icon headers
@@ -80,7 +73,7 @@ func GenerateIcon(r io.Reader, w io.Writer, sizes []int) error {
 	}
 
 	// Write out the header
-	err = binary.Write(w, binary.LittleEndian, header)
+	err := binary.Write(w, binary.LittleEndian, header)
 	if err != nil {
 		return err
 	}
@@ -100,3 +93,29 @@ func GenerateIcon(r io.Reader, w io.Writer, sizes []int) error {
 	}
 	return nil
 }
+
+// ScalePNG scales the image to the given square size and returns it
+// encoded as PNG.
+func ScalePNG(img image.Image, size int) ([]byte, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("a size of %d is not valid", size)
+	}
+	return scalePNG(img, size)
+}
+
+func scalePNG(img image.Image, size int) ([]byte, error) {
+	rect := image.Rect(0, 0, size, size)
+	rawdata := image.NewRGBA(rect)
+	scale := draw.CatmullRom
+	scale.Scale(rawdata, rect, img, img.Bounds(), draw.Over, nil)
+
+	var icondata bytes.Buffer
+	writer := bufio.NewWriter(&icondata)
+	if err := png.Encode(writer, rawdata); err != nil {
+		return nil, err
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+	return icondata.Bytes(), nil
+}
@@ -0,0 +1,109 @@
+package winicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// icnsTypes are the PNG based ICNS entries written by GenerateICNS,
+// matching the set produced by iconutil for a full iconset.
+var icnsTypes = []struct {
+	OSType string
+	Size   int
+}{
+	{"icp4", 16},
+	{"icp5", 32},
+	{"icp6", 64},
+	{"ic07", 128},
+	{"ic08", 256},
+	{"ic09", 512},
+	{"ic10", 1024}, // 512x512@2x
+	{"ic11", 32},   // 16x16@2x
+	{"ic12", 64},   // 32x32@2x
+	{"ic13", 256},  // 128x128@2x
+	{"ic14", 512},  // 256x256@2x
+}
+
+// GenerateICNS reads image data from the given reader and generates
+// a macOS .icns file that is written to the given writer. Every entry
+// stores PNG data, scaled from the source image.
+func GenerateICNS(r io.Reader, w io.Writer) error {
+	imagedata, _, err := image.Decode(r)
+	if err != nil {
+		return err
+	}
+	return EncodeICNS(w, imagedata)
+}
+
+// EncodeICNS writes img as a macOS .icns file to the given writer.
+func EncodeICNS(w io.Writer, img image.Image) error {
+	var (
+		body   bytes.Buffer
+		scaled = make(map[int][]byte)
+	)
+	for _, t := range icnsTypes {
+		data, ok := scaled[t.Size]
+		if !ok {
+			var err error
+			if data, err = scalePNG(img, t.Size); err != nil {
+				return err
+			}
+			scaled[t.Size] = data
+		}
+		body.WriteString(t.OSType)
+		if err := binary.Write(&body, binary.BigEndian, uint32(8+len(data))); err != nil {
+			return err
+		}
+		body.Write(data)
+	}
+
+	// Write out the header: magic and total file length
+	if _, err := io.WriteString(w, "icns"); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(8+body.Len())); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+// DecodeICNS reads a macOS .icns file and decodes every PNG encoded
+// entry. Legacy RLE and JPEG 2000 entries are skipped.
+func DecodeICNS(r io.Reader) ([]image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8 || string(data[:4]) != "icns" {
+		return nil, errors.New("not an icns file")
+	}
+	if length := binary.BigEndian.Uint32(data[4:]); int(length) > len(data) {
+		return nil, errors.New("icns file is truncated")
+	}
+	var images []image.Image
+	for offset := 8; offset+8 <= len(data); {
+		osType, length := string(data[offset:offset+4]), int(binary.BigEndian.Uint32(data[offset+4:]))
+		if length < 8 || offset+length > len(data) {
+			return nil, fmt.Errorf("icns entry %s out of range", osType)
+		}
+		entry := data[offset+8 : offset+length]
+		if bytes.HasPrefix(entry, pngSignature) {
+			img, err := png.Decode(bytes.NewReader(entry))
+			if err != nil {
+				return nil, fmt.Errorf("icns entry %s: %w", osType, err)
+			}
+			images = append(images, img)
+		}
+		offset += length
+	}
+	if len(images) == 0 {
+		return nil, errors.New("icns file contains no png images")
+	}
+	return images, nil
+}
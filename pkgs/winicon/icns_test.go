@@ -0,0 +1,43 @@
+package winicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestGenerateICNS(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for i := 0; i < 64; i++ {
+		src.Set(i, i, color.RGBA{R: 255, A: 255})
+	}
+	var srcData, icns bytes.Buffer
+	if err := png.Encode(&srcData, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateICNS(&srcData, &icns); err != nil {
+		t.Fatal(err)
+	}
+	data := icns.Bytes()
+	if string(data[:4]) != "icns" || int(binary.BigEndian.Uint32(data[4:])) != len(data) {
+		t.Fatalf("bad icns header %q %d", data[:4], binary.BigEndian.Uint32(data[4:]))
+	}
+	var count int
+	for offset := 8; offset < len(data); count++ {
+		osType, length := string(data[offset:offset+4]), int(binary.BigEndian.Uint32(data[offset+4:]))
+		img, err := png.Decode(bytes.NewReader(data[offset+8 : offset+length]))
+		if err != nil {
+			t.Fatalf("%s: %v", osType, err)
+		}
+		if want := icnsTypes[count].Size; img.Bounds().Dx() != want || osType != icnsTypes[count].OSType {
+			t.Errorf("%s: size %d, want %s %d", osType, img.Bounds().Dx(), icnsTypes[count].OSType, want)
+		}
+		offset += length
+	}
+	if count != len(icnsTypes) {
+		t.Errorf("entries %d, want %d", count, len(icnsTypes))
+	}
+}